@@ -20,6 +20,11 @@ resource "gitsync_values_yaml" "example_yaml" {
 name: bar
 replicas: 2
 EOT
+
+  wait_for_checks {
+    timeout         = "15m"
+    required_checks = ["build"]
+  }
 }
 
 resource "gitsync_values_json" "example_json" {
@@ -31,6 +36,114 @@ resource "gitsync_values_json" "example_json" {
   "replicas": 2
 }
 EOT
+
+  schema = jsonencode({
+    type     = "object"
+    required = ["name", "replicas"]
+    properties = {
+      name     = { type = "string" }
+      replicas = { type = "integer", minimum = 1 }
+    }
+  })
+}
+
+resource "gitsync_values_yaml" "example_values" {
+  branch = "main"
+  path   = "values/image.yaml"
+  values = {
+    image = {
+      repository = "ghcr.io/ip812/app"
+      tag        = "v1.0.0"
+    }
+    replicas = 2
+  }
+
+  format {
+    key_order = ["image", "repository"]
+  }
+}
+
+resource "gitsync_values_toml" "example_toml" {
+  branch  = "main"
+  path    = "config/config.toml"
+  content = <<EOT
+[server]
+name     = "bar"
+replicas = 2
+EOT
+}
+
+resource "gitsync_values_hcl" "example_tfvars" {
+  branch          = "main"
+  path            = "stacks/network/terraform.tfvars"
+  attributes_only = true
+  content         = <<EOT
+name     = "bar"
+replicas = 2
+EOT
+}
+
+resource "gitsync_values_keyvalue" "example_env" {
+  branch = "main"
+  path   = "deploy/.env"
+  keys = {
+    IMAGE_TAG = "1.2.3"
+    LOG_LEVEL = "info"
+  }
+}
+
+resource "gitsync_values_xml" "example_xml" {
+  branch   = "main"
+  path     = "maven/settings.xml"
+  xsd_path = "maven/settings-1.0.0.xsd"
+  content  = <<EOT
+<?xml version="1.0" encoding="UTF-8"?>
+<settings xmlns="http://maven.apache.org/SETTINGS/1.0.0">
+  <offline>false</offline>
+</settings>
+EOT
+}
+
+resource "gitsync_values_yaml" "example_chart_values" {
+  branch       = "main"
+  path         = "charts/app/values-prod.yaml"
+  detect_chart = true
+  values = {
+    replicas = 3
+  }
+}
+
+resource "gitsync_values_yaml" "example_manifest" {
+  branch  = "main"
+  path    = "apps/web/deployment.yaml"
+  content = <<EOT
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+EOT
+
+  kubernetes_validation {
+    version   = "1.35"
+    crd_files = ["${path.module}/crds/certificates.yaml"]
+  }
+
+  policy {
+    files = ["${path.module}/policies/images.rego"]
+  }
 }
 
 resource "gitsync_values_file" "example_file" {
@@ -42,3 +155,55 @@ resource "gitsync_values_file" "example_file" {
 Name is bar and replicas are 2
 EOT
 }
+
+resource "gitsync_values_file" "example_binary" {
+  branch         = "main"
+  path           = "assets/logo.png"
+  content_base64 = filebase64("${path.module}/logo.png")
+}
+
+resource "gitsync_branch" "example_branch" {
+  branch = "promote-values"
+  source = "main"
+}
+
+resource "gitsync_pull_request" "example_pull_request" {
+  title       = "Promote values to production"
+  head_branch = gitsync_branch.example_branch.branch
+  base_branch = "main"
+  labels      = ["automated"]
+  auto_merge  = true
+  wait_for    = "merged"
+}
+
+resource "gitsync_tag" "example_tag" {
+  tag     = "v1.0.0"
+  source  = "main"
+  message = "Release v1.0.0"
+}
+
+resource "gitsync_release" "example_release" {
+  tag   = gitsync_tag.example_tag.tag
+  name  = "v1.0.0"
+  notes = "Values for the v1.0.0 release."
+}
+
+resource "gitsync_yaml_keys" "example_yaml_keys" {
+  branch = "main"
+  path   = "values/umbrella.yaml"
+  keys = {
+    "image.tag"    = "v1.0.0"
+    "replicaCount" = 3
+  }
+  on_destroy = "restore"
+}
+
+resource "gitsync_json_patch" "example_json_patch" {
+  branch = "main"
+  path   = "config/settings.json"
+  merge_patch = jsonencode({
+    features = {
+      newCheckout = true
+    }
+  })
+}
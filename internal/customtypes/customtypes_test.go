@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package customtypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAMLSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name     string
+		prior    string
+		current  string
+		expected bool
+	}{
+		{
+			name:     "identical",
+			prior:    "a: 1\n",
+			current:  "a: 1\n",
+			expected: true,
+		},
+		{
+			name:     "reordered keys and indentation",
+			prior:    "a: 1\nb:\n  c: [1, 2]\n",
+			current:  "b:\n    c:\n      - 1\n      - 2\na: 1\n",
+			expected: true,
+		},
+		{
+			name:     "comments and quoting",
+			prior:    "# values\nname: app\n",
+			current:  "name: \"app\" # inline\n",
+			expected: true,
+		},
+		{
+			name:     "changed value",
+			prior:    "a: 1\n",
+			current:  "a: 2\n",
+			expected: false,
+		},
+		{
+			name:     "additional document",
+			prior:    "a: 1\n",
+			current:  "a: 1\n---\nb: 2\n",
+			expected: false,
+		},
+		{
+			name:     "documents reformatted one by one",
+			prior:    "---\na: 1\nb: 2\n---\nc: [1, 2]\n",
+			current:  "# first\nb: 2\na: 1\n---\n# second\nc:\n  - 1\n  - 2\n...\n",
+			expected: true,
+		},
+		{
+			name:     "documents reordered",
+			prior:    "a: 1\n---\nb: 2\n",
+			current:  "b: 2\n---\na: 1\n",
+			expected: false,
+		},
+		{
+			name:     "invalid yaml",
+			prior:    "a: 1\n",
+			current:  "a: [\n",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, diags := NewYAMLValue(tt.prior).StringSemanticEquals(context.Background(), NewYAMLValue(tt.current))
+			require.False(t, diags.HasError())
+			assert.Equal(t, tt.expected, equal)
+		})
+	}
+}
+
+func TestJSONSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name     string
+		prior    string
+		current  string
+		expected bool
+	}{
+		{
+			name:     "reordered keys and whitespace",
+			prior:    `{"a": 1, "b": [true, null]}`,
+			current:  "{\n  \"b\": [true, null],\n  \"a\": 1\n}\n",
+			expected: true,
+		},
+		{
+			name:     "changed value",
+			prior:    `{"a": 1}`,
+			current:  `{"a": "1"}`,
+			expected: false,
+		},
+		{
+			name:     "jsonc comments and trailing commas",
+			prior:    `{"a": 1}`,
+			current:  "{\n  // the value\n  \"a\": 1,\n}",
+			expected: true,
+		},
+		{
+			name:     "invalid json",
+			prior:    `{"a": 1}`,
+			current:  `{"a": 1`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, diags := NewJSONValue(tt.prior).StringSemanticEquals(context.Background(), NewJSONValue(tt.current))
+			require.False(t, diags.HasError())
+			assert.Equal(t, tt.expected, equal)
+		})
+	}
+}
+
+func TestTOMLSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name     string
+		prior    string
+		current  string
+		expected bool
+	}{
+		{
+			name:     "reordered keys and comments",
+			prior:    "name = \"app\"\nport = 80\n",
+			current:  "# service\nport = 80\nname = 'app'\n",
+			expected: true,
+		},
+		{
+			name:     "inline and standard tables",
+			prior:    "db = { url = \"x\", pool = 5 }\n",
+			current:  "[db]\nurl = \"x\"\npool = 5\n",
+			expected: true,
+		},
+		{
+			name:     "changed type",
+			prior:    "port = 80\n",
+			current:  "port = \"80\"\n",
+			expected: false,
+		},
+		{
+			name:     "invalid toml",
+			prior:    "port = 80\n",
+			current:  "port 80\n",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, diags := NewTOMLValue(tt.prior).StringSemanticEquals(context.Background(), NewTOMLValue(tt.current))
+			require.False(t, diags.HasError())
+			assert.Equal(t, tt.expected, equal)
+		})
+	}
+}
+
+func TestHCLSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name     string
+		prior    string
+		current  string
+		expected bool
+	}{
+		{
+			name:     "alignment and indentation",
+			prior:    "region = \"eu\"\ninstance_count = 3\ntags = {\nteam = \"platform\"\n}\n",
+			current:  "region         = \"eu\"\ninstance_count = 3\ntags = {\n  team = \"platform\"\n}\n",
+			expected: true,
+		},
+		{
+			name:     "changed value",
+			prior:    "region = \"eu\"\n",
+			current:  "region = \"us\"\n",
+			expected: false,
+		},
+		{
+			name:     "reordered attributes",
+			prior:    "a = 1\nb = 2\n",
+			current:  "b = 2\na = 1\n",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, diags := NewHCLValue(tt.prior).StringSemanticEquals(context.Background(), NewHCLValue(tt.current))
+			require.False(t, diags.HasError())
+			assert.Equal(t, tt.expected, equal)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package customtypes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = HCLType{}
+	_ basetypes.StringValuableWithSemanticEquals = HCL{}
+)
+
+// HCLType is a string type holding HCL content. Values that only differ in
+// the formatting fixed by `terraform fmt` are semantically equal.
+type HCLType struct {
+	basetypes.StringType
+}
+
+func (t HCLType) String() string {
+	return "customtypes.HCLType"
+}
+
+func (t HCLType) ValueType(ctx context.Context) attr.Value {
+	return HCL{}
+}
+
+func (t HCLType) Equal(o attr.Type) bool {
+	other, ok := o.(HCLType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t HCLType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return HCL{StringValue: in}, nil
+}
+
+func (t HCLType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return HCL{StringValue: stringValue}, nil
+}
+
+type HCL struct {
+	basetypes.StringValue
+}
+
+func NewHCLNull() HCL {
+	return HCL{StringValue: basetypes.NewStringNull()}
+}
+
+func NewHCLValue(value string) HCL {
+	return HCL{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v HCL) Type(ctx context.Context) attr.Type {
+	return HCLType{}
+}
+
+func (v HCL) Equal(o attr.Value) bool {
+	other, ok := o.(HCL)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals compares the canonically formatted content, so
+// alignment and indentation are not reported as changes.
+func (v HCL) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(HCL)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T", v, newValuable),
+		)
+		return false, diags
+	}
+
+	prior := hclwrite.Format([]byte(v.ValueString()))
+	current := hclwrite.Format([]byte(newValue.ValueString()))
+	return string(prior) == string(current), diags
+}
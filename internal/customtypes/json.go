@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package customtypes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"terraform-provider-gitsync/internal/jsonc"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = JSONType{}
+	_ basetypes.StringValuableWithSemanticEquals = JSON{}
+)
+
+// JSONType is a string type holding JSON content. Values that decode to the
+// same data are semantically equal.
+type JSONType struct {
+	basetypes.StringType
+}
+
+func (t JSONType) String() string {
+	return "customtypes.JSONType"
+}
+
+func (t JSONType) ValueType(ctx context.Context) attr.Value {
+	return JSON{}
+}
+
+func (t JSONType) Equal(o attr.Type) bool {
+	other, ok := o.(JSONType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t JSONType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return JSON{StringValue: in}, nil
+}
+
+func (t JSONType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return JSON{StringValue: stringValue}, nil
+}
+
+type JSON struct {
+	basetypes.StringValue
+}
+
+func NewJSONNull() JSON {
+	return JSON{StringValue: basetypes.NewStringNull()}
+}
+
+func NewJSONValue(value string) JSON {
+	return JSON{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v JSON) Type(ctx context.Context) attr.Type {
+	return JSONType{}
+}
+
+func (v JSON) Equal(o attr.Value) bool {
+	other, ok := o.(JSON)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals compares the decoded data, so key order and
+// whitespace are not reported as changes.
+func (v JSON) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(JSON)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T", v, newValuable),
+		)
+		return false, diags
+	}
+
+	// Comments and trailing commas of JSONC content are not compared.
+	var prior, current any
+	if err := unmarshalJSONC(v.ValueString(), &prior); err != nil {
+		return false, diags
+	}
+	if err := unmarshalJSONC(newValue.ValueString(), &current); err != nil {
+		return false, diags
+	}
+
+	return reflect.DeepEqual(prior, current), diags
+}
+
+func unmarshalJSONC(content string, value *any) error {
+	plain, err := jsonc.ToJSON([]byte(content))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plain, value)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package customtypes
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	_ basetypes.StringTypable                    = TOMLType{}
+	_ basetypes.StringValuableWithSemanticEquals = TOML{}
+)
+
+// TOMLType is a string type holding TOML content. Values that decode to the
+// same tables are semantically equal.
+type TOMLType struct {
+	basetypes.StringType
+}
+
+func (t TOMLType) String() string {
+	return "customtypes.TOMLType"
+}
+
+func (t TOMLType) ValueType(ctx context.Context) attr.Value {
+	return TOML{}
+}
+
+func (t TOMLType) Equal(o attr.Type) bool {
+	other, ok := o.(TOMLType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t TOMLType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return TOML{StringValue: in}, nil
+}
+
+func (t TOMLType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return TOML{StringValue: stringValue}, nil
+}
+
+type TOML struct {
+	basetypes.StringValue
+}
+
+func NewTOMLNull() TOML {
+	return TOML{StringValue: basetypes.NewStringNull()}
+}
+
+func NewTOMLValue(value string) TOML {
+	return TOML{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v TOML) Type(ctx context.Context) attr.Type {
+	return TOMLType{}
+}
+
+func (v TOML) Equal(o attr.Value) bool {
+	other, ok := o.(TOML)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals compares the decoded tables, so key order, comments,
+// whitespace and the choice between inline and standard tables are not
+// reported as changes.
+func (v TOML) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(TOML)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T", v, newValuable),
+		)
+		return false, diags
+	}
+
+	var prior, current map[string]any
+	if err := toml.Unmarshal([]byte(v.ValueString()), &prior); err != nil {
+		return false, diags
+	}
+	if err := toml.Unmarshal([]byte(newValue.ValueString()), &current); err != nil {
+		return false, diags
+	}
+
+	return reflect.DeepEqual(prior, current), diags
+}
@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package customtypes provides string types with semantic equality for
+// structured file content.
+package customtypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ basetypes.StringTypable                    = YAMLType{}
+	_ basetypes.StringValuableWithSemanticEquals = YAML{}
+)
+
+// YAMLType is a string type holding YAML content. Values that parse to the
+// same documents are semantically equal.
+type YAMLType struct {
+	basetypes.StringType
+}
+
+func (t YAMLType) String() string {
+	return "customtypes.YAMLType"
+}
+
+func (t YAMLType) ValueType(ctx context.Context) attr.Value {
+	return YAML{}
+}
+
+func (t YAMLType) Equal(o attr.Type) bool {
+	other, ok := o.(YAMLType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t YAMLType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return YAML{StringValue: in}, nil
+}
+
+func (t YAMLType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return YAML{StringValue: stringValue}, nil
+}
+
+type YAML struct {
+	basetypes.StringValue
+}
+
+func NewYAMLNull() YAML {
+	return YAML{StringValue: basetypes.NewStringNull()}
+}
+
+func NewYAMLValue(value string) YAML {
+	return YAML{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v YAML) Type(ctx context.Context) attr.Type {
+	return YAMLType{}
+}
+
+func (v YAML) Equal(o attr.Value) bool {
+	other, ok := o.(YAML)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals compares the parsed documents, so key order,
+// indentation, quoting and comments are not reported as changes.
+func (v YAML) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(YAML)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T", v, newValuable),
+		)
+		return false, diags
+	}
+
+	prior, err := parseYAML(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	current, err := parseYAML(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+
+	return reflect.DeepEqual(prior, current), diags
+}
+
+func parseYAML(content string) ([]any, error) {
+	var docs []any
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
@@ -4,20 +4,241 @@ package git
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 )
 
 type ValuesModel struct {
-	Path    string
-	Branch  string
-	Content string
+	Path   string
+	Branch string
+	// Content is written as is, so it may hold binary data.
+	Content []byte
+	// SHA is the blob SHA the file is expected to have before it is written.
+	// The current file is overwritten when it is empty.
+	SHA string
+}
+
+// NotFoundError is returned by the clients when a file does not exist on the
+// branch.
+type NotFoundError struct {
+	Path   string
+	Branch string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("file %q does not exist on branch %q", e.Path, e.Branch)
+}
+
+// IsNotFound reports whether err or any error it wraps is a NotFoundError or
+// an ObjectNotFoundError.
+func IsNotFound(err error) bool {
+	var nf *NotFoundError
+	var onf *ObjectNotFoundError
+	return errors.As(err, &nf) || errors.As(err, &onf)
+}
+
+// ObjectNotFoundError is returned by the clients when a branch, tag, release
+// or pull request does not exist.
+type ObjectNotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (e *ObjectNotFoundError) Error() string {
+	return fmt.Sprintf("%s %q does not exist", e.Kind, e.Name)
+}
+
+// FileTooLargeError is returned when a file is larger than the API of the
+// backend can read or write.
+type FileTooLargeError struct {
+	Path string
+	Size int64
+	// Size is 0 when the server did not report it and Limit is 0 when the
+	// server rejected the file without telling the largest size it accepts.
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	switch {
+	case e.Size == 0:
+		return fmt.Sprintf("file %q is larger than the limit of %s", e.Path, formatSize(e.Limit))
+	case e.Limit == 0:
+		return fmt.Sprintf("file %q has %s, more than the server accepts", e.Path, formatSize(e.Size))
+	default:
+		return fmt.Sprintf("file %q has %s, more than the limit of %s", e.Path, formatSize(e.Size), formatSize(e.Limit))
+	}
+}
+
+func formatSize(size int64) string {
+	if size < 1000*1000 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/(1000*1000))
+}
+
+// ConflictError is returned by Update and Delete when the file was changed
+// since the expected blob SHA was read.
+type ConflictError struct {
+	Path   string
+	Branch string
+	// Commit is the last commit that changed the file.
+	Commit *Commit
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"file %q on branch %q was changed by %s in commit %s",
+		e.Path,
+		e.Branch,
+		e.Commit.Author,
+		e.Commit.SHA,
+	)
+}
+
+const (
+	RefPrefixBranch = "refs/heads/"
+	RefPrefixTag    = "refs/tags/"
+)
+
+type Ref struct {
+	Name string
+	SHA  string
+}
+
+type Tag struct {
+	Name    string
+	SHA     string
+	Message string
+}
+
+// Commit is the last commit that touched a file together with the SHA of the
+// file blob it produced.
+type Commit struct {
+	SHA         string
+	BlobSHA     string
+	URL         string
+	CommittedAt time.Time
+	Author      string
+}
+
+const (
+	CheckStatePending = "pending"
+	CheckStateSuccess = "success"
+	CheckStateFailure = "failure"
+)
+
+// Check is a single CI result reported for a commit, a GitHub check run or
+// commit status, or a GitLab pipeline job.
+type Check struct {
+	Name  string
+	State string
 }
 
 type Client interface {
 	GetID(branch, path string) string
-	Create(ctx context.Context, data ValuesModel) error
+	Create(ctx context.Context, data ValuesModel) (*Commit, error)
 	GetContent(ctx context.Context, path, branch string) (string, error)
-	Update(ctx context.Context, data ValuesModel) error
-	Delete(ctx context.Context, path, branch string) error
+	// GetFile returns the content of a file as raw bytes, for binary files.
+	GetFile(ctx context.Context, path, branch string) ([]byte, error)
+	// DownloadFile streams the content of a file to w and returns the number
+	// of bytes written. It reads files too large for the regular file APIs.
+	DownloadFile(ctx context.Context, path, branch string, w io.Writer) (int64, error)
+	// Update and Delete return a ConflictError when the expected blob SHA is
+	// set and no longer matches the file.
+	Update(ctx context.Context, data ValuesModel) (*Commit, error)
+	GetCommit(ctx context.Context, path, branch string) (*Commit, error)
+	Delete(ctx context.Context, path, branch, sha string) error
+	// CreateRef creates the fully qualified ref (refs/heads/... or refs/tags/...)
+	// pointing at source, which can be a branch, a tag or a commit SHA.
+	CreateRef(ctx context.Context, name, source string) (*Ref, error)
+	GetRef(ctx context.Context, name string) (*Ref, error)
+	DeleteRef(ctx context.Context, name string) error
+	// CreateTag creates an annotated tag when message is set and a lightweight
+	// tag otherwise. Tags are removed with DeleteRef.
+	CreateTag(ctx context.Context, name, source, message string) (*Tag, error)
+	GetTag(ctx context.Context, name string) (*Tag, error)
+	GetChecks(ctx context.Context, sha string) ([]Check, error)
 	Owner() string
 	Repository() string
 }
+
+const (
+	PullRequestStateOpen   = "open"
+	PullRequestStateClosed = "closed"
+	PullRequestStateMerged = "merged"
+)
+
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+type PullRequestModel struct {
+	Title      string
+	Body       string
+	HeadBranch string
+	BaseBranch string
+	Labels     []string
+	Assignees  []string
+	Reviewers  []string
+}
+
+type PullRequest struct {
+	Number         int
+	URL            string
+	State          string
+	Title          string
+	Body           string
+	HeadBranch     string
+	BaseBranch     string
+	Labels         []string
+	Assignees      []string
+	Approved       bool
+	MergeCommitSHA string
+}
+
+// PullRequestClient manages GitHub pull requests and GitLab merge requests.
+type PullRequestClient interface {
+	// CreatePullRequest returns the pull request together with the error when
+	// it was opened, but setting its labels, assignees or reviewers failed.
+	CreatePullRequest(ctx context.Context, data PullRequestModel) (*PullRequest, error)
+	GetPullRequest(ctx context.Context, number int) (*PullRequest, error)
+	UpdatePullRequest(ctx context.Context, number int, data PullRequestModel) (*PullRequest, error)
+	ClosePullRequest(ctx context.Context, number int) error
+	EnableAutoMerge(ctx context.Context, number int, mergeMethod string) error
+	DisableAutoMerge(ctx context.Context, number int) error
+}
+
+type ReleaseAsset struct {
+	Name string
+	Path string
+	URL  string
+}
+
+type ReleaseModel struct {
+	Tag    string
+	Name   string
+	Notes  string
+	Assets []ReleaseAsset
+}
+
+type Release struct {
+	Tag    string
+	Name   string
+	Notes  string
+	URL    string
+	Assets []ReleaseAsset
+}
+
+// ReleaseClient manages GitHub and GitLab releases. Releases are identified by
+// their tag on both backends.
+type ReleaseClient interface {
+	CreateRelease(ctx context.Context, data ReleaseModel) (*Release, error)
+	GetRelease(ctx context.Context, tag string) (*Release, error)
+	// UpdateRelease replaces all uploaded assets when data.Assets is not nil.
+	UpdateRelease(ctx context.Context, data ReleaseModel) (*Release, error)
+	DeleteRelease(ctx context.Context, tag string) error
+}
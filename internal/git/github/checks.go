@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/google/go-github/v75/github"
+)
+
+// GetChecks merges the check runs (GitHub Actions and apps) with the commit
+// statuses reported by external CI systems for the given commit.
+func (c *Client) GetChecks(ctx context.Context, sha string) ([]git.Check, error) {
+	var checks []git.Check
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		runs, resp, err := c.Checks.ListCheckRunsForRef(ctx, c.owner, c.repository, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, run := range runs.CheckRuns {
+			checks = append(checks, git.Check{
+				Name:  run.GetName(),
+				State: checkRunState(run),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	statusOpts := &github.ListOptions{PerPage: 100}
+	for {
+		combined, resp, err := c.Repositories.GetCombinedStatus(ctx, c.owner, c.repository, sha, statusOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, status := range combined.Statuses {
+			checks = append(checks, git.Check{
+				Name:  status.GetContext(),
+				State: commitStatusState(status.GetState()),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		statusOpts.Page = resp.NextPage
+	}
+
+	return checks, nil
+}
+
+func checkRunState(run *github.CheckRun) string {
+	if run.GetStatus() != "completed" {
+		return git.CheckStatePending
+	}
+
+	switch run.GetConclusion() {
+	case "success", "neutral", "skipped":
+		return git.CheckStateSuccess
+	default:
+		return git.CheckStateFailure
+	}
+}
+
+func commitStatusState(state string) string {
+	switch state {
+	case "success":
+		return git.CheckStateSuccess
+	case "pending":
+		return git.CheckStatePending
+	default:
+		return git.CheckStateFailure
+	}
+}
@@ -3,8 +3,13 @@
 package github
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"terraform-provider-gitsync/internal/git"
 
@@ -17,6 +22,18 @@ var (
 	_ git.Client = (*Client)(nil)
 )
 
+const (
+	// contentsLimit is the largest file the contents API includes the content
+	// of. Larger files are read and written through the Git Data API.
+	contentsLimit = 1 << 20
+	// blobLimit is the largest blob the Git Data API accepts.
+	blobLimit = 100 << 20
+)
+
+// errBranchMoved is returned when the branch moved while a commit was created
+// through the Git Data API. It is retried like a conflict.
+var errBranchMoved = errors.New("branch moved while committing")
+
 type Client struct {
 	owner      string
 	repository string
@@ -58,6 +75,9 @@ func retryOnConflict(ctx context.Context, operation func() error) error {
 		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == 409 {
 			return struct{}{}, err
 		}
+		if errors.Is(err, errBranchMoved) {
+			return struct{}{}, err
+		}
 
 		// Not a conflict error, don't retry
 		return struct{}{}, backoff.Permanent(err)
@@ -67,25 +87,38 @@ func retryOnConflict(ctx context.Context, operation func() error) error {
 	return err
 }
 
-func (c *Client) Create(ctx context.Context, data git.ValuesModel) error {
-	return retryOnConflict(ctx, func() error {
+func (c *Client) Create(ctx context.Context, data git.ValuesModel) (*git.Commit, error) {
+	var commit *git.Commit
+	err := retryOnConflict(ctx, func() error {
+		if len(data.Content) > contentsLimit {
+			var err error
+			commit, err = c.commitBlob(ctx, data, fmt.Sprintf("terraform: Create %q at branch %q", data.Path, data.Branch), true)
+			return err
+		}
+
 		options := &github.RepositoryContentFileOptions{
 			Message: github.Ptr(
 				fmt.Sprintf("terraform: Create %q at branch %q", data.Path, data.Branch),
 			),
-			Content: []byte(data.Content),
+			Content: data.Content,
 			Branch:  github.Ptr(data.Branch),
 		}
 
-		_, _, err := c.Repositories.CreateFile(
+		resp, _, err := c.Repositories.CreateFile(
 			ctx,
 			c.owner,
 			c.repository,
 			data.Path,
 			options,
 		)
-		return err
+		if err != nil {
+			return err
+		}
+
+		commit = contentCommit(resp)
+		return nil
 	})
+	return commit, err
 }
 
 func (c *Client) get(ctx context.Context, path, branch string) (*github.RepositoryContent, error) {
@@ -99,67 +132,315 @@ func (c *Client) get(ctx context.Context, path, branch string) (*github.Reposito
 		},
 	)
 	if err != nil {
+		if ghErr, ok := err.(*github.ErrorResponse); ok {
+			if ghErr.Response.StatusCode == http.StatusNotFound {
+				return nil, &git.NotFoundError{Path: path, Branch: branch}
+			}
+			for _, e := range ghErr.Errors {
+				if e.Code == "too_large" {
+					return nil, &git.FileTooLargeError{Path: path, Limit: blobLimit}
+				}
+			}
+		}
 		return &github.RepositoryContent{}, err
 	}
 
 	return cnt, nil
 }
 
+// notFound returns an ObjectNotFoundError for the kind and name when err is a
+// 404 response, and err otherwise.
+func notFound(err error, kind, name string) error {
+	if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == http.StatusNotFound {
+		return &git.ObjectNotFoundError{Kind: kind, Name: name}
+	}
+	return err
+}
+
 func (c *Client) GetContent(ctx context.Context, path, branch string) (string, error) {
-	cnt, err := c.get(ctx, path, branch)
+	content, err := c.GetFile(ctx, path, branch)
 	if err != nil {
 		return "", err
 	}
+	return string(content), nil
+}
+
+func (c *Client) GetFile(ctx context.Context, path, branch string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := c.DownloadFile(ctx, path, branch, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadFile writes the base64 content of files up to 1 MB, which is
+// lossless for binary files. The contents API leaves out the content of larger
+// files, which are streamed from the Git Blobs API with the raw media type.
+func (c *Client) DownloadFile(ctx context.Context, path, branch string, w io.Writer) (int64, error) {
+	cnt, err := c.get(ctx, path, branch)
+	if err != nil {
+		return 0, err
+	}
 
 	if cnt == nil {
-		return "", fmt.Errorf("file %q does not exist on branch %q", path, branch)
+		return 0, &git.NotFoundError{Path: path, Branch: branch}
+	}
+
+	if cnt.GetEncoding() == "base64" {
+		decoded, err := cnt.GetContent()
+		if err != nil {
+			return 0, err
+		}
+		n, err := io.WriteString(w, decoded)
+		return int64(n), err
+	}
+
+	if size := int64(cnt.GetSize()); size > blobLimit {
+		return 0, &git.FileTooLargeError{Path: path, Size: size, Limit: blobLimit}
 	}
 
-	decoded, err := cnt.GetContent()
+	req, err := c.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("repos/%v/%v/git/blobs/%v", c.owner, c.repository, cnt.GetSHA()),
+		nil,
+	)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
 
-	return decoded, nil
+	resp, err := c.BareDo(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return io.Copy(w, resp.Body)
 }
 
-func (c *Client) Update(ctx context.Context, data git.ValuesModel) error {
-	return retryOnConflict(ctx, func() error {
+func (c *Client) Update(ctx context.Context, data git.ValuesModel) (*git.Commit, error) {
+	var commit *git.Commit
+	err := retryOnConflict(ctx, func() error {
 		cnt, err := c.get(ctx, data.Path, data.Branch)
 		if err != nil {
 			return err
 		}
 
 		if cnt == nil {
-			return fmt.Errorf("file %q does not exist on branch %q", data.Path, data.Branch)
+			return &git.NotFoundError{Path: data.Path, Branch: data.Branch}
 		}
 
 		sha := cnt.GetSHA()
 		if sha == "" {
 			return fmt.Errorf("unable to determine SHA for %q on branch %q", data.Path, data.Branch)
 		}
+		if data.SHA != "" && sha != data.SHA {
+			return c.conflict(ctx, data.Path, data.Branch)
+		}
+
+		if len(data.Content) > contentsLimit {
+			commit, err = c.commitBlob(ctx, data, fmt.Sprintf("terraform: Update %q at branch %q", data.Path, data.Branch), false)
+			return err
+		}
 
 		opts := &github.RepositoryContentFileOptions{
 			Message: github.Ptr(
 				fmt.Sprintf("terraform: Update %q at branch %q", data.Path, data.Branch),
 			),
-			Content: []byte(data.Content),
+			Content: data.Content,
 			Branch:  github.Ptr(data.Branch),
 			SHA:     github.Ptr(sha),
 		}
 
-		_, _, err = c.Repositories.UpdateFile(
+		resp, _, err := c.Repositories.UpdateFile(
 			ctx,
 			c.owner,
 			c.repository,
 			data.Path,
 			opts,
 		)
+		if err != nil {
+			return err
+		}
+
+		commit = contentCommit(resp)
+		return nil
+	})
+	return commit, err
+}
+
+func (c *Client) GetCommit(ctx context.Context, path, branch string) (*git.Commit, error) {
+	cnt, err := c.get(ctx, path, branch)
+	if err != nil {
+		return nil, err
+	}
+	if cnt == nil {
+		return nil, &git.NotFoundError{Path: path, Branch: branch}
+	}
+
+	commits, _, err := c.Repositories.ListCommits(
+		ctx,
+		c.owner,
+		c.repository,
+		&github.CommitsListOptions{
+			SHA:         branch,
+			Path:        path,
+			ListOptions: github.ListOptions{PerPage: 1},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commit found for %q on branch %q", path, branch)
+	}
+
+	last := commits[0]
+	commit := &git.Commit{
+		SHA:     last.GetSHA(),
+		BlobSHA: cnt.GetSHA(),
+		URL:     last.GetHTMLURL(),
+	}
+	if details := last.GetCommit(); details != nil {
+		commit.Author = details.GetAuthor().GetName()
+		commit.CommittedAt = details.GetCommitter().GetDate().Time
+	}
+	return commit, nil
+}
+
+func (c *Client) conflict(ctx context.Context, path, branch string) error {
+	commit, err := c.GetCommit(ctx, path, branch)
+	if err != nil {
 		return err
+	}
+	return &git.ConflictError{Path: path, Branch: branch, Commit: commit}
+}
+
+// commitBlob commits the content of data through the Git Data API, which
+// accepts files larger than the contents API. Unlike the contents API, it
+// replaces the file whatever its SHA, so the file is checked in the tree of the
+// commit it builds on: it must not exist when create is set, and must have the
+// expected SHA otherwise.
+func (c *Client) commitBlob(ctx context.Context, data git.ValuesModel, message string, create bool) (*git.Commit, error) {
+	if size := int64(len(data.Content)); size > blobLimit {
+		return nil, &git.FileTooLargeError{Path: data.Path, Size: size, Limit: blobLimit}
+	}
+
+	ref, _, err := c.Git.GetRef(ctx, c.owner, c.repository, "heads/"+data.Branch)
+	if err != nil {
+		return nil, err
+	}
+	parent, _, err := c.Git.GetCommit(ctx, c.owner, c.repository, ref.GetObject().GetSHA())
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := c.treeBlobSHA(ctx, parent.GetTree().GetSHA(), data.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case create && current != "":
+		return nil, fmt.Errorf("file %q already exists on branch %q", data.Path, data.Branch)
+	case !create && current == "":
+		return nil, &git.NotFoundError{Path: data.Path, Branch: data.Branch}
+	case !create && data.SHA != "" && current != data.SHA:
+		return nil, c.conflict(ctx, data.Path, data.Branch)
+	}
+
+	blob, _, err := c.Git.CreateBlob(ctx, c.owner, c.repository, github.Blob{
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString(data.Content)),
+		Encoding: github.Ptr("base64"),
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree, _, err := c.Git.CreateTree(ctx, c.owner, c.repository, parent.GetTree().GetSHA(), []*github.TreeEntry{{
+		Path: github.Ptr(data.Path),
+		Mode: github.Ptr("100644"),
+		Type: github.Ptr("blob"),
+		SHA:  blob.SHA,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	created, _, err := c.Git.CreateCommit(ctx, c.owner, c.repository, github.Commit{
+		Message: github.Ptr(message),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: parent.SHA}},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = c.Git.UpdateRef(ctx, c.owner, c.repository, "heads/"+data.Branch, github.UpdateRef{
+		SHA: created.GetSHA(),
+	})
+	if err != nil {
+		// The update is rejected when it is not a fast-forward.
+		if ghErr, ok := err.(*github.ErrorResponse); ok && ghErr.Response.StatusCode == http.StatusUnprocessableEntity {
+			return nil, errBranchMoved
+		}
+		return nil, err
+	}
+
+	return &git.Commit{
+		SHA:         created.GetSHA(),
+		BlobSHA:     blob.GetSHA(),
+		URL:         created.GetHTMLURL(),
+		CommittedAt: created.GetCommitter().GetDate().Time,
+		Author:      created.GetAuthor().GetName(),
+	}, nil
+}
+
+// treeBlobSHA returns the SHA of the blob at path in the tree, or an empty
+// string when there is none. Trees are read one directory at a time, as
+// recursive trees are truncated in large repositories.
+func (c *Client) treeBlobSHA(ctx context.Context, treeSHA, path string) (string, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		tree, _, err := c.Git.GetTree(ctx, c.owner, c.repository, treeSHA, false)
+		if err != nil {
+			return "", err
+		}
+
+		var entry *github.TreeEntry
+		for _, e := range tree.Entries {
+			if e.GetPath() == segment {
+				entry = e
+				break
+			}
+		}
+
+		switch {
+		case entry == nil:
+			return "", nil
+		case i == len(segments)-1:
+			if entry.GetType() != "blob" {
+				return "", nil
+			}
+			return entry.GetSHA(), nil
+		case entry.GetType() != "tree":
+			return "", nil
+		}
+		treeSHA = entry.GetSHA()
+	}
+	return "", nil
+}
+
+func contentCommit(resp *github.RepositoryContentResponse) *git.Commit {
+	return &git.Commit{
+		SHA:         resp.GetSHA(),
+		BlobSHA:     resp.GetContent().GetSHA(),
+		URL:         resp.GetHTMLURL(),
+		CommittedAt: resp.GetCommitter().GetDate().Time,
+		Author:      resp.GetAuthor().GetName(),
+	}
 }
 
-func (c *Client) Delete(ctx context.Context, path, branch string) error {
+func (c *Client) Delete(ctx context.Context, path, branch, sha string) error {
 	return retryOnConflict(ctx, func() error {
 		cnt, err := c.get(ctx, path, branch)
 		if err != nil {
@@ -167,19 +448,22 @@ func (c *Client) Delete(ctx context.Context, path, branch string) error {
 		}
 
 		if cnt == nil {
-			return fmt.Errorf("file %q does not exist on branch %q", path, branch)
+			return &git.NotFoundError{Path: path, Branch: branch}
 		}
 
-		sha := cnt.GetSHA()
-		if sha == "" {
+		current := cnt.GetSHA()
+		if current == "" {
 			return fmt.Errorf("unable to determine SHA for %q on branch %q", path, branch)
 		}
+		if sha != "" && current != sha {
+			return c.conflict(ctx, path, branch)
+		}
 
 		opts := &github.RepositoryContentFileOptions{
 			Message: github.Ptr(
 				fmt.Sprintf("terraform: Delete %q from branch %q", path, branch),
 			),
-			SHA:    github.Ptr(sha),
+			SHA:    github.Ptr(current),
 			Branch: github.Ptr(branch),
 		}
 
@@ -0,0 +1,317 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v75/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	return &Client{owner: "foo", repository: "bar", Client: client}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+// gitDataRepo serves a branch with charts/big.bin through the contents API and
+// the Git Data API. blobSHA is the SHA of the file in the tree of the head
+// commit, which the contents API reports as contentsSHA.
+type gitDataRepo struct {
+	contentsSHA string
+	blobSHA     atomic.Value
+	// moveBranch rejects the first ref update as not a fast-forward.
+	moveBranch bool
+	rejected   atomic.Bool
+
+	blobs      atomic.Int32
+	refUpdates atomic.Int32
+}
+
+func (r *gitDataRepo) mux(t *testing.T) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/contents/", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"type":     "file",
+			"path":     "charts/big.bin",
+			"sha":      r.contentsSHA,
+			"size":     2 << 20,
+			"encoding": "none",
+		})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/commits", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, []map[string]any{{
+			"sha":    "teammate-commit",
+			"commit": map[string]any{"author": map[string]any{"name": "teammate"}},
+		}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/heads/main", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"ref": "refs/heads/main", "object": map[string]any{"sha": "head"}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/commits/{sha}", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": req.PathValue("sha"), "tree": map[string]any{"sha": "root"}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/trees/root", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "root", "tree": []map[string]any{
+			{"path": "README.md", "type": "blob", "sha": "readme"},
+			{"path": "charts", "type": "tree", "sha": "charts"},
+		}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/trees/charts", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "charts", "tree": []map[string]any{
+			{"path": "big.bin", "type": "blob", "sha": r.blobSHA.Load()},
+		}})
+	})
+	mux.HandleFunc("POST /repos/foo/bar/git/blobs", func(w http.ResponseWriter, req *http.Request) {
+		r.blobs.Add(1)
+		writeJSON(t, w, map[string]any{"sha": "new-blob"})
+	})
+	mux.HandleFunc("POST /repos/foo/bar/git/trees", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "new-tree"})
+	})
+	mux.HandleFunc("POST /repos/foo/bar/git/commits", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "new-commit", "html_url": "https://github.com/foo/bar/commit/new-commit"})
+	})
+	mux.HandleFunc("PATCH /repos/foo/bar/git/refs/heads/main", func(w http.ResponseWriter, req *http.Request) {
+		r.refUpdates.Add(1)
+		if r.moveBranch && !r.rejected.Swap(true) {
+			// The branch moved and a teammate changed the file meanwhile.
+			r.blobSHA.Store("teammate-blob")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			writeJSON(t, w, map[string]any{"message": "Update is not a fast forward"})
+			return
+		}
+		writeJSON(t, w, map[string]any{"ref": "refs/heads/main", "object": map[string]any{"sha": "new-commit"}})
+	})
+	return mux
+}
+
+func largeContent() []byte {
+	return bytes.Repeat([]byte("x"), contentsLimit+1)
+}
+
+func TestUpdateLargeFile(t *testing.T) {
+	repo := &gitDataRepo{contentsSHA: "old-blob"}
+	repo.blobSHA.Store("old-blob")
+	client := newTestClient(t, repo.mux(t))
+
+	commit, err := client.Update(context.Background(), git.ValuesModel{
+		Path:    "charts/big.bin",
+		Branch:  "main",
+		Content: largeContent(),
+		SHA:     "old-blob",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "new-commit", commit.SHA)
+	assert.Equal(t, "new-blob", commit.BlobSHA)
+	assert.Equal(t, int32(1), repo.refUpdates.Load())
+}
+
+func TestUpdateLargeFileChangedInParentTree(t *testing.T) {
+	// The contents API still reports the expected SHA, but the head commit
+	// the new tree is built on already has another version of the file.
+	repo := &gitDataRepo{contentsSHA: "old-blob"}
+	repo.blobSHA.Store("teammate-blob")
+	client := newTestClient(t, repo.mux(t))
+
+	_, err := client.Update(context.Background(), git.ValuesModel{
+		Path:    "charts/big.bin",
+		Branch:  "main",
+		Content: largeContent(),
+		SHA:     "old-blob",
+	})
+	var conflict *git.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, "teammate", conflict.Commit.Author)
+	assert.Zero(t, repo.blobs.Load())
+}
+
+func TestUpdateLargeFileBranchMoved(t *testing.T) {
+	repo := &gitDataRepo{contentsSHA: "old-blob", moveBranch: true}
+	repo.blobSHA.Store("old-blob")
+	client := newTestClient(t, repo.mux(t))
+
+	// The retry after the rejected ref update must check the file again
+	// instead of committing on top of the change of the teammate.
+	_, err := client.Update(context.Background(), git.ValuesModel{
+		Path:    "charts/big.bin",
+		Branch:  "main",
+		Content: largeContent(),
+		SHA:     "old-blob",
+	})
+	require.ErrorAs(t, err, new(*git.ConflictError))
+	assert.Equal(t, int32(1), repo.refUpdates.Load())
+}
+
+func TestCreateLargeFileExists(t *testing.T) {
+	repo := &gitDataRepo{}
+	repo.blobSHA.Store("old-blob")
+	client := newTestClient(t, repo.mux(t))
+
+	_, err := client.Create(context.Background(), git.ValuesModel{
+		Path:    "charts/big.bin",
+		Branch:  "main",
+		Content: largeContent(),
+	})
+	require.ErrorContains(t, err, "already exists")
+	assert.Zero(t, repo.blobs.Load())
+}
+
+func TestCommitBlobTooLarge(t *testing.T) {
+	client := newTestClient(t, http.NewServeMux())
+
+	_, err := client.commitBlob(context.Background(), git.ValuesModel{
+		Path:    "big.bin",
+		Branch:  "main",
+		Content: make([]byte, blobLimit+1),
+	}, "message", true)
+	var tooLarge *git.FileTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(blobLimit+1), tooLarge.Size)
+}
+
+func TestDownloadFile(t *testing.T) {
+	content := strings.Repeat("large file\n", contentsLimit/10)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/contents/big.txt", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"type":     "file",
+			"path":     "big.txt",
+			"sha":      "big-blob",
+			"size":     len(content),
+			"encoding": "none",
+		})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/contents/small.txt", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"type":     "file",
+			"path":     "small.txt",
+			"sha":      "small-blob",
+			"encoding": "base64",
+			"content":  "c21hbGwK",
+		})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/contents/huge.bin", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"type":     "file",
+			"path":     "huge.bin",
+			"sha":      "huge-blob",
+			"size":     blobLimit + 1,
+			"encoding": "none",
+		})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/blobs/big-blob", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/vnd.github.raw+json", req.Header.Get("Accept"))
+		_, _ = w.Write([]byte(content))
+	})
+	client := newTestClient(t, mux)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	n, err := client.DownloadFile(ctx, "big.txt", "main", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+
+	small, err := client.GetContent(ctx, "small.txt", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "small\n", small)
+
+	_, err = client.DownloadFile(ctx, "huge.bin", "main", &buf)
+	var tooLarge *git.FileTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(blobLimit+1), tooLarge.Size)
+}
+
+func TestGetCommit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/contents/values.yaml", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "heads/main", req.URL.Query().Get("ref"))
+		writeJSON(t, w, map[string]any{"type": "file", "path": "values.yaml", "sha": "blob"})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/commits", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "main", req.URL.Query().Get("sha"))
+		assert.Equal(t, "values.yaml", req.URL.Query().Get("path"))
+		writeJSON(t, w, []map[string]any{{
+			"sha":      "abc",
+			"html_url": "https://github.com/foo/bar/commit/abc",
+			"commit": map[string]any{
+				"author":    map[string]any{"name": "Jane Doe", "date": "2024-01-01T10:00:00Z"},
+				"committer": map[string]any{"name": "GitHub", "date": "2024-01-02T10:00:00Z"},
+			},
+		}})
+	})
+	client := newTestClient(t, mux)
+
+	commit, err := client.GetCommit(context.Background(), "values.yaml", "main")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Commit{
+		SHA:         "abc",
+		BlobSHA:     "blob",
+		URL:         "https://github.com/foo/bar/commit/abc",
+		CommittedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Author:      "Jane Doe",
+	}, commit)
+}
+
+func TestContentCommit(t *testing.T) {
+	resp := &github.RepositoryContentResponse{
+		Content: &github.RepositoryContent{SHA: github.Ptr("blob")},
+		Commit: github.Commit{
+			SHA:       github.Ptr("abc"),
+			HTMLURL:   github.Ptr("https://github.com/foo/bar/commit/abc"),
+			Author:    &github.CommitAuthor{Name: github.Ptr("Jane Doe")},
+			Committer: &github.CommitAuthor{Date: &github.Timestamp{Time: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}},
+		},
+	}
+
+	assert.Equal(t, &git.Commit{
+		SHA:         "abc",
+		BlobSHA:     "blob",
+		URL:         "https://github.com/foo/bar/commit/abc",
+		CommittedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Author:      "Jane Doe",
+	}, contentCommit(resp))
+}
+
+func TestGetContentNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/contents/values.yaml", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]any{"message": "Not Found"})
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.GetContent(context.Background(), "values.yaml", "main")
+	assert.True(t, git.IsNotFound(err), err)
+	assert.EqualError(t, err, `file "values.yaml" does not exist on branch "main"`)
+
+	err = client.Delete(context.Background(), "values.yaml", "main", "")
+	assert.True(t, git.IsNotFound(err), err)
+}
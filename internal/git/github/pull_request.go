@@ -0,0 +1,264 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/google/go-github/v75/github"
+)
+
+var (
+	_ git.PullRequestClient = (*Client)(nil)
+)
+
+const (
+	reviewStateApproved         = "APPROVED"
+	reviewStateChangesRequested = "CHANGES_REQUESTED"
+	reviewStateDismissed        = "DISMISSED"
+)
+
+func (c *Client) CreatePullRequest(ctx context.Context, data git.PullRequestModel) (*git.PullRequest, error) {
+	pr, _, err := c.PullRequests.Create(ctx, c.owner, c.repository, &github.NewPullRequest{
+		Title: github.Ptr(data.Title),
+		Body:  github.Ptr(data.Body),
+		Head:  github.Ptr(data.HeadBranch),
+		Base:  github.Ptr(data.BaseBranch),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The metadata can only be set once the pull request exists. It is
+	// returned on failure, so the next apply does not open it again.
+	if err := c.setPullRequestMetadata(ctx, pr.GetNumber(), data); err != nil {
+		created, getErr := c.GetPullRequest(ctx, pr.GetNumber())
+		if getErr != nil {
+			created = &git.PullRequest{
+				Number:     pr.GetNumber(),
+				URL:        pr.GetHTMLURL(),
+				State:      pr.GetState(),
+				Title:      pr.GetTitle(),
+				Body:       pr.GetBody(),
+				HeadBranch: pr.GetHead().GetRef(),
+				BaseBranch: pr.GetBase().GetRef(),
+			}
+		}
+		return created, err
+	}
+
+	return c.GetPullRequest(ctx, pr.GetNumber())
+}
+
+func (c *Client) GetPullRequest(ctx context.Context, number int) (*git.PullRequest, error) {
+	pr, _, err := c.PullRequests.Get(ctx, c.owner, c.repository, number)
+	if err != nil {
+		return nil, notFound(err, "pull request", strconv.Itoa(number))
+	}
+
+	approved, err := c.isApproved(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	state := pr.GetState()
+	if pr.GetMerged() {
+		state = git.PullRequestStateMerged
+	}
+
+	labels := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		labels = append(labels, l.GetName())
+	}
+
+	assignees := make([]string, 0, len(pr.Assignees))
+	for _, a := range pr.Assignees {
+		assignees = append(assignees, a.GetLogin())
+	}
+
+	return &git.PullRequest{
+		Number:         pr.GetNumber(),
+		URL:            pr.GetHTMLURL(),
+		State:          state,
+		Title:          pr.GetTitle(),
+		Body:           pr.GetBody(),
+		HeadBranch:     pr.GetHead().GetRef(),
+		BaseBranch:     pr.GetBase().GetRef(),
+		Labels:         labels,
+		Assignees:      assignees,
+		Approved:       approved,
+		MergeCommitSHA: pr.GetMergeCommitSHA(),
+	}, nil
+}
+
+func (c *Client) UpdatePullRequest(ctx context.Context, number int, data git.PullRequestModel) (*git.PullRequest, error) {
+	_, _, err := c.PullRequests.Edit(ctx, c.owner, c.repository, number, &github.PullRequest{
+		Title: github.Ptr(data.Title),
+		Body:  github.Ptr(data.Body),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.setPullRequestMetadata(ctx, number, data); err != nil {
+		return nil, err
+	}
+
+	return c.GetPullRequest(ctx, number)
+}
+
+func (c *Client) ClosePullRequest(ctx context.Context, number int) error {
+	_, _, err := c.PullRequests.Edit(ctx, c.owner, c.repository, number, &github.PullRequest{
+		State: github.Ptr(git.PullRequestStateClosed),
+	})
+	return err
+}
+
+// The REST API has no endpoint for auto-merge, so it is toggled through the
+// GraphQL API using the pull request node ID.
+func (c *Client) EnableAutoMerge(ctx context.Context, number int, mergeMethod string) error {
+	pr, _, err := c.PullRequests.Get(ctx, c.owner, c.repository, number)
+	if err != nil {
+		return err
+	}
+
+	if mergeMethod == "" {
+		mergeMethod = git.MergeMethodMerge
+	}
+
+	return c.graphql(ctx, `
+mutation($id: ID!, $method: PullRequestMergeMethod) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) {
+    clientMutationId
+  }
+}`, map[string]any{
+		"id":     pr.GetNodeID(),
+		"method": strings.ToUpper(mergeMethod),
+	})
+}
+
+func (c *Client) DisableAutoMerge(ctx context.Context, number int) error {
+	pr, _, err := c.PullRequests.Get(ctx, c.owner, c.repository, number)
+	if err != nil {
+		return err
+	}
+
+	return c.graphql(ctx, `
+mutation($id: ID!) {
+  disablePullRequestAutoMerge(input: {pullRequestId: $id}) {
+    clientMutationId
+  }
+}`, map[string]any{
+		"id": pr.GetNodeID(),
+	})
+}
+
+// Labels and assignees are managed through the issues API, which replaces the
+// full list. Reviewers can only be requested, GitHub drops them from the pull
+// request once they have submitted a review.
+func (c *Client) setPullRequestMetadata(ctx context.Context, number int, data git.PullRequestModel) error {
+	labels := data.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	assignees := data.Assignees
+	if assignees == nil {
+		assignees = []string{}
+	}
+
+	_, _, err := c.Issues.Edit(ctx, c.owner, c.repository, number, &github.IssueRequest{
+		Labels:    &labels,
+		Assignees: &assignees,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(data.Reviewers) == 0 {
+		return nil
+	}
+
+	_, _, err = c.PullRequests.RequestReviewers(ctx, c.owner, c.repository, number, github.ReviewersRequest{
+		Reviewers: data.Reviewers,
+	})
+	return err
+}
+
+// A pull request counts as approved when at least one reviewer approved it and
+// nobody has outstanding requested changes. Only the latest review per user is
+// taken into account.
+func (c *Client) isApproved(ctx context.Context, number int) (bool, error) {
+	latest := map[string]string{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := c.PullRequests.ListReviews(ctx, c.owner, c.repository, number, opts)
+		if err != nil {
+			return false, err
+		}
+
+		for _, r := range reviews {
+			switch r.GetState() {
+			case reviewStateApproved, reviewStateChangesRequested, reviewStateDismissed:
+				latest[r.GetUser().GetLogin()] = r.GetState()
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	approved := false
+	for _, state := range latest {
+		switch state {
+		case reviewStateChangesRequested:
+			return false, nil
+		case reviewStateApproved:
+			approved = true
+		}
+	}
+
+	return approved, nil
+}
+
+type graphqlRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphqlResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (c *Client) graphql(ctx context.Context, query string, variables map[string]any) error {
+	req, err := c.NewRequest(http.MethodPost, "graphql", &graphqlRequest{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var out graphqlResponse
+	if _, err := c.Do(ctx, req, &out); err != nil {
+		return err
+	}
+
+	if len(out.Errors) > 0 {
+		msgs := make([]string, 0, len(out.Errors))
+		for _, e := range out.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+
+	return nil
+}
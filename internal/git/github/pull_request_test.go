@@ -0,0 +1,64 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPullRequestNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/pulls/42", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]any{"message": "Not Found"})
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.GetPullRequest(context.Background(), 42)
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestCreatePullRequestMetadataFails(t *testing.T) {
+	pr := map[string]any{
+		"number":   7,
+		"html_url": "https://github.com/foo/bar/pull/7",
+		"state":    "open",
+		"title":    "Bump chart",
+		"head":     map[string]any{"ref": "bump"},
+		"base":     map[string]any{"ref": "main"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/foo/bar/pulls", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, pr)
+	})
+	mux.HandleFunc("PATCH /repos/foo/bar/issues/7", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		writeJSON(t, w, map[string]any{"message": "Validation Failed"})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/pulls/7", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, pr)
+	})
+	mux.HandleFunc("GET /repos/foo/bar/pulls/7/reviews", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, []any{})
+	})
+	client := newTestClient(t, mux)
+
+	created, err := client.CreatePullRequest(context.Background(), git.PullRequestModel{
+		Title:      "Bump chart",
+		HeadBranch: "bump",
+		BaseBranch: "main",
+		Assignees:  []string{"nobody"},
+	})
+	require.Error(t, err)
+	require.NotNil(t, created)
+	assert.Equal(t, 7, created.Number)
+	assert.Equal(t, "https://github.com/foo/bar/pull/7", created.URL)
+}
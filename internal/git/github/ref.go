@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/google/go-github/v75/github"
+)
+
+func (c *Client) CreateRef(ctx context.Context, name, source string) (*git.Ref, error) {
+	sha, _, err := c.Repositories.GetCommitSHA1(ctx, c.owner, c.repository, source, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ref, _, err := c.Git.CreateRef(ctx, c.owner, c.repository, github.CreateRef{
+		Ref: name,
+		SHA: sha,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &git.Ref{
+		Name: ref.GetRef(),
+		SHA:  ref.GetObject().GetSHA(),
+	}, nil
+}
+
+// Annotated tags point at a tag object instead of a commit, so GetRef peels
+// them to return the SHA of the tagged commit.
+func (c *Client) GetRef(ctx context.Context, name string) (*git.Ref, error) {
+	ref, _, err := c.Git.GetRef(ctx, c.owner, c.repository, strings.TrimPrefix(name, "refs/"))
+	if err != nil {
+		return nil, notFound(err, "ref", name)
+	}
+
+	sha := ref.GetObject().GetSHA()
+	if ref.GetObject().GetType() == "tag" {
+		tag, _, err := c.Git.GetTag(ctx, c.owner, c.repository, sha)
+		if err != nil {
+			return nil, err
+		}
+		sha = tag.GetObject().GetSHA()
+	}
+
+	return &git.Ref{
+		Name: ref.GetRef(),
+		SHA:  sha,
+	}, nil
+}
+
+func (c *Client) DeleteRef(ctx context.Context, name string) error {
+	_, err := c.Git.DeleteRef(ctx, c.owner, c.repository, strings.TrimPrefix(name, "refs/"))
+	// GitHub answers the deletion of a missing ref with 422 instead of 404.
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response.StatusCode == http.StatusUnprocessableEntity &&
+		strings.Contains(ghErr.Message, "Reference does not exist") {
+		return &git.ObjectNotFoundError{Kind: "ref", Name: name}
+	}
+	return notFound(err, "ref", name)
+}
+
+func (c *Client) CreateTag(ctx context.Context, name, source, message string) (*git.Tag, error) {
+	sha, _, err := c.Repositories.GetCommitSHA1(ctx, c.owner, c.repository, source, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// A lightweight tag is just a ref, an annotated tag needs a tag object the
+	// ref points at.
+	target := sha
+	if message != "" {
+		tag, _, err := c.Git.CreateTag(ctx, c.owner, c.repository, github.CreateTag{
+			Tag:     name,
+			Message: message,
+			Object:  sha,
+			Type:    "commit",
+		})
+		if err != nil {
+			return nil, err
+		}
+		target = tag.GetSHA()
+	}
+
+	_, _, err = c.Git.CreateRef(ctx, c.owner, c.repository, github.CreateRef{
+		Ref: git.RefPrefixTag + name,
+		SHA: target,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &git.Tag{
+		Name:    name,
+		SHA:     sha,
+		Message: message,
+	}, nil
+}
+
+func (c *Client) GetTag(ctx context.Context, name string) (*git.Tag, error) {
+	ref, _, err := c.Git.GetRef(ctx, c.owner, c.repository, "tags/"+name)
+	if err != nil {
+		return nil, notFound(err, "tag", name)
+	}
+
+	if ref.GetObject().GetType() != "tag" {
+		return &git.Tag{
+			Name: name,
+			SHA:  ref.GetObject().GetSHA(),
+		}, nil
+	}
+
+	tag, _, err := c.Git.GetTag(ctx, c.owner, c.repository, ref.GetObject().GetSHA())
+	if err != nil {
+		return nil, err
+	}
+
+	return &git.Tag{
+		Name:    name,
+		SHA:     tag.GetObject().GetSHA(),
+		Message: tag.GetMessage(),
+	}, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRef(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/commits/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("abc"))
+	})
+	mux.HandleFunc("POST /repos/foo/bar/git/refs", func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"ref": "refs/heads/feature", "sha": "abc"}, body)
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(t, w, map[string]any{"ref": "refs/heads/feature", "object": map[string]any{"type": "commit", "sha": "abc"}})
+	})
+	client := newTestClient(t, mux)
+
+	ref, err := client.CreateRef(context.Background(), git.RefPrefixBranch+"feature", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Ref{Name: "refs/heads/feature", SHA: "abc"}, ref)
+}
+
+func TestGetRefPeelsAnnotatedTag(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"ref": "refs/tags/v1.0.0", "object": map[string]any{"type": "tag", "sha": "tag-object"}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/tags/tag-object", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "tag-object", "object": map[string]any{"type": "commit", "sha": "abc"}})
+	})
+	client := newTestClient(t, mux)
+
+	ref, err := client.GetRef(context.Background(), git.RefPrefixTag+"v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Ref{Name: "refs/tags/v1.0.0", SHA: "abc"}, ref)
+}
+
+func TestDeleteRef(t *testing.T) {
+	var deleted atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /repos/foo/bar/git/refs/heads/feature", func(w http.ResponseWriter, req *http.Request) {
+		deleted.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	client := newTestClient(t, mux)
+
+	require.NoError(t, client.DeleteRef(context.Background(), git.RefPrefixBranch+"feature"))
+	assert.True(t, deleted.Load())
+}
+
+func TestGetRefNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/heads/feature", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]any{"message": "Not Found"})
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.GetRef(context.Background(), git.RefPrefixBranch+"feature")
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestDeleteRefMissing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /repos/foo/bar/git/refs/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		writeJSON(t, w, map[string]any{"message": "Reference does not exist"})
+	})
+	client := newTestClient(t, mux)
+
+	err := client.DeleteRef(context.Background(), git.RefPrefixTag+"v1.0.0")
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestGetTagKeepsMessage(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"ref": "refs/tags/v1.0.0", "object": map[string]any{"type": "tag", "sha": "tag-object"}})
+	})
+	mux.HandleFunc("GET /repos/foo/bar/git/tags/tag-object", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"sha": "tag-object", "message": "Release 1.0.0\n", "object": map[string]any{"sha": "abc"}})
+	})
+	client := newTestClient(t, mux)
+
+	tag, err := client.GetTag(context.Background(), "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "Release 1.0.0\n", tag.Message)
+	assert.Equal(t, "abc", tag.SHA)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/google/go-github/v75/github"
+)
+
+var (
+	_ git.ReleaseClient = (*Client)(nil)
+)
+
+func (c *Client) CreateRelease(ctx context.Context, data git.ReleaseModel) (*git.Release, error) {
+	// GitHub would create a missing tag from the default branch instead.
+	if _, err := c.GetTag(ctx, data.Tag); err != nil {
+		return nil, err
+	}
+
+	release, _, err := c.Repositories.CreateRelease(ctx, c.owner, c.repository, &github.RepositoryRelease{
+		TagName: github.Ptr(data.Tag),
+		Name:    github.Ptr(data.Name),
+		Body:    github.Ptr(data.Notes),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The release is removed again, so that a failed upload can be retried
+	// by the next apply.
+	if err := c.uploadReleaseAssets(ctx, release.GetID(), data.Assets); err != nil {
+		if _, deleteErr := c.Repositories.DeleteRelease(ctx, c.owner, c.repository, release.GetID()); deleteErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to delete the release again: %w", deleteErr))
+		}
+		return nil, err
+	}
+
+	return c.GetRelease(ctx, data.Tag)
+}
+
+func (c *Client) GetRelease(ctx context.Context, tag string) (*git.Release, error) {
+	release, _, err := c.Repositories.GetReleaseByTag(ctx, c.owner, c.repository, tag)
+	if err != nil {
+		return nil, notFound(err, "release", tag)
+	}
+
+	assets := make([]git.ReleaseAsset, 0, len(release.Assets))
+	for _, a := range release.Assets {
+		assets = append(assets, git.ReleaseAsset{
+			Name: a.GetName(),
+			URL:  a.GetBrowserDownloadURL(),
+		})
+	}
+
+	return &git.Release{
+		Tag:    release.GetTagName(),
+		Name:   release.GetName(),
+		Notes:  release.GetBody(),
+		URL:    release.GetHTMLURL(),
+		Assets: assets,
+	}, nil
+}
+
+func (c *Client) UpdateRelease(ctx context.Context, data git.ReleaseModel) (*git.Release, error) {
+	release, _, err := c.Repositories.GetReleaseByTag(ctx, c.owner, c.repository, data.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = c.Repositories.EditRelease(ctx, c.owner, c.repository, release.GetID(), &github.RepositoryRelease{
+		Name: github.Ptr(data.Name),
+		Body: github.Ptr(data.Notes),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Assets != nil {
+		for _, a := range release.Assets {
+			if _, err := c.Repositories.DeleteReleaseAsset(ctx, c.owner, c.repository, a.GetID()); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.uploadReleaseAssets(ctx, release.GetID(), data.Assets); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.GetRelease(ctx, data.Tag)
+}
+
+func (c *Client) DeleteRelease(ctx context.Context, tag string) error {
+	release, _, err := c.Repositories.GetReleaseByTag(ctx, c.owner, c.repository, tag)
+	if err != nil {
+		return notFound(err, "release", tag)
+	}
+
+	_, err = c.Repositories.DeleteRelease(ctx, c.owner, c.repository, release.GetID())
+	return err
+}
+
+func (c *Client) uploadReleaseAssets(ctx context.Context, id int64, assets []git.ReleaseAsset) error {
+	for _, a := range assets {
+		if err := c.uploadReleaseAsset(ctx, id, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) uploadReleaseAsset(ctx context.Context, id int64, asset git.ReleaseAsset) error {
+	f, err := os.Open(asset.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, _, err = c.Repositories.UploadReleaseAsset(ctx, c.owner, c.repository, id, &github.UploadOptions{
+		Name: asset.Name,
+	}, f)
+	return err
+}
@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReleaseDeletesReleaseWhenUploadFails(t *testing.T) {
+	var deleted atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"ref": "refs/tags/v1.0.0", "object": map[string]any{"type": "commit", "sha": "abc"}})
+	})
+	mux.HandleFunc("POST /repos/foo/bar/releases", func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(t, w, map[string]any{"id": 42, "tag_name": "v1.0.0"})
+	})
+	mux.HandleFunc("DELETE /repos/foo/bar/releases/42", func(w http.ResponseWriter, req *http.Request) {
+		deleted.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.CreateRelease(context.Background(), git.ReleaseModel{
+		Tag: "v1.0.0",
+		Assets: []git.ReleaseAsset{{
+			Name: "app",
+			Path: filepath.Join(t.TempDir(), "missing"),
+		}},
+	})
+	require.Error(t, err)
+	assert.True(t, deleted.Load())
+}
+
+func TestCreateReleaseTagMissing(t *testing.T) {
+	var created atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/git/ref/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]any{"message": "Not Found"})
+	})
+	mux.HandleFunc("POST /repos/foo/bar/releases", func(w http.ResponseWriter, req *http.Request) {
+		created.Store(true)
+		writeJSON(t, w, map[string]any{"id": 42, "tag_name": "v1.0.0"})
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.CreateRelease(context.Background(), git.ReleaseModel{Tag: "v1.0.0"})
+	assert.True(t, git.IsNotFound(err), err)
+	assert.False(t, created.Load())
+}
+
+func TestGetReleaseNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/foo/bar/releases/tags/v1.0.0", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		writeJSON(t, w, map[string]any{"message": "Not Found"})
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.GetRelease(context.Background(), "v1.0.0")
+	assert.True(t, git.IsNotFound(err), err)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"context"
+	"terraform-provider-gitsync/internal/git"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GetChecks returns the jobs of the most recent pipeline that ran for the
+// given commit. No checks are returned until a pipeline has been created.
+func (c *Client) GetChecks(ctx context.Context, sha string) ([]git.Check, error) {
+	pipelines, _, err := c.Pipelines.ListProjectPipelines(c.projectPath(), &gitlab.ListProjectPipelinesOptions{
+		SHA:     gitlab.Ptr(sha),
+		OrderBy: gitlab.Ptr("id"),
+		Sort:    gitlab.Ptr("desc"),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+
+	var checks []git.Check
+	opts := &gitlab.ListJobsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		jobs, resp, err := c.Jobs.ListPipelineJobs(c.projectPath(), pipelines[0].ID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, job := range jobs {
+			checks = append(checks, git.Check{
+				Name:  job.Name,
+				State: jobState(job),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return checks, nil
+}
+
+func jobState(job *gitlab.Job) string {
+	switch job.Status {
+	case "success", "skipped", "manual":
+		return git.CheckStateSuccess
+	case "failed", "canceled":
+		if job.AllowFailure {
+			return git.CheckStateSuccess
+		}
+		return git.CheckStateFailure
+	default:
+		return git.CheckStatePending
+	}
+}
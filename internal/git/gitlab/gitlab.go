@@ -3,9 +3,12 @@
 package gitlab
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"terraform-provider-gitsync/internal/git"
@@ -77,85 +80,221 @@ func retryOnConflict(ctx context.Context, operation func() error) error {
 	return err
 }
 
-func (c *Client) Create(ctx context.Context, data git.ValuesModel) error {
-	return retryOnConflict(ctx, func() error {
+// Files are written through the commits API, as the repository files API does
+// not return the commit it created.
+func (c *Client) Create(ctx context.Context, data git.ValuesModel) (*git.Commit, error) {
+	var cmt *gitlab.Commit
+	err := retryOnConflict(ctx, func() error {
 		msg := fmt.Sprintf("terraform: Create %q at branch %q", data.Path, data.Branch)
-		opts := &gitlab.CreateFileOptions{
+		opts := &gitlab.CreateCommitOptions{
 			Branch:        gitlab.Ptr(data.Branch),
-			Content:       gitlab.Ptr(data.Content),
 			CommitMessage: gitlab.Ptr(msg),
+			Actions: []*gitlab.CommitActionOptions{
+				{
+					Action:   gitlab.Ptr(gitlab.FileCreate),
+					FilePath: gitlab.Ptr(data.Path),
+					Content:  gitlab.Ptr(base64.StdEncoding.EncodeToString(data.Content)),
+					Encoding: gitlab.Ptr("base64"),
+				},
+			},
 		}
 
-		_, _, err := c.RepositoryFiles.CreateFile(c.projectPath(), data.Path, opts, gitlab.WithContext(ctx))
-		return err
+		var err error
+		cmt, _, err = c.Commits.CreateCommit(c.projectPath(), opts, gitlab.WithContext(ctx))
+		return tooLarge(err, data)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return c.fileCommit(ctx, cmt, data.Path)
+}
+
+// tooLarge returns a FileTooLargeError when the server rejected the commit of
+// data because of its size, and err otherwise.
+func tooLarge(err error, data git.ValuesModel) error {
+	if glErr, ok := err.(*gitlab.ErrorResponse); ok && glErr.Response.StatusCode == http.StatusRequestEntityTooLarge {
+		return &git.FileTooLargeError{Path: data.Path, Size: int64(len(data.Content))}
+	}
+	return err
 }
 
+// get returns the metadata of a file without its content, which is read from
+// the raw file endpoint.
 func (c *Client) get(ctx context.Context, path, branch string) (*gitlab.File, error) {
-	file, _, err := c.RepositoryFiles.GetFile(
+	file, _, err := c.RepositoryFiles.GetFileMetaData(
 		c.projectPath(),
 		path,
-		&gitlab.GetFileOptions{Ref: gitlab.Ptr(branch)},
+		&gitlab.GetFileMetaDataOptions{Ref: gitlab.Ptr(branch)},
 		gitlab.WithContext(ctx),
 	)
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return nil, &git.NotFoundError{Path: path, Branch: branch}
+	}
 	if err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
+// notFound returns an ObjectNotFoundError for the kind and name when err is a
+// 404 response, and err otherwise.
+func notFound(err error, kind, name string) error {
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return &git.ObjectNotFoundError{Kind: kind, Name: name}
+	}
+	return err
+}
+
 func (c *Client) GetContent(ctx context.Context, path, branch string) (string, error) {
-	file, err := c.get(ctx, path, branch)
+	content, err := c.GetFile(ctx, path, branch)
 	if err != nil {
 		return "", err
 	}
-	if file == nil {
-		return "", fmt.Errorf("file %q does not exist on branch %q", path, branch)
+	return string(content), nil
+}
+
+func (c *Client) GetFile(ctx context.Context, path, branch string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := c.DownloadFile(ctx, path, branch, &buf); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	decoded, err := base64.StdEncoding.DecodeString(file.Content)
+// DownloadFile streams the file from the raw file endpoint, which unlike the
+// repository files API does not load the whole file as base64.
+func (c *Client) DownloadFile(ctx context.Context, path, branch string, w io.Writer) (int64, error) {
+	req, err := c.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf(
+			"projects/%s/repository/files/%s/raw",
+			gitlab.PathEscape(c.projectPath()),
+			gitlab.PathEscape(path),
+		),
+		&gitlab.GetRawFileOptions{Ref: gitlab.Ptr(branch)},
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)},
+	)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	return string(decoded), nil
+
+	cw := &countingWriter{w: w}
+	if _, err := c.Do(req, cw); err != nil {
+		if errors.Is(err, gitlab.ErrNotFound) {
+			return 0, &git.NotFoundError{Path: path, Branch: branch}
+		}
+		return cw.n, err
+	}
+	return cw.n, nil
 }
 
-func (c *Client) Update(ctx context.Context, data git.ValuesModel) error {
-	return retryOnConflict(ctx, func() error {
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+func (c *Client) Update(ctx context.Context, data git.ValuesModel) (*git.Commit, error) {
+	var cmt *gitlab.Commit
+	err := retryOnConflict(ctx, func() error {
 		file, err := c.get(ctx, data.Path, data.Branch)
 		if err != nil {
 			return err
 		}
 		if file == nil {
-			return fmt.Errorf("file %q does not exist on branch %q", data.Path, data.Branch)
+			return &git.NotFoundError{Path: data.Path, Branch: data.Branch}
+		}
+		if data.SHA != "" && file.BlobID != data.SHA {
+			return c.conflict(ctx, data.Path, data.Branch)
 		}
 
 		msg := fmt.Sprintf("terraform: Update %q at branch %q", data.Path, data.Branch)
-		opts := &gitlab.UpdateFileOptions{
+		opts := &gitlab.CreateCommitOptions{
 			Branch:        gitlab.Ptr(data.Branch),
-			Content:       gitlab.Ptr(data.Content),
 			CommitMessage: gitlab.Ptr(msg),
-			LastCommitID:  gitlab.Ptr(file.LastCommitID),
+			Actions: []*gitlab.CommitActionOptions{
+				{
+					Action:       gitlab.Ptr(gitlab.FileUpdate),
+					FilePath:     gitlab.Ptr(data.Path),
+					Content:      gitlab.Ptr(base64.StdEncoding.EncodeToString(data.Content)),
+					Encoding:     gitlab.Ptr("base64"),
+					LastCommitID: gitlab.Ptr(file.LastCommitID),
+				},
+			},
 		}
 
-		_, _, err = c.RepositoryFiles.UpdateFile(
-			c.projectPath(),
-			data.Path,
-			opts,
-			gitlab.WithContext(ctx),
-		)
-		return err
+		cmt, _, err = c.Commits.CreateCommit(c.projectPath(), opts, gitlab.WithContext(ctx))
+		return tooLarge(err, data)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return c.fileCommit(ctx, cmt, data.Path)
 }
 
-func (c *Client) Delete(ctx context.Context, path, branch string) error {
+func (c *Client) GetCommit(ctx context.Context, path, branch string) (*git.Commit, error) {
+	file, err := c.get(ctx, path, branch)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, &git.NotFoundError{Path: path, Branch: branch}
+	}
+
+	cmt, _, err := c.Commits.GetCommit(c.projectPath(), file.LastCommitID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return newCommit(cmt, file.BlobID), nil
+}
+
+func (c *Client) conflict(ctx context.Context, path, branch string) error {
+	commit, err := c.GetCommit(ctx, path, branch)
+	if err != nil {
+		return err
+	}
+	return &git.ConflictError{Path: path, Branch: branch, Commit: commit}
+}
+
+// fileCommit completes a created commit with the blob SHA of the written
+// file, which the commits API does not return.
+func (c *Client) fileCommit(ctx context.Context, cmt *gitlab.Commit, path string) (*git.Commit, error) {
+	file, err := c.get(ctx, path, cmt.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newCommit(cmt, file.BlobID), nil
+}
+
+func newCommit(cmt *gitlab.Commit, blobSHA string) *git.Commit {
+	commit := &git.Commit{
+		SHA:     cmt.ID,
+		BlobSHA: blobSHA,
+		URL:     cmt.WebURL,
+		Author:  cmt.AuthorName,
+	}
+	if cmt.CommittedDate != nil {
+		commit.CommittedAt = *cmt.CommittedDate
+	}
+	return commit
+}
+
+func (c *Client) Delete(ctx context.Context, path, branch, sha string) error {
 	return retryOnConflict(ctx, func() error {
 		file, err := c.get(ctx, path, branch)
 		if err != nil {
 			return err
 		}
 		if file == nil {
-			return fmt.Errorf("file %q does not exist on branch %q", path, branch)
+			return &git.NotFoundError{Path: path, Branch: branch}
+		}
+		if sha != "" && file.BlobID != sha {
+			return c.conflict(ctx, path, branch)
 		}
 
 		msg := fmt.Sprintf("terraform: Delete %q from branch %q", path, branch)
@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/gitlab-org/api/client-go"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL+"/api/v4"))
+	require.NoError(t, err)
+
+	return &Client{owner: "foo", repository: "bar", Client: client}
+}
+
+func TestGetCommit(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.EscapedPath() {
+		case "/api/v4/projects/foo%2Fbar/repository/files/values%2Eyaml":
+			assert.Equal(t, http.MethodHead, req.Method)
+			assert.Equal(t, "main", req.URL.Query().Get("ref"))
+			w.Header().Set("X-Gitlab-File-Path", "values.yaml")
+			w.Header().Set("X-Gitlab-Blob-Id", "blob")
+			w.Header().Set("X-Gitlab-Last-Commit-Id", "abc")
+		case "/api/v4/projects/foo%2Fbar/repository/commits/abc":
+			_, _ = w.Write([]byte(`{
+				"id": "abc",
+				"author_name": "Jane Doe",
+				"committed_date": "2024-01-02T10:00:00Z",
+				"web_url": "https://gitlab.com/foo/bar/-/commit/abc"
+			}`))
+		default:
+			t.Errorf("unexpected request %s", req.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	commit, err := client.GetCommit(context.Background(), "values.yaml", "main")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Commit{
+		SHA:         "abc",
+		BlobSHA:     "blob",
+		URL:         "https://gitlab.com/foo/bar/-/commit/abc",
+		CommittedAt: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Author:      "Jane Doe",
+	}, commit)
+}
+
+func TestGetContentNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 File Not Found"}`))
+	})
+
+	_, err := client.GetContent(context.Background(), "values.yaml", "main")
+	assert.True(t, git.IsNotFound(err), err)
+	assert.EqualError(t, err, `file "values.yaml" does not exist on branch "main"`)
+
+	_, err = client.GetCommit(context.Background(), "values.yaml", "main")
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestCreateTooLarge(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/repository/commits", req.URL.EscapedPath())
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	})
+
+	content := []byte("large file")
+	_, err := client.Create(context.Background(), git.ValuesModel{
+		Path:    "big.bin",
+		Branch:  "main",
+		Content: content,
+	})
+	var tooLarge *git.FileTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, "big.bin", tooLarge.Path)
+	assert.Equal(t, int64(len(content)), tooLarge.Size)
+}
+
+func TestTooLargeKeepsOtherErrors(t *testing.T) {
+	err := &gitlab.ErrorResponse{Response: &http.Response{StatusCode: http.StatusConflict}}
+	assert.Same(t, err, tooLarge(err, git.ValuesModel{Path: "big.bin"}))
+	assert.NoError(t, tooLarge(nil, git.ValuesModel{Path: "big.bin"}))
+}
+
+func TestDownloadFile(t *testing.T) {
+	content := strings.Repeat("large file\n", 1<<17)
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.EscapedPath() {
+		case "/api/v4/projects/foo%2Fbar/repository/files/charts%2Fbig%2Etxt/raw":
+			assert.Equal(t, "main", req.URL.Query().Get("ref"))
+			_, _ = w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"404 File Not Found"}`))
+		}
+	})
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	n, err := client.DownloadFile(ctx, "charts/big.txt", "main", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+
+	_, err = client.DownloadFile(ctx, "missing.txt", "main", &buf)
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestGetPullRequestNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/merge_requests/42", req.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Not found"}`))
+	})
+
+	_, err := client.GetPullRequest(context.Background(), 42)
+	assert.True(t, git.IsNotFound(err), err)
+}
+
+func TestGetRefNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/repository/branches/feature", req.URL.EscapedPath())
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"404 Branch Not Found"}`))
+	})
+
+	_, err := client.GetRef(context.Background(), git.RefPrefixBranch+"feature")
+	assert.True(t, git.IsNotFound(err), err)
+}
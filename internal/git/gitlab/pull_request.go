@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"terraform-provider-gitsync/internal/git"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	_ git.PullRequestClient = (*Client)(nil)
+)
+
+const (
+	mergeRequestStateOpened = "opened"
+	mergeRequestStateLocked = "locked"
+	stateEventClose         = "close"
+)
+
+func (c *Client) CreatePullRequest(ctx context.Context, data git.PullRequestModel) (*git.PullRequest, error) {
+	assignees, err := c.userIDs(ctx, data.Assignees)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := c.userIDs(ctx, data.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := gitlab.LabelOptions(data.Labels)
+	mr, _, err := c.MergeRequests.CreateMergeRequest(c.projectPath(), &gitlab.CreateMergeRequestOptions{
+		Title:        gitlab.Ptr(data.Title),
+		Description:  gitlab.Ptr(data.Body),
+		SourceBranch: gitlab.Ptr(data.HeadBranch),
+		TargetBranch: gitlab.Ptr(data.BaseBranch),
+		Labels:       &labels,
+		AssigneeIDs:  &assignees,
+		ReviewerIDs:  &reviewers,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetPullRequest(ctx, int(mr.IID))
+}
+
+func (c *Client) GetPullRequest(ctx context.Context, number int) (*git.PullRequest, error) {
+	mr, _, err := c.MergeRequests.GetMergeRequest(c.projectPath(), int64(number), nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, notFound(err, "merge request", strconv.Itoa(number))
+	}
+
+	approvals, _, err := c.MergeRequestApprovals.GetConfiguration(c.projectPath(), int64(number), gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	state := mr.State
+	switch state {
+	case mergeRequestStateOpened, mergeRequestStateLocked:
+		state = git.PullRequestStateOpen
+	}
+
+	assignees := make([]string, 0, len(mr.Assignees))
+	for _, a := range mr.Assignees {
+		assignees = append(assignees, a.Username)
+	}
+
+	mergeCommitSHA := mr.MergeCommitSHA
+	if mr.SquashCommitSHA != "" && mergeCommitSHA == "" {
+		mergeCommitSHA = mr.SquashCommitSHA
+	}
+
+	return &git.PullRequest{
+		Number:         int(mr.IID),
+		URL:            mr.WebURL,
+		State:          state,
+		Title:          mr.Title,
+		Body:           mr.Description,
+		HeadBranch:     mr.SourceBranch,
+		BaseBranch:     mr.TargetBranch,
+		Labels:         mr.Labels,
+		Assignees:      assignees,
+		Approved:       approvals.Approved && len(approvals.ApprovedBy) > 0,
+		MergeCommitSHA: mergeCommitSHA,
+	}, nil
+}
+
+func (c *Client) UpdatePullRequest(ctx context.Context, number int, data git.PullRequestModel) (*git.PullRequest, error) {
+	assignees, err := c.userIDs(ctx, data.Assignees)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := c.userIDs(ctx, data.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := gitlab.LabelOptions(data.Labels)
+	_, _, err = c.MergeRequests.UpdateMergeRequest(c.projectPath(), int64(number), &gitlab.UpdateMergeRequestOptions{
+		Title:       gitlab.Ptr(data.Title),
+		Description: gitlab.Ptr(data.Body),
+		Labels:      &labels,
+		AssigneeIDs: &assignees,
+		ReviewerIDs: &reviewers,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetPullRequest(ctx, number)
+}
+
+func (c *Client) ClosePullRequest(ctx context.Context, number int) error {
+	_, _, err := c.MergeRequests.UpdateMergeRequest(c.projectPath(), int64(number), &gitlab.UpdateMergeRequestOptions{
+		StateEvent: gitlab.Ptr(stateEventClose),
+	}, gitlab.WithContext(ctx))
+	return err
+}
+
+// GitLab merges with the method configured on the project, the only choice
+// left to the caller is whether the commits are squashed.
+func (c *Client) EnableAutoMerge(ctx context.Context, number int, mergeMethod string) error {
+	if mergeMethod == git.MergeMethodRebase {
+		return fmt.Errorf("merge method %q is not supported by GitLab, configure it on the project instead", mergeMethod)
+	}
+
+	_, _, err := c.MergeRequests.AcceptMergeRequest(c.projectPath(), int64(number), &gitlab.AcceptMergeRequestOptions{
+		AutoMerge: gitlab.Ptr(true),
+		Squash:    gitlab.Ptr(mergeMethod == git.MergeMethodSquash),
+	}, gitlab.WithContext(ctx))
+	return err
+}
+
+func (c *Client) DisableAutoMerge(ctx context.Context, number int) error {
+	_, _, err := c.MergeRequests.CancelMergeWhenPipelineSucceeds(c.projectPath(), int64(number), gitlab.WithContext(ctx))
+	return err
+}
+
+func (c *Client) userIDs(ctx context.Context, usernames []string) ([]int64, error) {
+	ids := make([]int64, 0, len(usernames))
+	for _, username := range usernames {
+		users, _, err := c.Users.ListUsers(&gitlab.ListUsersOptions{
+			Username: gitlab.Ptr(username),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %q does not exist", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"terraform-provider-gitsync/internal/git"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GitLab has no generic refs API, branches and tags are managed through their
+// own endpoints depending on the prefix of the ref.
+func (c *Client) CreateRef(ctx context.Context, name, source string) (*git.Ref, error) {
+	switch {
+	case strings.HasPrefix(name, git.RefPrefixBranch):
+		branch, _, err := c.Branches.CreateBranch(c.projectPath(), &gitlab.CreateBranchOptions{
+			Branch: gitlab.Ptr(strings.TrimPrefix(name, git.RefPrefixBranch)),
+			Ref:    gitlab.Ptr(source),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return &git.Ref{Name: name, SHA: branch.Commit.ID}, nil
+	case strings.HasPrefix(name, git.RefPrefixTag):
+		tag, _, err := c.Tags.CreateTag(c.projectPath(), &gitlab.CreateTagOptions{
+			TagName: gitlab.Ptr(strings.TrimPrefix(name, git.RefPrefixTag)),
+			Ref:     gitlab.Ptr(source),
+		}, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return &git.Ref{Name: name, SHA: tag.Commit.ID}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported ref %q", name)
+}
+
+func (c *Client) GetRef(ctx context.Context, name string) (*git.Ref, error) {
+	switch {
+	case strings.HasPrefix(name, git.RefPrefixBranch):
+		branch, _, err := c.Branches.GetBranch(
+			c.projectPath(),
+			strings.TrimPrefix(name, git.RefPrefixBranch),
+			gitlab.WithContext(ctx),
+		)
+		if err != nil {
+			return nil, notFound(err, "branch", strings.TrimPrefix(name, git.RefPrefixBranch))
+		}
+		return &git.Ref{Name: name, SHA: branch.Commit.ID}, nil
+	case strings.HasPrefix(name, git.RefPrefixTag):
+		tag, _, err := c.Tags.GetTag(
+			c.projectPath(),
+			strings.TrimPrefix(name, git.RefPrefixTag),
+			gitlab.WithContext(ctx),
+		)
+		if err != nil {
+			return nil, notFound(err, "tag", strings.TrimPrefix(name, git.RefPrefixTag))
+		}
+		return &git.Ref{Name: name, SHA: tag.Commit.ID}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported ref %q", name)
+}
+
+func (c *Client) DeleteRef(ctx context.Context, name string) error {
+	switch {
+	case strings.HasPrefix(name, git.RefPrefixBranch):
+		_, err := c.Branches.DeleteBranch(
+			c.projectPath(),
+			strings.TrimPrefix(name, git.RefPrefixBranch),
+			gitlab.WithContext(ctx),
+		)
+		return notFound(err, "branch", strings.TrimPrefix(name, git.RefPrefixBranch))
+	case strings.HasPrefix(name, git.RefPrefixTag):
+		_, err := c.Tags.DeleteTag(
+			c.projectPath(),
+			strings.TrimPrefix(name, git.RefPrefixTag),
+			gitlab.WithContext(ctx),
+		)
+		return notFound(err, "tag", strings.TrimPrefix(name, git.RefPrefixTag))
+	}
+
+	return fmt.Errorf("unsupported ref %q", name)
+}
+
+func (c *Client) CreateTag(ctx context.Context, name, source, message string) (*git.Tag, error) {
+	opts := &gitlab.CreateTagOptions{
+		TagName: gitlab.Ptr(name),
+		Ref:     gitlab.Ptr(source),
+	}
+	if message != "" {
+		opts.Message = gitlab.Ptr(message)
+	}
+
+	tag, _, err := c.Tags.CreateTag(c.projectPath(), opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &git.Tag{
+		Name:    tag.Name,
+		SHA:     tag.Commit.ID,
+		Message: tag.Message,
+	}, nil
+}
+
+func (c *Client) GetTag(ctx context.Context, name string) (*git.Tag, error) {
+	tag, _, err := c.Tags.GetTag(c.projectPath(), name, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, notFound(err, "tag", name)
+	}
+
+	return &git.Tag{
+		Name:    tag.Name,
+		SHA:     tag.Commit.ID,
+		Message: tag.Message,
+	}, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-gitsync/internal/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateRef(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/repository/branches", req.URL.EscapedPath())
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"branch": "feature", "ref": "main"}, body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"feature","commit":{"id":"abc"}}`))
+	})
+
+	ref, err := client.CreateRef(context.Background(), git.RefPrefixBranch+"feature", "main")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Ref{Name: "refs/heads/feature", SHA: "abc"}, ref)
+}
+
+func TestGetRefTag(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/repository/tags/v1.0.0", req.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"v1.0.0","commit":{"id":"abc"}}`))
+	})
+
+	ref, err := client.GetRef(context.Background(), git.RefPrefixTag+"v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, &git.Ref{Name: "refs/tags/v1.0.0", SHA: "abc"}, ref)
+}
+
+func TestDeleteRef(t *testing.T) {
+	deleted := false
+	client := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "/api/v4/projects/foo%2Fbar/repository/branches/feature", req.URL.EscapedPath())
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	require.NoError(t, client.DeleteRef(context.Background(), git.RefPrefixBranch+"feature"))
+	assert.True(t, deleted)
+
+	_, err := client.GetRef(context.Background(), "refs/notes/commits")
+	assert.EqualError(t, err, `unsupported ref "refs/notes/commits"`)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"terraform-provider-gitsync/internal/git"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	_ git.ReleaseClient = (*Client)(nil)
+)
+
+func (c *Client) CreateRelease(ctx context.Context, data git.ReleaseModel) (*git.Release, error) {
+	opts := &gitlab.CreateReleaseOptions{
+		TagName:     gitlab.Ptr(data.Tag),
+		Description: gitlab.Ptr(data.Notes),
+	}
+	if data.Name != "" {
+		opts.Name = gitlab.Ptr(data.Name)
+	}
+
+	_, _, err := c.Releases.CreateRelease(c.projectPath(), opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// The release is removed again, so that a failed upload can be retried
+	// by the next apply.
+	if err := c.uploadReleaseAssets(ctx, data.Tag, data.Assets); err != nil {
+		if deleteErr := c.DeleteRelease(ctx, data.Tag); deleteErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to delete the release again: %w", deleteErr))
+		}
+		return nil, err
+	}
+
+	return c.GetRelease(ctx, data.Tag)
+}
+
+func (c *Client) GetRelease(ctx context.Context, tag string) (*git.Release, error) {
+	release, _, err := c.Releases.GetRelease(c.projectPath(), tag, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, notFound(err, "release", tag)
+	}
+
+	assets := make([]git.ReleaseAsset, 0, len(release.Assets.Links))
+	for _, l := range release.Assets.Links {
+		assets = append(assets, git.ReleaseAsset{
+			Name: l.Name,
+			URL:  l.URL,
+		})
+	}
+
+	return &git.Release{
+		Tag:    release.TagName,
+		Name:   release.Name,
+		Notes:  release.Description,
+		URL:    release.Links.Self,
+		Assets: assets,
+	}, nil
+}
+
+func (c *Client) UpdateRelease(ctx context.Context, data git.ReleaseModel) (*git.Release, error) {
+	release, _, err := c.Releases.UpdateRelease(c.projectPath(), data.Tag, &gitlab.UpdateReleaseOptions{
+		Name:        gitlab.Ptr(data.Name),
+		Description: gitlab.Ptr(data.Notes),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Assets != nil {
+		for _, l := range release.Assets.Links {
+			_, _, err := c.ReleaseLinks.DeleteReleaseLink(c.projectPath(), data.Tag, l.ID, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.uploadReleaseAssets(ctx, data.Tag, data.Assets); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.GetRelease(ctx, data.Tag)
+}
+
+func (c *Client) DeleteRelease(ctx context.Context, tag string) error {
+	_, _, err := c.Releases.DeleteRelease(c.projectPath(), tag, gitlab.WithContext(ctx))
+	return notFound(err, "release", tag)
+}
+
+func (c *Client) uploadReleaseAssets(ctx context.Context, tag string, assets []git.ReleaseAsset) error {
+	for _, a := range assets {
+		if err := c.uploadReleaseAsset(ctx, tag, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GitLab releases only hold links, so the file is uploaded to the project
+// first and then linked from the release.
+func (c *Client) uploadReleaseAsset(ctx context.Context, tag string, asset git.ReleaseAsset) error {
+	f, err := os.Open(asset.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	upload, _, err := c.ProjectMarkdownUploads.UploadProjectMarkdown(
+		c.projectPath(),
+		f,
+		asset.Name,
+		gitlab.WithContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = c.ReleaseLinks.CreateReleaseLink(c.projectPath(), tag, &gitlab.CreateReleaseLinkOptions{
+		Name: gitlab.Ptr(asset.Name),
+		URL:  gitlab.Ptr(c.uploadURL(upload)),
+	}, gitlab.WithContext(ctx))
+	return err
+}
+
+// Older GitLab versions only return the upload URL relative to the project,
+// newer ones also return the full path relative to the instance.
+func (c *Client) uploadURL(upload *gitlab.ProjectMarkdownUploadedFile) string {
+	base := c.BaseURL()
+	host := fmt.Sprintf("%s://%s", base.Scheme, base.Host)
+	if upload.FullPath != "" {
+		return host + upload.FullPath
+	}
+	return fmt.Sprintf("%s/%s/%s", host, c.projectPath(), strings.TrimPrefix(upload.URL, "/"))
+}
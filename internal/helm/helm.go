@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package helm locates Helm charts in a repo and merges values the way Helm
+// does before it validates them against the values.schema.json of a chart.
+package helm
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+	// ChartFile marks the directory of a chart.
+	ChartFile = "Chart.yaml"
+	// ValuesFile holds the default values of a chart.
+	ValuesFile = "values.yaml"
+	// SchemaFile holds the JSON Schema of the values of a chart.
+	SchemaFile = "values.schema.json"
+)
+
+// ParentDirs returns the directories that may hold the chart of the values
+// file at p, from its own directory up to the root of the repo. The root is
+// returned as the empty string.
+func ParentDirs(p string) []string {
+	var dirs []string
+	dir := path.Dir(path.Clean(strings.TrimPrefix(p, "/")))
+	for {
+		if dir == "." {
+			return append(dirs, "")
+		}
+		dirs = append(dirs, dir)
+		dir = path.Dir(dir)
+	}
+}
+
+// CoalesceValues merges values over the default values of a chart like
+// Helm: maps are merged key by key, other values replace the default, and a
+// null value removes the default. The arguments are modified.
+func CoalesceValues(defaults, values any) any {
+	if values == nil {
+		return defaults
+	}
+	valuesMap, ok := values.(map[string]any)
+	if !ok {
+		return values
+	}
+	defaultsMap, ok := defaults.(map[string]any)
+	if !ok {
+		return removeNulls(valuesMap)
+	}
+
+	for key, def := range defaultsMap {
+		value, ok := valuesMap[key]
+		switch {
+		case !ok:
+			valuesMap[key] = def
+		case value == nil:
+			delete(valuesMap, key)
+		default:
+			if _, isMap := value.(map[string]any); isMap {
+				valuesMap[key] = CoalesceValues(def, value)
+			}
+		}
+	}
+	return removeNulls(valuesMap)
+}
+
+// removeNulls drops the null values that have no default to remove.
+func removeNulls(values map[string]any) map[string]any {
+	for key, value := range values {
+		switch v := value.(type) {
+		case nil:
+			delete(values, key)
+		case map[string]any:
+			removeNulls(v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,108 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentDirs(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "file in root",
+			path:     "values.yaml",
+			expected: []string{""},
+		},
+		{
+			name:     "nested file",
+			path:     "charts/app/values-prod.yaml",
+			expected: []string{"charts/app", "charts", ""},
+		},
+		{
+			name:     "leading slash",
+			path:     "/envs/prod/values.yaml",
+			expected: []string{"envs/prod", "envs", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParentDirs(tt.path))
+		})
+	}
+}
+
+func TestCoalesceValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults any
+		values   any
+		expected any
+	}{
+		{
+			name:     "no values",
+			defaults: map[string]any{"replicas": 1},
+			values:   nil,
+			expected: map[string]any{"replicas": 1},
+		},
+		{
+			name:     "no defaults",
+			defaults: nil,
+			values:   map[string]any{"replicas": 2, "debug": nil},
+			expected: map[string]any{"replicas": 2},
+		},
+		{
+			name: "nested maps are merged",
+			defaults: map[string]any{
+				"image":    map[string]any{"repository": "nginx", "tag": "1.0"},
+				"replicas": 1,
+			},
+			values: map[string]any{
+				"image": map[string]any{"tag": "2.0"},
+			},
+			expected: map[string]any{
+				"image":    map[string]any{"repository": "nginx", "tag": "2.0"},
+				"replicas": 1,
+			},
+		},
+		{
+			name:     "lists replace the default",
+			defaults: map[string]any{"args": []any{"a", "b"}},
+			values:   map[string]any{"args": []any{"c"}},
+			expected: map[string]any{"args": []any{"c"}},
+		},
+		{
+			name: "null removes the default",
+			defaults: map[string]any{
+				"resources": map[string]any{"limits": map[string]any{"cpu": "1"}},
+				"replicas":  1,
+			},
+			values: map[string]any{
+				"resources": map[string]any{"limits": nil},
+			},
+			expected: map[string]any{
+				"resources": map[string]any{},
+				"replicas":  1,
+			},
+		},
+		{
+			name:     "scalar replaces a map",
+			defaults: map[string]any{"service": map[string]any{"port": 80}},
+			values:   map[string]any{"service": "none"},
+			expected: map[string]any{"service": "none"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CoalesceValues(tt.defaults, tt.values))
+		})
+	}
+}
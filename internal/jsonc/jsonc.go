@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonc translates JSON with comments and trailing commas (JSONC) into
+// plain JSON.
+package jsonc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ToJSON returns content with comments and trailing commas replaced by
+// spaces. Offsets and line numbers stay the same, so errors of a JSON parser
+// point into the original content.
+func ToJSON(content []byte) ([]byte, error) {
+	out, err := stripComments(content)
+	if err != nil {
+		return nil, err
+	}
+	stripTrailingCommas(out)
+	return out, nil
+}
+
+func stripComments(content []byte) ([]byte, error) {
+	out := make([]byte, len(content))
+	copy(out, content)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			start := i
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; ; i++ {
+				if i+1 >= len(out) {
+					return nil, fmt.Errorf("unterminated comment at offset %d", start)
+				}
+				if out[i] == '*' && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+	if inString {
+		return nil, errors.New("unterminated string")
+	}
+	return out, nil
+}
+
+// stripTrailingCommas blanks commas that are followed by the end of an
+// object or array. Comments must be removed before.
+func stripTrailingCommas(out []byte) {
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case ',':
+			j := i + 1
+			for j < len(out) && isSpace(out[j]) {
+				j++
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
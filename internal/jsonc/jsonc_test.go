@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		err      string
+	}{
+		{
+			name:     "plain json",
+			content:  `{"a": [1, 2]}`,
+			expected: `{"a": [1, 2]}`,
+		},
+		{
+			name:     "line comment",
+			content:  "{\n  // name of the app\n  \"name\": \"app\"\n}",
+			expected: "{\n" + strings.Repeat(" ", 20) + "\n  \"name\": \"app\"\n}",
+		},
+		{
+			name:     "block comment keeps line breaks",
+			content:  "{/* a\nb */\"a\": 1}",
+			expected: "{    \n    \"a\": 1}",
+		},
+		{
+			name:     "trailing commas",
+			content:  "{\"a\": [1, 2,], \"b\": 3, // last\n}",
+			expected: "{\"a\": [1, 2 ], \"b\": 3         \n}",
+		},
+		{
+			name:     "comment markers in strings",
+			content:  `{"url": "https://example.com/*", "s": "a,]"}`,
+			expected: `{"url": "https://example.com/*", "s": "a,]"}`,
+		},
+		{
+			name:     "escaped quote in string",
+			content:  `{"a": "x\" // y"}`,
+			expected: `{"a": "x\" // y"}`,
+		},
+		{
+			name:    "unterminated comment",
+			content: "{\"a\": 1 /* open\n}",
+			err:     "unterminated comment at offset 8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := ToJSON([]byte(tt.content))
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+			assert.Len(t, out, len(tt.content))
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jsonpatch applies RFC 6902 JSON Patches and RFC 7386 JSON Merge
+// Patches to JSON documents and computes the patches that undo them. Object
+// keys keep their order, so a patched document only differs from the
+// original where the patch changed it.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// object is a JSON object that keeps the order of its keys.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *object {
+	return &object{values: map[string]any{}}
+}
+
+func (o *object) get(key string) (any, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+func (o *object) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) remove(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// Document is a parsed JSON document together with the layout it is written
+// back with.
+type Document struct {
+	root            any
+	indent          string
+	trailingNewline bool
+}
+
+// Parse parses content and detects its indentation.
+func Parse(content string) (*Document, error) {
+	root, err := decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON content: %w", err)
+	}
+
+	return &Document{
+		root:            root,
+		indent:          detectIndent(content),
+		trailingNewline: strings.HasSuffix(content, "\n"),
+	}, nil
+}
+
+// String renders the document with the indentation it was parsed with.
+func (d *Document) String() string {
+	var buf bytes.Buffer
+	write(&buf, d.root, d.indent, 0)
+	if d.trailingNewline {
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// Get returns the value at the JSON Pointer, rendered compactly with the
+// order of its keys kept.
+func (d *Document) Get(pointer string) (json.RawMessage, bool) {
+	path, err := parsePointer(pointer)
+	if err != nil {
+		return nil, false
+	}
+	value, ok := lookup(d.root, path)
+	if !ok {
+		return nil, false
+	}
+	return toJSON(value), true
+}
+
+func decode(content string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := newObject()
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.set(keyTok.(string), value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := []any{}
+			for dec.More() {
+				value, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %q", t)
+	default:
+		return t, nil
+	}
+}
+
+// detectIndent returns the leading whitespace of the first indented line, or
+// an empty string for a document written on a single line.
+func detectIndent(content string) string {
+	if !strings.Contains(strings.TrimSpace(content), "\n") {
+		return ""
+	}
+	for _, line := range strings.Split(content, "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+func write(buf *bytes.Buffer, value any, indent string, depth int) {
+	pad := strings.Repeat(indent, depth+1)
+	end := strings.Repeat(indent, depth)
+	newline, colon := "\n", ": "
+	if indent == "" {
+		// Documents written on a single line stay compact.
+		newline, colon = "", ":"
+	}
+
+	switch v := value.(type) {
+	case *object:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{" + newline)
+		for i, k := range v.keys {
+			if i > 0 {
+				buf.WriteString("," + newline)
+			}
+			buf.WriteString(pad)
+			writeScalar(buf, k)
+			buf.WriteString(colon)
+			write(buf, v.values[k], indent, depth+1)
+		}
+		buf.WriteString(newline + end + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[" + newline)
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteString("," + newline)
+			}
+			buf.WriteString(pad)
+			write(buf, e, indent, depth+1)
+		}
+		buf.WriteString(newline + end + "]")
+	default:
+		writeScalar(buf, v)
+	}
+}
+
+func writeScalar(buf *bytes.Buffer, value any) {
+	var scalar bytes.Buffer
+	enc := json.NewEncoder(&scalar)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(value)
+	buf.Write(bytes.TrimSuffix(scalar.Bytes(), []byte("\n")))
+}
+
+// equal compares two values, ignoring the order of object keys and the
+// notation of numbers.
+func equal(a, b any) bool {
+	switch av := a.(type) {
+	case *object:
+		bv, ok := b.(*object)
+		if !ok || len(av.keys) != len(bv.keys) {
+			return false
+		}
+		for _, k := range av.keys {
+			other, ok := bv.values[k]
+			if !ok || !equal(av.values[k], other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, _, errX := big.ParseFloat(av.String(), 10, 512, big.ToNearestEven)
+		y, _, errY := big.ParseFloat(bv.String(), 10, 512, big.ToNearestEven)
+		if errX != nil || errY != nil {
+			return av == bv
+		}
+		return x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
+
+// clone returns a deep copy of value.
+func clone(value any) any {
+	switch v := value.(type) {
+	case *object:
+		obj := newObject()
+		for _, k := range v.keys {
+			obj.set(k, clone(v.values[k]))
+		}
+		return obj
+	case []any:
+		arr := make([]any, len(v))
+		for i, e := range v {
+			arr[i] = clone(e)
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+// toJSON renders value compactly for use inside a patch.
+func toJSON(value any) json.RawMessage {
+	var buf bytes.Buffer
+	write(&buf, value, "", 0)
+	return json.RawMessage(buf.Bytes())
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "two space indent",
+			content: "{\n  \"b\": 1,\n  \"a\": [\n    true,\n    null\n  ]\n}\n",
+		},
+		{
+			name:    "tab indent without trailing newline",
+			content: "{\n\t\"name\": \"app\",\n\t\"empty\": {}\n}",
+		},
+		{
+			name:    "compact",
+			content: "{\"b\":1.50,\"a\":\"x\"}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, d.String())
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		patch    string
+		expected string
+		err      string
+	}{
+		{
+			name:     "add and replace",
+			content:  "{\n  \"name\": \"app\",\n  \"replicas\": 1\n}\n",
+			patch:    `[{"op":"replace","path":"/replicas","value":3},{"op":"add","path":"/debug","value":true}]`,
+			expected: "{\n  \"name\": \"app\",\n  \"replicas\": 3,\n  \"debug\": true\n}\n",
+		},
+		{
+			name:     "remove and append to array",
+			content:  "{\n  \"hosts\": [\n    \"a\"\n  ],\n  \"old\": {\n    \"x\": 1\n  }\n}\n",
+			patch:    `[{"op":"remove","path":"/old"},{"op":"add","path":"/hosts/-","value":"b"}]`,
+			expected: "{\n  \"hosts\": [\n    \"a\",\n    \"b\"\n  ]\n}\n",
+		},
+		{
+			name:     "move and copy",
+			content:  "{\"a\":{\"b\":1},\"c\":2}",
+			patch:    `[{"op":"move","from":"/a/b","path":"/c"},{"op":"copy","from":"/c","path":"/a/d"}]`,
+			expected: "{\"a\":{\"d\":1},\"c\":1}",
+		},
+		{
+			name:     "escaped pointer",
+			content:  "{\"a/b\":{\"c~d\":1}}",
+			patch:    `[{"op":"replace","path":"/a~1b/c~0d","value":2}]`,
+			expected: "{\"a/b\":{\"c~d\":2}}",
+		},
+		{
+			name:    "failed test",
+			content: "{\"a\":1}",
+			patch:   `[{"op":"test","path":"/a","value":2}]`,
+			err:     "test failed",
+		},
+		{
+			name:    "missing parent",
+			content: "{\"a\":1}",
+			patch:   `[{"op":"add","path":"/b/c","value":2}]`,
+			err:     "parent path does not exist",
+		},
+		{
+			name:    "unknown op",
+			content: "{}",
+			patch:   `[{"op":"merge","path":"/a"}]`,
+			err:     `unknown op "merge"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.content)
+			require.NoError(t, err)
+
+			reverse, err := ApplyPatch(d, tt.patch)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d.String())
+
+			applied, err := PatchApplied(d, tt.patch)
+			require.NoError(t, err)
+			assert.True(t, applied)
+
+			_, err = ApplyPatch(d, reverse)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, d.String())
+		})
+	}
+}
+
+func TestPatchApplied(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		patch    string
+		expected bool
+	}{
+		{
+			name:     "value present",
+			content:  `{"a":{"b":1.0}}`,
+			patch:    `[{"op":"add","path":"/a/b","value":1}]`,
+			expected: true,
+		},
+		{
+			name:    "value changed",
+			content: `{"a":{"b":2}}`,
+			patch:   `[{"op":"replace","path":"/a/b","value":1}]`,
+		},
+		{
+			name:    "removed key is back",
+			content: `{"a":1}`,
+			patch:   `[{"op":"remove","path":"/a"}]`,
+		},
+		{
+			name:     "appended element is last",
+			content:  `{"a":[1,2]}`,
+			patch:    `[{"op":"add","path":"/a/-","value":2}]`,
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.content)
+			require.NoError(t, err)
+
+			applied, err := PatchApplied(d, tt.patch)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, applied)
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		patch    string
+		expected string
+		reverse  string
+	}{
+		{
+			name:     "nested change",
+			content:  "{\n  \"image\": {\n    \"tag\": \"v1\",\n    \"pullPolicy\": \"Always\"\n  },\n  \"replicas\": 1\n}\n",
+			patch:    `{"image":{"tag":"v2","pullPolicy":null},"debug":true}`,
+			expected: "{\n  \"image\": {\n    \"tag\": \"v2\"\n  },\n  \"replicas\": 1,\n  \"debug\": true\n}\n",
+			reverse:  `{"image":{"pullPolicy":"Always","tag":"v1"},"debug":null}`,
+		},
+		{
+			name:     "scalar replaced by object",
+			content:  `{"a":1}`,
+			patch:    `{"a":{"b":2}}`,
+			expected: `{"a":{"b":2}}`,
+			reverse:  `{"a":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.content)
+			require.NoError(t, err)
+
+			reverse, err := ApplyMergePatch(d, tt.patch)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d.String())
+			assert.JSONEq(t, tt.reverse, reverse)
+
+			applied, err := MergePatchApplied(d, tt.patch)
+			require.NoError(t, err)
+			assert.True(t, applied)
+
+			_, err = ApplyMergePatch(d, reverse)
+			require.NoError(t, err)
+			applied, err = MergePatchApplied(d, tt.patch)
+			require.NoError(t, err)
+			assert.False(t, applied)
+		})
+	}
+}
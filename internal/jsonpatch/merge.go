@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import "fmt"
+
+// ApplyMergePatch applies the JSON Merge Patch to the document and returns
+// the JSON Merge Patch that undoes it. Values that were null before cannot be
+// restored, as null removes a key in a merge patch.
+func ApplyMergePatch(d *Document, patch string) (string, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse JSON Merge Patch: %w", err)
+	}
+
+	original := clone(d.root)
+	d.root = mergePatch(d.root, p)
+	return string(toJSON(mergeDiff(d.root, original))), nil
+}
+
+// MergePatchApplied reports whether applying the JSON Merge Patch again would
+// not change the document.
+func MergePatchApplied(d *Document, patch string) (bool, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse JSON Merge Patch: %w", err)
+	}
+	return equal(mergePatch(clone(d.root), p), d.root), nil
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(*object)
+	if !ok {
+		return clone(patch)
+	}
+
+	t, ok := target.(*object)
+	if !ok {
+		t = newObject()
+	}
+	for _, k := range p.keys {
+		v := p.values[k]
+		if v == nil {
+			t.remove(k)
+			continue
+		}
+		current, _ := t.get(k)
+		t.set(k, mergePatch(current, v))
+	}
+	return t
+}
+
+// mergeDiff returns the merge patch that turns from into to.
+func mergeDiff(from, to any) any {
+	f, fok := from.(*object)
+	t, tok := to.(*object)
+	if !fok || !tok {
+		return clone(to)
+	}
+
+	diff := newObject()
+	for _, k := range f.keys {
+		if _, ok := t.get(k); !ok {
+			diff.set(k, nil)
+		}
+	}
+	for _, k := range t.keys {
+		old, ok := f.get(k)
+		if !ok {
+			diff.set(k, clone(t.values[k]))
+			continue
+		}
+		if !equal(old, t.values[k]) {
+			diff.set(k, mergeDiff(old, t.values[k]))
+		}
+	}
+	return diff
+}
@@ -0,0 +1,402 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single RFC 6902 operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func parsePatch(patch string) ([]Operation, error) {
+	var ops []Operation
+	if err := json.Unmarshal([]byte(patch), &ops); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON Patch: %w", err)
+	}
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d (%s) has no value", i, op.Op)
+			}
+		case "move", "copy":
+			if op.From == "" && op.Path == "" {
+				return nil, fmt.Errorf("operation %d (%s) has no from", i, op.Op)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d has an unknown op %q", i, op.Op)
+		}
+	}
+	return ops, nil
+}
+
+// ApplyPatch applies the JSON Patch to the document and returns the JSON
+// Patch that undoes it.
+func ApplyPatch(d *Document, patch string) (string, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return "", err
+	}
+
+	var reverse []Operation
+	for i, op := range ops {
+		inverse, err := d.apply(op)
+		if err != nil {
+			return "", fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+		reverse = append(inverse, reverse...)
+	}
+
+	if reverse == nil {
+		reverse = []Operation{}
+	}
+	out, err := json.Marshal(reverse)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// PatchApplied reports whether the result of every operation of the JSON
+// Patch is present in the document.
+func PatchApplied(d *Document, patch string) (bool, error) {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return false, err
+	}
+
+	for _, op := range ops {
+		path, err := parsePointer(op.Path)
+		if err != nil {
+			return false, err
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			value, err := decode(string(op.Value))
+			if err != nil {
+				return false, err
+			}
+			current, ok := lookup(d.root, path)
+			if len(path) > 0 && path[len(path)-1] == "-" {
+				current, ok = lastElement(d.root, path[:len(path)-1])
+			}
+			if !ok || !equal(current, value) {
+				return false, nil
+			}
+		case "remove":
+			// Removing an array element leaves the next element at its index.
+			if _, isArray := parentValue(d.root, path).([]any); isArray {
+				continue
+			}
+			if _, ok := lookup(d.root, path); ok {
+				return false, nil
+			}
+		case "move":
+			from, err := parsePointer(op.From)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := lookup(d.root, path); !ok {
+				return false, nil
+			}
+			if _, isArray := parentValue(d.root, from).([]any); isArray {
+				continue
+			}
+			if _, ok := lookup(d.root, from); ok {
+				return false, nil
+			}
+		case "copy":
+			from, err := parsePointer(op.From)
+			if err != nil {
+				return false, err
+			}
+			source, ok := lookup(d.root, from)
+			if !ok {
+				return false, nil
+			}
+			current, ok := lookup(d.root, path)
+			if !ok || !equal(current, source) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// apply applies a single operation and returns the operations undoing it.
+func (d *Document) apply(op Operation) ([]Operation, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := decode(string(op.Value))
+		if err != nil {
+			return nil, err
+		}
+		return d.add(path, value)
+	case "remove":
+		old, err := d.remove(path)
+		if err != nil {
+			return nil, err
+		}
+		return []Operation{{Op: "add", Path: op.Path, Value: toJSON(old)}}, nil
+	case "replace":
+		value, err := decode(string(op.Value))
+		if err != nil {
+			return nil, err
+		}
+		old, ok := lookup(d.root, path)
+		if !ok {
+			return nil, fmt.Errorf("path does not exist")
+		}
+		if _, err := d.remove(path); err != nil {
+			return nil, err
+		}
+		if _, err := d.add(path, value); err != nil {
+			return nil, err
+		}
+		return []Operation{{Op: "replace", Path: op.Path, Value: toJSON(old)}}, nil
+	case "move":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+			return nil, fmt.Errorf("cannot move a value into itself")
+		}
+		value, err := d.remove(from)
+		if err != nil {
+			return nil, err
+		}
+		inverse, err := d.add(path, value)
+		if err != nil {
+			return nil, err
+		}
+		target := inverse[0].Path
+		if inverse[0].Op == "replace" {
+			// The move overwrote an existing key, which is put back after
+			// the value moved back.
+			return []Operation{
+				{Op: "move", From: target, Path: op.From},
+				{Op: "add", Path: target, Value: inverse[0].Value},
+			}, nil
+		}
+		return []Operation{{Op: "move", From: target, Path: op.From}}, nil
+	case "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := lookup(d.root, from)
+		if !ok {
+			return nil, fmt.Errorf("from path does not exist")
+		}
+		return d.add(path, clone(value))
+	case "test":
+		value, err := decode(string(op.Value))
+		if err != nil {
+			return nil, err
+		}
+		current, ok := lookup(d.root, path)
+		if !ok || !equal(current, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// add adds value at path and returns the operation undoing it.
+func (d *Document) add(path []string, value any) ([]Operation, error) {
+	if len(path) == 0 {
+		old := d.root
+		d.root = value
+		return []Operation{{Op: "replace", Path: "", Value: toJSON(old)}}, nil
+	}
+
+	parent, ok := lookup(d.root, path[:len(path)-1])
+	if !ok {
+		return nil, fmt.Errorf("parent path does not exist")
+	}
+	key := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case *object:
+		if old, ok := p.get(key); ok {
+			p.set(key, value)
+			return []Operation{{Op: "replace", Path: FormatPointer(path), Value: toJSON(old)}}, nil
+		}
+		p.set(key, value)
+		return []Operation{{Op: "remove", Path: FormatPointer(path)}}, nil
+	case []any:
+		index := len(p)
+		if key != "-" {
+			i, err := arrayIndex(key, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			index = i
+		}
+		p = slices.Insert(p, index, value)
+		if err := d.replaceContainer(path[:len(path)-1], p); err != nil {
+			return nil, err
+		}
+		target := append(slices.Clone(path[:len(path)-1]), strconv.Itoa(index))
+		return []Operation{{Op: "remove", Path: FormatPointer(target)}}, nil
+	default:
+		return nil, fmt.Errorf("parent is not an object or array")
+	}
+}
+
+// remove removes the value at path and returns it.
+func (d *Document) remove(path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the document root")
+	}
+
+	parent, ok := lookup(d.root, path[:len(path)-1])
+	if !ok {
+		return nil, fmt.Errorf("path does not exist")
+	}
+	key := path[len(path)-1]
+
+	switch p := parent.(type) {
+	case *object:
+		old, ok := p.get(key)
+		if !ok {
+			return nil, fmt.Errorf("path does not exist")
+		}
+		p.remove(key)
+		return old, nil
+	case []any:
+		index, err := arrayIndex(key, len(p))
+		if err != nil {
+			return nil, err
+		}
+		old := p[index]
+		if err := d.replaceContainer(path[:len(path)-1], slices.Delete(p, index, index+1)); err != nil {
+			return nil, err
+		}
+		return old, nil
+	default:
+		return nil, fmt.Errorf("path does not exist")
+	}
+}
+
+// replaceContainer stores an array that changed its length at path.
+func (d *Document) replaceContainer(path []string, value []any) error {
+	if len(path) == 0 {
+		d.root = value
+		return nil
+	}
+
+	parent, _ := lookup(d.root, path[:len(path)-1])
+	key := path[len(path)-1]
+	switch p := parent.(type) {
+	case *object:
+		p.set(key, value)
+	case []any:
+		index, err := arrayIndex(key, len(p))
+		if err != nil {
+			return err
+		}
+		p[index] = value
+	}
+	return nil
+}
+
+func lookup(root any, path []string) (any, bool) {
+	current := root
+	for _, key := range path {
+		switch c := current.(type) {
+		case *object:
+			v, ok := c.get(key)
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []any:
+			index, err := arrayIndex(key, len(c))
+			if err != nil {
+				return nil, false
+			}
+			current = c[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func lastElement(root any, path []string) (any, bool) {
+	value, ok := lookup(root, path)
+	if !ok {
+		return nil, false
+	}
+	arr, ok := value.([]any)
+	if !ok || len(arr) == 0 {
+		return nil, false
+	}
+	return arr[len(arr)-1], true
+}
+
+func parentValue(root any, path []string) any {
+	if len(path) == 0 {
+		return nil
+	}
+	value, _ := lookup(root, path[:len(path)-1])
+	return value
+}
+
+func arrayIndex(key string, length int) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	if index >= length {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// FormatPointer joins tokens into an RFC 6901 JSON Pointer.
+func FormatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(t, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
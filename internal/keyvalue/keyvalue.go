@@ -0,0 +1,303 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package keyvalue parses and edits line-oriented configuration files:
+// dotenv, INI and Java properties. Edits only touch the lines of the changed
+// keys, so comments, blank lines and unmanaged keys are kept as they are.
+package keyvalue
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatDotenv     Format = "dotenv"
+	FormatINI        Format = "ini"
+	FormatProperties Format = "properties"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{string(FormatDotenv), string(FormatINI), string(FormatProperties)}
+
+// DetectFormat returns the format of a file from its name. Dotenv files are
+// recognized by the .env extension and by names like .env or .env.local.
+func DetectFormat(path string) (Format, bool) {
+	base := filepath.Base(path)
+	switch {
+	case base == ".env" || strings.HasPrefix(base, ".env.") || filepath.Ext(base) == ".env":
+		return FormatDotenv, true
+	case filepath.Ext(base) == ".ini":
+		return FormatINI, true
+	case filepath.Ext(base) == ".properties":
+		return FormatProperties, true
+	}
+	return "", false
+}
+
+// ParseError locates an error in the content.
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// entry is a key and the lines it spans.
+type entry struct {
+	key   string
+	value string
+	// start and end are the first and last line of the entry.
+	start, end int
+	// keyStart, keyEnd and valueStart are offsets in the first line, suffix
+	// is the text after the value in the last line, like a comment.
+	keyStart, keyEnd int
+	valueStart       int
+	suffix           string
+	// quote is the quote character around a dotenv value.
+	quote byte
+}
+
+// section is an INI section. The global section before the first header
+// has an empty name and no header line.
+type section struct {
+	name    string
+	header  int
+	entries []entry
+}
+
+type Document struct {
+	format Format
+	lines  []string
+}
+
+// Parse parses content and reports syntax errors, invalid escapes and
+// duplicate keys.
+func Parse(format Format, content string) (*Document, error) {
+	if !slices.Contains(Formats, string(format)) {
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	d := &Document{format: format, lines: strings.Split(content, "\n")}
+	if _, err := d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// String returns the current content of the document.
+func (d *Document) String() string {
+	return strings.Join(d.lines, "\n")
+}
+
+// Keys returns the keys of the document in order. Keys of INI sections are
+// prefixed with the section name and a dot.
+func (d *Document) Keys() []string {
+	sections, _ := d.parse()
+
+	var keys []string
+	for _, s := range sections {
+		for _, e := range s.entries {
+			keys = append(keys, qualify(s.name, e.key))
+		}
+	}
+	return keys
+}
+
+// Get returns the decoded value of key.
+func (d *Document) Get(key string) (string, bool) {
+	sections, _ := d.parse()
+	_, e := d.find(sections, key)
+	if e == nil {
+		return "", false
+	}
+	return e.value, true
+}
+
+// Set sets the value of key. Existing keys are changed in place, new keys
+// are added after the last key of their section. Missing INI sections are
+// added at the end.
+func (d *Document) Set(key, value string) error {
+	sections, err := d.parse()
+	if err != nil {
+		return err
+	}
+	s, e := d.find(sections, key)
+	name, short := d.split(key)
+
+	if e != nil {
+		encoded, err := d.encodeValue(value, e.quote)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		line := d.lines[e.start][:e.valueStart] + encoded + e.suffix
+		d.lines = slices.Replace(d.lines, e.start, e.end+1, line)
+		return nil
+	}
+
+	line, err := d.encodeEntry(sections, short, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	if s == nil {
+		// Only INI keys can be in a missing section.
+		end := len(d.lines)
+		for end > 0 && strings.TrimSpace(d.lines[end-1]) == "" {
+			end--
+		}
+		added := []string{"[" + name + "]", line}
+		if end > 0 {
+			added = append([]string{""}, added...)
+		}
+		d.lines = slices.Insert(d.lines, end, added...)
+		return nil
+	}
+
+	at := s.header + 1
+	if len(s.entries) > 0 {
+		at = s.entries[len(s.entries)-1].end + 1
+	} else if s.header < 0 {
+		at = d.globalEnd()
+	}
+	d.lines = slices.Insert(d.lines, at, line)
+	return nil
+}
+
+// Delete removes key and reports whether it existed.
+func (d *Document) Delete(key string) (bool, error) {
+	sections, err := d.parse()
+	if err != nil {
+		return false, err
+	}
+	_, e := d.find(sections, key)
+	if e == nil {
+		return false, nil
+	}
+	d.lines = slices.Delete(d.lines, e.start, e.end+1)
+	return true, nil
+}
+
+// split splits a key into its INI section and the key within the section.
+// The section is the part before the last dot.
+func (d *Document) split(key string) (string, string) {
+	if d.format != FormatINI {
+		return "", key
+	}
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+func qualify(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
+
+func (d *Document) find(sections []section, key string) (*section, *entry) {
+	for i := range sections {
+		s := &sections[i]
+		for j := range s.entries {
+			if qualify(s.name, s.entries[j].key) == key {
+				return s, &s.entries[j]
+			}
+		}
+	}
+
+	name, _ := d.split(key)
+	for i := range sections {
+		if sections[i].name == name {
+			return &sections[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// globalEnd returns the line to add keys to the global section at. For INI
+// files this is before the first section header and the comments in front
+// of it, for the other formats the end of the content.
+func (d *Document) globalEnd() int {
+	if d.format == FormatINI {
+		for i, line := range d.lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "[") {
+				for i > 0 && isTrivia(d.format, d.lines[i-1]) {
+					i--
+				}
+				return i
+			}
+		}
+	}
+
+	end := len(d.lines)
+	for end > 0 && strings.TrimSpace(d.lines[end-1]) == "" {
+		end--
+	}
+	return end
+}
+
+// separator returns the text between key and value of the first entry, so
+// new entries follow the style of the file.
+func (d *Document) separator(sections []section) string {
+	for _, s := range sections {
+		for _, e := range s.entries {
+			if e.keyEnd <= e.valueStart {
+				return d.lines[e.start][e.keyEnd:e.valueStart]
+			}
+		}
+	}
+
+	if d.format == FormatINI {
+		return " = "
+	}
+	return "="
+}
+
+func (d *Document) encodeEntry(sections []section, key, value string) (string, error) {
+	encoded, err := d.encodeValue(value, 0)
+	if err != nil {
+		return "", err
+	}
+	if d.format == FormatProperties {
+		key = escapeProperties(key, true)
+	}
+	return key + d.separator(sections) + encoded, nil
+}
+
+func (d *Document) encodeValue(value string, quote byte) (string, error) {
+	switch d.format {
+	case FormatDotenv:
+		return encodeDotenv(value, quote), nil
+	case FormatINI:
+		if strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("INI values cannot contain line breaks")
+		}
+		return value, nil
+	default:
+		return escapeProperties(value, false), nil
+	}
+}
+
+func isTrivia(format Format, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return true
+	}
+	switch format {
+	case FormatINI:
+		return trimmed[0] == ';' || trimmed[0] == '#'
+	case FormatProperties:
+		return trimmed[0] == '#' || trimmed[0] == '!'
+	default:
+		return trimmed[0] == '#'
+	}
+}
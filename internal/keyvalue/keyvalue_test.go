@@ -0,0 +1,344 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keyvalue
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+		ok       bool
+	}{
+		{path: ".env", expected: FormatDotenv, ok: true},
+		{path: "config/.env.production", expected: FormatDotenv, ok: true},
+		{path: "app.env", expected: FormatDotenv, ok: true},
+		{path: "setup.ini", expected: FormatINI, ok: true},
+		{path: "src/main/resources/application.properties", expected: FormatProperties, ok: true},
+		{path: "values.yaml", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			format, ok := DetectFormat(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{
+			name:    "dotenv duplicate key",
+			format:  FormatDotenv,
+			content: "A=1\n# comment\nexport A=2\n",
+			line:    3,
+			column:  8,
+			message: `duplicate key "A"`,
+		},
+		{
+			name:    "dotenv invalid escape",
+			format:  FormatDotenv,
+			content: "A=\"one\\qtwo\"\n",
+			line:    1,
+			column:  7,
+			message: `invalid escape "\\q"`,
+		},
+		{
+			name:    "dotenv unterminated quote",
+			format:  FormatDotenv,
+			content: "A=1\nB='open\n",
+			line:    2,
+			column:  3,
+			message: "unterminated quoted value",
+		},
+		{
+			name:    "dotenv missing equals",
+			format:  FormatDotenv,
+			content: "A 1\n",
+			line:    1,
+			column:  3,
+			message: `expected "=" after key "A"`,
+		},
+		{
+			name:    "ini duplicate key in section",
+			format:  FormatINI,
+			content: "[db]\nhost = a\nhost = b\n",
+			line:    3,
+			column:  1,
+			message: `duplicate key "db.host"`,
+		},
+		{
+			name:    "ini duplicate section",
+			format:  FormatINI,
+			content: "[db]\nhost = a\n[db]\nport = 1\n",
+			line:    3,
+			column:  1,
+			message: `duplicate section "db"`,
+		},
+		{
+			name:    "ini unterminated section",
+			format:  FormatINI,
+			content: "[db\n",
+			line:    1,
+			column:  4,
+			message: "unterminated section header",
+		},
+		{
+			name:    "properties invalid unicode escape",
+			format:  FormatProperties,
+			content: "greeting = caf\\u00g9\n",
+			line:    1,
+			column:  15,
+			message: `invalid unicode escape "\\u00g9"`,
+		},
+		{
+			name:    "properties duplicate key across continuation",
+			format:  FormatProperties,
+			content: "a = 1\\\n    2\na: 3\n",
+			line:    3,
+			column:  1,
+			message: `duplicate key "a"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.format, tt.content)
+			require.Error(t, err)
+
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.line, parseErr.Line)
+			assert.Equal(t, tt.column, parseErr.Column)
+			assert.Equal(t, tt.message, parseErr.Message)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		content string
+		values  map[string]string
+	}{
+		{
+			name:    "dotenv",
+			format:  FormatDotenv,
+			content: "# app\nexport NAME=app # inline\nEMPTY=\nQUOTED=\"a \\\"b\\\"\\nc\"\nLITERAL='x $y \\n'\nMULTI=\"one\ntwo\"\n",
+			values: map[string]string{
+				"NAME":    "app",
+				"EMPTY":   "",
+				"QUOTED":  "a \"b\"\nc",
+				"LITERAL": `x $y \n`,
+				"MULTI":   "one\ntwo",
+			},
+		},
+		{
+			name:    "ini",
+			format:  FormatINI,
+			content: "root = yes\n\n; database\n[db]\nhost = localhost\nport: 5432\n\n[tool.lint]\nstrict=true\n",
+			values: map[string]string{
+				"root":             "yes",
+				"db.host":          "localhost",
+				"db.port":          "5432",
+				"tool.lint.strict": "true",
+			},
+		},
+		{
+			name:    "properties",
+			format:  FormatProperties,
+			content: "! comment\nserver.port=8080\nmessage = hello \\\n    world\nkey\\ with\\ spaces : value\nemoji=\\uD83D\\uDE00\ncafe caf\\u00e9\n",
+			values: map[string]string{
+				"server.port":     "8080",
+				"message":         "hello world",
+				"key with spaces": "value",
+				"emoji":           "😀",
+				"cafe":            "café",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.format, tt.content)
+			require.NoError(t, err)
+			assert.Len(t, doc.Keys(), len(tt.values))
+
+			for key, expected := range tt.values {
+				value, ok := doc.Get(key)
+				assert.True(t, ok, key)
+				assert.Equal(t, expected, value, key)
+			}
+			_, ok := doc.Get("missing")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		content  string
+		values   map[string]string
+		expected string
+	}{
+		{
+			name:     "dotenv keeps comments and quoting",
+			format:   FormatDotenv,
+			content:  "# app\nexport NAME=app # inline\nGREETING='hi'\nMULTI=\"one\ntwo\"\n",
+			values:   map[string]string{"NAME": "web", "GREETING": "hello", "MULTI": "single"},
+			expected: "# app\nexport NAME=web # inline\nGREETING='hello'\nMULTI=\"single\"\n",
+		},
+		{
+			name:     "dotenv quotes new values when needed",
+			format:   FormatDotenv,
+			content:  "A=1\n\n",
+			values:   map[string]string{"B": "two words", "C": "plain"},
+			expected: "A=1\nB=\"two words\"\nC=plain\n\n",
+		},
+		{
+			name:     "dotenv empty value with comment",
+			format:   FormatDotenv,
+			content:  "A= # unset\n",
+			values:   map[string]string{"A": "1"},
+			expected: "A=1 # unset\n",
+		},
+		{
+			name:     "ini adds keys to sections",
+			format:   FormatINI,
+			content:  "root = yes\n\n; database\n[db]\nhost = localhost\n\n[cache]\n",
+			values:   map[string]string{"db.port": "5432", "cache.ttl": "60", "debug": "false", "db.host": "db"},
+			expected: "root = yes\ndebug = false\n\n; database\n[db]\nhost = db\nport = 5432\n\n[cache]\nttl = 60\n",
+		},
+		{
+			name:     "ini adds missing section",
+			format:   FormatINI,
+			content:  "[db]\nhost=localhost\n",
+			values:   map[string]string{"tool.lint.strict": "true"},
+			expected: "[db]\nhost=localhost\n\n[tool.lint]\nstrict=true\n",
+		},
+		{
+			name:     "properties escapes values",
+			format:   FormatProperties,
+			content:  "# settings\nmessage = hello \\\n    world\n",
+			values:   map[string]string{"message": "bye", "path": "C:\\temp", "key with spaces": " padded"},
+			expected: "# settings\nmessage = bye\nkey\\ with\\ spaces = \\ padded\npath = C:\\\\temp\n",
+		},
+		{
+			name:     "empty file",
+			format:   FormatProperties,
+			content:  "",
+			values:   map[string]string{"a": "1"},
+			expected: "a=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.format, tt.content)
+			require.NoError(t, err)
+
+			for _, key := range sortedKeys(tt.values) {
+				require.NoError(t, doc.Set(key, tt.values[key]))
+			}
+			assert.Equal(t, tt.expected, doc.String())
+
+			parsed, err := Parse(tt.format, doc.String())
+			require.NoError(t, err)
+			for key, expected := range tt.values {
+				value, ok := parsed.Get(key)
+				assert.True(t, ok, key)
+				assert.Equal(t, expected, value, key)
+			}
+		})
+	}
+}
+
+func TestSetINILineBreak(t *testing.T) {
+	doc, err := Parse(FormatINI, "a = 1\n")
+	require.NoError(t, err)
+	assert.ErrorContains(t, doc.Set("a", "one\ntwo"), "cannot contain line breaks")
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		content  string
+		key      string
+		deleted  bool
+		expected string
+	}{
+		{
+			name:     "dotenv multi-line value",
+			format:   FormatDotenv,
+			content:  "A=1\nB=\"x\ny\"\nC=3\n",
+			key:      "B",
+			deleted:  true,
+			expected: "A=1\nC=3\n",
+		},
+		{
+			name:     "ini keeps empty section",
+			format:   FormatINI,
+			content:  "[db]\n; host\nhost = a\n",
+			key:      "db.host",
+			deleted:  true,
+			expected: "[db]\n; host\n",
+		},
+		{
+			name:     "properties continuation",
+			format:   FormatProperties,
+			content:  "a=1\\\n  2\nb=3\n",
+			key:      "a",
+			deleted:  true,
+			expected: "b=3\n",
+		},
+		{
+			name:     "missing key",
+			format:   FormatDotenv,
+			content:  "A=1\n",
+			key:      "B",
+			deleted:  false,
+			expected: "A=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.format, tt.content)
+			require.NoError(t, err)
+
+			deleted, err := doc.Delete(tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.deleted, deleted)
+			assert.Equal(t, tt.expected, doc.String())
+		})
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,419 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keyvalue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// dotenvEscapes are the escape sequences allowed in double-quoted dotenv
+// values.
+var dotenvEscapes = map[byte]byte{
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'"':  '"',
+	'\\': '\\',
+	'$':  '$',
+}
+
+func (d *Document) errorf(line, column int, format string, args ...any) *ParseError {
+	return &ParseError{Line: line + 1, Column: column + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (d *Document) parse() ([]section, error) {
+	sections := []section{{header: -1}}
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		if isTrivia(d.format, line) {
+			continue
+		}
+
+		if d.format == FormatINI && strings.HasPrefix(strings.TrimSpace(line), "[") {
+			name, err := d.parseSection(i)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range sections {
+				if s.header >= 0 && s.name == name {
+					return nil, d.errorf(i, strings.Index(line, "["), "duplicate section %q", name)
+				}
+			}
+			sections = append(sections, section{name: name, header: i})
+			continue
+		}
+
+		var e entry
+		var err error
+		switch d.format {
+		case FormatDotenv:
+			e, err = d.parseDotenv(i)
+		case FormatINI:
+			e, err = d.parseINI(i)
+		default:
+			e, err = d.parseProperties(i)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s := &sections[len(sections)-1]
+		key := qualify(s.name, e.key)
+		if seen[key] {
+			return nil, d.errorf(i, e.keyStart, "duplicate key %q", key)
+		}
+		seen[key] = true
+		s.entries = append(s.entries, e)
+		i = e.end
+	}
+	return sections, nil
+}
+
+func (d *Document) parseSection(i int) (string, error) {
+	line := d.lines[i]
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasSuffix(trimmed, "]") {
+		return "", d.errorf(i, len(strings.TrimRight(line, " \t\r")), "unterminated section header")
+	}
+	name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	if name == "" {
+		return "", d.errorf(i, strings.Index(line, "["), "empty section name")
+	}
+	return name, nil
+}
+
+func (d *Document) parseDotenv(i int) (entry, error) {
+	line := d.lines[i]
+	pos := skipSpace(line, 0)
+	if rest := line[pos:]; strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+		pos = skipSpace(line, pos+len("export"))
+	}
+
+	e := entry{start: i, end: i, keyStart: pos}
+	end := pos
+	for end < len(line) && isDotenvKeyChar(line[end], end == pos) {
+		end++
+	}
+	if end == pos {
+		return e, d.errorf(i, pos, "invalid key")
+	}
+	e.key, e.keyEnd = line[pos:end], end
+
+	eq := skipSpace(line, end)
+	if eq >= len(line) || line[eq] != '=' {
+		return e, d.errorf(i, eq, "expected \"=\" after key %q", e.key)
+	}
+	p := skipSpace(line, eq+1)
+	e.valueStart = p
+	if p < len(line) && (line[p] == '"' || line[p] == '\'') {
+		return e, d.parseQuoted(&e, p)
+	}
+
+	// An unquoted value ends at a comment that follows whitespace.
+	end = p
+	for end < len(line) && (line[end] != '#' || line[end-1] != ' ' && line[end-1] != '\t') {
+		end++
+	}
+	e.value = strings.TrimRight(line[p:end], " \t\r")
+	if e.value == "" {
+		e.valueStart = eq + 1
+	}
+	e.suffix = line[e.valueStart+len(e.value):]
+	return e, nil
+}
+
+// parseQuoted parses a quoted dotenv value starting at column p of the entry
+// line. Quoted values may span several lines.
+func (d *Document) parseQuoted(e *entry, p int) error {
+	quote := d.lines[e.start][p]
+	e.quote = quote
+
+	var b strings.Builder
+	i, col := e.start, p+1
+	for {
+		line := d.lines[i]
+		for col < len(line) {
+			c := line[col]
+			switch {
+			case c == quote:
+				e.end = i
+				e.value = b.String()
+				e.suffix = line[col+1:]
+				if rest := strings.TrimSpace(e.suffix); rest != "" && rest[0] != '#' {
+					return d.errorf(i, col+1, "unexpected content after quoted value")
+				}
+				return nil
+			case c == '\\' && quote == '"':
+				if col+1 >= len(line) {
+					return d.errorf(i, col, "invalid escape at end of line")
+				}
+				r, ok := dotenvEscapes[line[col+1]]
+				if !ok {
+					return d.errorf(i, col, "invalid escape %q", line[col:col+2])
+				}
+				b.WriteByte(r)
+				col += 2
+			default:
+				b.WriteByte(c)
+				col++
+			}
+		}
+		if i+1 >= len(d.lines) {
+			return d.errorf(e.start, p, "unterminated quoted value")
+		}
+		b.WriteByte('\n')
+		i, col = i+1, 0
+	}
+}
+
+func (d *Document) parseINI(i int) (entry, error) {
+	line := d.lines[i]
+	start := skipSpace(line, 0)
+	sep := strings.IndexAny(line, "=:")
+	if sep < 0 {
+		return entry{}, d.errorf(i, start, "expected a key and a value separated by \"=\" or \":\"")
+	}
+	key := strings.TrimRight(line[start:sep], " \t")
+	if key == "" {
+		return entry{}, d.errorf(i, start, "missing key")
+	}
+
+	p := skipSpace(line, sep+1)
+	value := strings.TrimRight(line[p:], " \t\r")
+	return entry{
+		key:        key,
+		value:      value,
+		start:      i,
+		end:        i,
+		keyStart:   start,
+		keyEnd:     start + len(key),
+		valueStart: p,
+		suffix:     line[p+len(value):],
+	}, nil
+}
+
+// position is the line and column of a byte of a logical properties line.
+type position struct {
+	line, column int
+}
+
+func (d *Document) parseProperties(i int) (entry, error) {
+	// A line ending in an odd number of backslashes continues on the next
+	// line, without the leading whitespace of that line.
+	var text []byte
+	var pos []position
+	e := entry{start: i, end: i}
+	first := strings.TrimSuffix(d.lines[i], "\r")
+	for j, col := i, skipSpace(d.lines[i], 0); ; {
+		line := d.lines[j]
+		if strings.HasSuffix(line, "\r") {
+			line, e.suffix = line[:len(line)-1], "\r"
+		} else {
+			e.suffix = ""
+		}
+		segment := line[col:]
+		continued := strings.HasSuffix(segment, "\\") &&
+			(len(segment)-len(strings.TrimRight(segment, "\\")))%2 == 1
+		if continued {
+			segment = segment[:len(segment)-1]
+			if j == i {
+				first = first[:len(first)-1]
+			}
+		}
+		for k := range len(segment) {
+			text = append(text, segment[k])
+			pos = append(pos, position{j, col + k})
+		}
+		e.end = j
+		if !continued || j+1 >= len(d.lines) {
+			break
+		}
+		j++
+		col = skipSpace(d.lines[j], 0)
+	}
+
+	// column returns the column in the first line of the byte at k, or the
+	// end of the first line when it is on a later line.
+	column := func(k int) int {
+		if k < len(pos) && pos[k].line == i {
+			return pos[k].column
+		}
+		return len(first)
+	}
+
+	k := 0
+	for k < len(text) && !strings.ContainsRune("=: \t\f", rune(text[k])) {
+		if text[k] == '\\' {
+			k++
+		}
+		k++
+	}
+	k = min(k, len(text))
+	e.keyStart, e.keyEnd = column(0), column(k)
+	if k == 0 {
+		return e, d.errorf(i, e.keyStart, "missing key")
+	}
+
+	p := k
+	for p < len(text) && strings.ContainsRune(" \t\f", rune(text[p])) {
+		p++
+	}
+	if p < len(text) && (text[p] == '=' || text[p] == ':') {
+		p++
+		for p < len(text) && strings.ContainsRune(" \t\f", rune(text[p])) {
+			p++
+		}
+	}
+	e.valueStart = column(p)
+
+	var err error
+	if e.key, err = d.unescapeProperties(text, pos, 0, k); err != nil {
+		return e, err
+	}
+	if e.value, err = d.unescapeProperties(text, pos, p, len(text)); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+func (d *Document) unescapeProperties(text []byte, pos []position, from, to int) (string, error) {
+	var b strings.Builder
+	for k := from; k < to; k++ {
+		c := text[k]
+		if c != '\\' || k+1 >= to {
+			b.WriteByte(c)
+			continue
+		}
+
+		k++
+		switch text[k] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, ok := hexRune(text[k+1 : min(k+5, to)])
+			if !ok {
+				at := pos[k-1]
+				return "", d.errorf(at.line, at.column, "invalid unicode escape %q", text[k-1:min(k+5, to)])
+			}
+			k += 4
+			// Characters outside the BMP are written as surrogate pairs.
+			if utf16.IsSurrogate(r) && k+6 < to && text[k+1] == '\\' && text[k+2] == 'u' {
+				if low, ok := hexRune(text[k+3 : min(k+7, to)]); ok {
+					if pair := utf16.DecodeRune(r, low); pair != unicode.ReplacementChar {
+						r = pair
+						k += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(text[k])
+		}
+	}
+	return b.String(), nil
+}
+
+func hexRune(digits []byte) (rune, bool) {
+	if len(digits) != 4 {
+		return 0, false
+	}
+	r, err := strconv.ParseUint(string(digits), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(r), true
+}
+
+func isDotenvKeyChar(c byte, first bool) bool {
+	switch {
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return true
+	case first:
+		return false
+	}
+	return c >= '0' && c <= '9' || c == '.' || c == '-'
+}
+
+func skipSpace(line string, from int) int {
+	for from < len(line) && (line[from] == ' ' || line[from] == '\t' || line[from] == '\f') {
+		from++
+	}
+	return from
+}
+
+// encodeDotenv writes a dotenv value. Values are quoted when they need to
+// be, and kept in the quotes they had before when possible.
+func encodeDotenv(value string, quote byte) string {
+	if quote == '\'' && !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	if quote == 0 && !strings.ContainsAny(value, " \t\r\n#\"'\\$`") {
+		return value
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := range len(value) {
+		switch c := value[i]; c {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// escapeProperties escapes a properties key or value. Separators and comment
+// characters are only escaped in keys.
+func escapeProperties(s string, key bool) string {
+	var b strings.Builder
+	for i := range len(s) {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\f':
+			b.WriteString(`\f`)
+		case ' ':
+			if key || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		case '=', ':', '#', '!':
+			if key {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build ignore
+
+// gen downloads the OpenAPI definitions of the bundled Kubernetes versions
+// from the Go module proxy and writes them to schemas/, without their
+// descriptions.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// versions are the Kubernetes releases to bundle, one patch release per minor
+// version.
+var versions = []string{
+	"v1.33.13",
+	"v1.34.4",
+	"v1.35.4",
+	"v1.36.3",
+}
+
+const proxy = "https://proxy.golang.org"
+
+func main() {
+	if err := os.MkdirAll("schemas", 0o755); err != nil {
+		log.Fatal(err)
+	}
+	for _, version := range versions {
+		if err := generate(version); err != nil {
+			log.Fatalf("%s: %v", version, err)
+		}
+	}
+}
+
+func generate(version string) error {
+	spec, err := download(version)
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Definitions map[string]any `json:"definitions"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(spec))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to parse swagger.json: %w", err)
+	}
+	stripDescriptions(doc.Definitions)
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	minor := version[:strings.LastIndex(version, ".")]
+	f, err := os.Create(filepath.Join("schemas", minor+".json.gz"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	zw.Comment = "k8s.io/kubernetes@" + version
+	if _, err := zw.Write(out); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// download reads api/openapi-spec/swagger.json from the module zip of the
+// release.
+func download(version string) ([]byte, error) {
+	resp, err := http.Get(fmt.Sprintf("%s/k8s.io/kubernetes/@v/%s.zip", proxy, version))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download module: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("k8s.io/kubernetes@%s/api/openapi-spec/swagger.json", version)
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+	return nil, fmt.Errorf("%s not found in module", name)
+}
+
+func stripDescriptions(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		delete(v, "description")
+		for key, e := range v {
+			// Properties may be called description themselves.
+			if key == "properties" {
+				if props, ok := e.(map[string]any); ok {
+					for _, prop := range props {
+						stripDescriptions(prop)
+					}
+					continue
+				}
+			}
+			stripDescriptions(e)
+		}
+	case []any:
+		for _, e := range v {
+			stripDescriptions(e)
+		}
+	}
+}
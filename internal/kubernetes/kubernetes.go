@@ -0,0 +1,339 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package kubernetes provides the schemas of Kubernetes manifests offline,
+// from the OpenAPI definitions bundled for a few Kubernetes versions and from
+// CustomResourceDefinition manifests. Like kubeconform in strict mode, the
+// schemas reject unknown fields and accept null for every field.
+package kubernetes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"terraform-provider-gitsync/internal/serialize"
+	"terraform-provider-gitsync/internal/yamledit"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:generate go run gen.go
+
+//go:embed schemas/*.json.gz
+var bundled embed.FS
+
+const (
+	baseURL        = "https://kubernetes.local/"
+	definitionsURL = baseURL + "definitions.json"
+	objectMetaRef  = definitionsURL + "#/definitions/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+	quantityName   = "io.k8s.apimachinery.pkg.api.resource.Quantity"
+)
+
+// Versions returns the bundled Kubernetes versions, like "1.36", oldest
+// first.
+func Versions() []string {
+	entries, _ := bundled.ReadDir("schemas")
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ".json.gz"), "v"))
+	}
+	slices.SortFunc(versions, compareVersions)
+	return versions
+}
+
+// LatestVersion returns the newest bundled Kubernetes version.
+func LatestVersion() string {
+	versions := Versions()
+	return versions[len(versions)-1]
+}
+
+func compareVersions(a, b string) int {
+	var aMajor, aMinor, bMajor, bMinor int
+	fmt.Sscanf(a, "%d.%d", &aMajor, &aMinor)
+	fmt.Sscanf(b, "%d.%d", &bMajor, &bMinor)
+	if aMajor != bMajor {
+		return aMajor - bMajor
+	}
+	return aMinor - bMinor
+}
+
+type gvk struct {
+	apiVersion string
+	kind       string
+}
+
+// Schemas resolves the schema of a manifest by its apiVersion and kind. It
+// is not safe for concurrent use.
+type Schemas struct {
+	compiler  *jsonschema.Compiler
+	locations map[gvk]string
+	compiled  map[gvk]*jsonschema.Schema
+}
+
+// Load returns the schemas of a bundled Kubernetes version, given as
+// "1.36", "v1.36" or with a patch release, and of the
+// CustomResourceDefinitions in crds. Every element of crds is the YAML
+// content of a file, other documents than CustomResourceDefinitions are
+// ignored.
+func Load(version string, crds ...string) (*Schemas, error) {
+	defs, err := loadDefinitions(version)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schemas{
+		compiler:  jsonschema.NewCompiler(),
+		locations: map[gvk]string{},
+		compiled:  map[gvk]*jsonschema.Schema{},
+	}
+	s.compiler.DefaultDraft(jsonschema.Draft4)
+	s.compiler.UseLoader(noLoader{})
+	if err := s.compiler.AddResource(definitionsURL, defs.doc); err != nil {
+		return nil, err
+	}
+	maps.Copy(s.locations, defs.locations)
+
+	for i, content := range crds {
+		if err := s.addCRDs(content); err != nil {
+			return nil, fmt.Errorf("CRD file %d: %w", i+1, err)
+		}
+	}
+	return s, nil
+}
+
+// Schema returns the schema of the manifests of apiVersion and kind, or nil
+// when there is none.
+func (s *Schemas) Schema(apiVersion, kind string) (*jsonschema.Schema, error) {
+	key := gvk{apiVersion: apiVersion, kind: kind}
+	if schema, ok := s.compiled[key]; ok {
+		return schema, nil
+	}
+	location, ok := s.locations[key]
+	if !ok {
+		return nil, nil
+	}
+	schema, err := s.compiler.Compile(location)
+	if err != nil {
+		return nil, err
+	}
+	s.compiled[key] = schema
+	return schema, nil
+}
+
+func (s *Schemas) addCRDs(content string) error {
+	found := false
+	for _, doc := range yamledit.SplitDocuments(content) {
+		value, err := serialize.ParseYAML(doc)
+		if err != nil {
+			return err
+		}
+		crd, ok := value.(map[string]any)
+		if !ok || crd["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+		if crd["apiVersion"] != "apiextensions.k8s.io/v1" {
+			return fmt.Errorf("CustomResourceDefinition of %v is not supported, must be apiextensions.k8s.io/v1", crd["apiVersion"])
+		}
+		if err := s.addCRD(crd); err != nil {
+			return err
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("no CustomResourceDefinition found")
+	}
+	return nil
+}
+
+func (s *Schemas) addCRD(crd map[string]any) error {
+	spec, _ := crd["spec"].(map[string]any)
+	group, _ := spec["group"].(string)
+	names, _ := spec["names"].(map[string]any)
+	kind, _ := names["kind"].(string)
+	versions, _ := spec["versions"].([]any)
+	if group == "" || kind == "" {
+		return errors.New("CustomResourceDefinition without spec.group or spec.names.kind")
+	}
+
+	for _, v := range versions {
+		version, _ := v.(map[string]any)
+		name, _ := version["name"].(string)
+		if name == "" {
+			return fmt.Errorf("version of %s without name", kind)
+		}
+
+		// Versions without a schema accept any content, like the API server.
+		schema := map[string]any{"type": "object"}
+		if validation, ok := version["schema"].(map[string]any); ok {
+			if openAPI, ok := validation["openAPIV3Schema"].(map[string]any); ok {
+				schema = openAPI
+			}
+		}
+		props, ok := schema["properties"].(map[string]any)
+		if !ok && schema["x-kubernetes-preserve-unknown-fields"] != true {
+			props = map[string]any{}
+			schema["properties"] = props
+		}
+		if props != nil {
+			addProperty(props, "apiVersion", map[string]any{"type": "string"})
+			addProperty(props, "kind", map[string]any{"type": "string"})
+			addProperty(props, "metadata", map[string]any{"$ref": objectMetaRef})
+		}
+		prepare(schema)
+
+		location := baseURL + path.Join("crds", group, name, kind+".json")
+		if err := s.compiler.AddResource(location, schema); err != nil {
+			return err
+		}
+		s.locations[gvk{apiVersion: group + "/" + name, kind: kind}] = location
+	}
+	return nil
+}
+
+func addProperty(props map[string]any, name string, schema map[string]any) {
+	if _, ok := props[name]; !ok {
+		props[name] = schema
+	}
+}
+
+type definitions struct {
+	doc       any
+	locations map[gvk]string
+}
+
+var (
+	definitionsMu    sync.Mutex
+	definitionsCache = map[string]*definitions{}
+)
+
+// loadDefinitions decodes and prepares the bundled definitions of version
+// once. The result is only read afterwards, so it is shared by all Schemas.
+func loadDefinitions(version string) (*definitions, error) {
+	minor := strings.TrimPrefix(version, "v")
+	if parts := strings.Split(minor, "."); len(parts) > 2 {
+		minor = strings.Join(parts[:2], ".")
+	}
+	if !slices.Contains(Versions(), minor) {
+		return nil, fmt.Errorf(
+			"kubernetes version %q is not bundled, must be one of %s",
+			version,
+			strings.Join(Versions(), ", "),
+		)
+	}
+
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	if d, ok := definitionsCache[minor]; ok {
+		return d, nil
+	}
+
+	f, err := bundled.Open("schemas/v" + minor + ".json.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &definitions{doc: doc, locations: map[gvk]string{}}
+	defs, _ := doc.(map[string]any)["definitions"].(map[string]any)
+	// Quantities are written as numbers as often as strings.
+	defs[quantityName] = map[string]any{"anyOf": []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "number"},
+	}}
+	for name, def := range defs {
+		prepare(def)
+		schema, _ := def.(map[string]any)
+		gvks, _ := schema["x-kubernetes-group-version-kind"].([]any)
+		if len(gvks) != 1 {
+			// Shared types like DeleteOptions list every group.
+			continue
+		}
+		g, _ := gvks[0].(map[string]any)
+		group, _ := g["group"].(string)
+		version, _ := g["version"].(string)
+		kind, _ := g["kind"].(string)
+		apiVersion := version
+		if group != "" {
+			apiVersion = group + "/" + version
+		}
+		d.locations[gvk{apiVersion: apiVersion, kind: kind}] = definitionsURL + "#/definitions/" + name
+	}
+	definitionsCache[minor] = d
+	return d, nil
+}
+
+// prepare turns an OpenAPI schema of Kubernetes into a JSON schema. Objects
+// with properties reject unknown fields unless they preserve them, every
+// type accepts null, and int-or-string accepts both.
+func prepare(value any) {
+	schema, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+
+	delete(schema, "description")
+	if schema["format"] == "int-or-string" || schema["x-kubernetes-int-or-string"] == true {
+		delete(schema, "type")
+		delete(schema, "format")
+		if _, ok := schema["anyOf"]; !ok {
+			schema["anyOf"] = []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "integer"},
+			}
+		}
+	}
+	if t, ok := schema["type"].(string); ok {
+		schema["type"] = []any{t, "null"}
+	}
+	if _, ok := schema["properties"]; ok {
+		if _, ok := schema["additionalProperties"]; !ok && schema["x-kubernetes-preserve-unknown-fields"] != true {
+			schema["additionalProperties"] = false
+		}
+	}
+
+	for _, key := range []string{"properties", "patternProperties"} {
+		if props, ok := schema[key].(map[string]any); ok {
+			for _, prop := range props {
+				prepare(prop)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not", "allOf", "anyOf", "oneOf"} {
+		switch v := schema[key].(type) {
+		case map[string]any:
+			prepare(v)
+		case []any:
+			for _, e := range v {
+				prepare(e)
+			}
+		}
+	}
+}
+
+// noLoader refuses to load other schemas, so validation stays offline.
+type noLoader struct{}
+
+func (noLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("cannot load %s, references to other documents are not supported", url)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersions(t *testing.T) {
+	versions := Versions()
+	require.NotEmpty(t, versions)
+	assert.IsIncreasing(t, versions)
+	assert.Equal(t, versions[len(versions)-1], LatestVersion())
+}
+
+func TestLoadVersion(t *testing.T) {
+	latest := LatestVersion()
+
+	tests := []struct {
+		name     string
+		version  string
+		errorMsg string
+	}{
+		{
+			name:    "minor version",
+			version: latest,
+		},
+		{
+			name:    "v prefix",
+			version: "v" + latest,
+		},
+		{
+			name:    "patch release",
+			version: latest + ".1",
+		},
+		{
+			name:     "not bundled",
+			version:  "1.20",
+			errorMsg: `kubernetes version "1.20" is not bundled, must be one of`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemas, err := Load(tt.version)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			schema, err := schemas.Schema("apps/v1", "Deployment")
+			require.NoError(t, err)
+			assert.NotNil(t, schema)
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	crd := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+    - name: v1alpha1
+    - name: v1
+      schema:
+        openAPIV3Schema:
+          type: object
+`
+	schemas, err := Load(LatestVersion(), crd)
+	require.NoError(t, err)
+
+	tests := []struct {
+		apiVersion string
+		kind       string
+		found      bool
+	}{
+		{apiVersion: "v1", kind: "ConfigMap", found: true},
+		{apiVersion: "apps/v1", kind: "StatefulSet", found: true},
+		{apiVersion: "networking.k8s.io/v1", kind: "Ingress", found: true},
+		{apiVersion: "example.com/v1", kind: "Widget", found: true},
+		{apiVersion: "example.com/v1alpha1", kind: "Widget", found: true},
+		{apiVersion: "example.com/v2", kind: "Widget", found: false},
+		{apiVersion: "v1", kind: "DeleteOptions", found: false},
+		{apiVersion: "apps/v1", kind: "ConfigMap", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiVersion+" "+tt.kind, func(t *testing.T) {
+			schema, err := schemas.Schema(tt.apiVersion, tt.kind)
+			require.NoError(t, err)
+			assert.Equal(t, tt.found, schema != nil)
+		})
+	}
+}
+
+func TestLoadCRDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		crd      string
+		errorMsg string
+	}{
+		{
+			name:     "no CRD",
+			crd:      "apiVersion: v1\nkind: ConfigMap\n",
+			errorMsg: "CRD file 1: no CustomResourceDefinition found",
+		},
+		{
+			name:     "v1beta1",
+			crd:      "apiVersion: apiextensions.k8s.io/v1beta1\nkind: CustomResourceDefinition\n",
+			errorMsg: "apiextensions.k8s.io/v1beta1 is not supported",
+		},
+		{
+			name:     "missing group",
+			crd:      "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nspec:\n  names:\n    kind: Widget\n",
+			errorMsg: "without spec.group or spec.names.kind",
+		},
+		{
+			name:     "invalid YAML",
+			crd:      "kind: [",
+			errorMsg: "failed to parse YAML content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(LatestVersion(), tt.crd)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	schema := map[string]any{
+		"description": "A widget.",
+		"type":        "object",
+		"properties": map[string]any{
+			"port": map[string]any{"type": "string", "format": "int-or-string"},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"extra": map[string]any{
+				"type":                                 "object",
+				"properties":                           map[string]any{},
+				"x-kubernetes-preserve-unknown-fields": true,
+			},
+		},
+	}
+	prepare(schema)
+
+	assert.Equal(t, map[string]any{
+		"type":                 []any{"object", "null"},
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"port": map[string]any{"anyOf": []any{
+				map[string]any{"type": []any{"string", "null"}},
+				map[string]any{"type": []any{"integer", "null"}},
+			}},
+			"labels": map[string]any{
+				"type":                 []any{"object", "null"},
+				"additionalProperties": map[string]any{"type": []any{"string", "null"}},
+			},
+			"extra": map[string]any{
+				"type":                                 []any{"object", "null"},
+				"properties":                           map[string]any{},
+				"x-kubernetes-preserve-unknown-fields": true,
+			},
+		},
+	}, schema)
+}
@@ -0,0 +1,372 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package merge implements a key-level three-way merge of structured files.
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"terraform-provider-gitsync/internal/jsonc"
+	"terraform-provider-gitsync/internal/jsonpatch"
+	"terraform-provider-gitsync/internal/yamledit"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConflictError lists the keys that were changed differently on both sides.
+type ConflictError struct {
+	Keys []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting changes to keys: %s", strings.Join(e.Keys, ", "))
+}
+
+// absent marks a key that does not exist on one side of the merge.
+type absent struct{}
+
+// YAML merges the changes between base and ours into theirs and returns the
+// merged document. Keys are compared at every mapping level, any other value
+// is merged as a whole. Streams of several documents are merged document by
+// document.
+func YAML(base, theirs, ours string) (string, error) {
+	b := yamledit.SplitDocuments(base)
+	t := yamledit.SplitDocuments(theirs)
+	o := yamledit.SplitDocuments(ours)
+	if len(b) <= 1 && len(t) <= 1 && len(o) <= 1 {
+		return yamlDocument(base, theirs, ours)
+	}
+
+	if len(b) != len(t) || len(t) != len(o) {
+		// Documents cannot be matched up once they were added or removed.
+		switch {
+		case base == theirs:
+			return ours, nil
+		case base == ours:
+			return theirs, nil
+		}
+		return "", &ConflictError{Keys: []string{"."}}
+	}
+
+	var merged strings.Builder
+	var conflicts []string
+	for i := range t {
+		doc, err := yamlDocument(b[i], t[i], o[i])
+		var conflictErr *ConflictError
+		if errors.As(err, &conflictErr) {
+			for _, key := range conflictErr.Keys {
+				conflicts = append(conflicts, fmt.Sprintf("document %d: %s", i+1, key))
+			}
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("document %d: %w", i+1, err)
+		}
+
+		// A document rendered again has lost its start marker.
+		if i > 0 && len(yamledit.SplitDocuments(merged.String()+doc)) != i+1 {
+			doc = "---\n" + doc
+		}
+		merged.WriteString(doc)
+	}
+	if len(conflicts) > 0 {
+		return "", &ConflictError{Keys: conflicts}
+	}
+	return merged.String(), nil
+}
+
+func yamlDocument(base, theirs, ours string) (string, error) {
+	var b, t, o any
+	for _, doc := range []struct {
+		content string
+		value   *any
+	}{{base, &b}, {theirs, &t}, {ours, &o}} {
+		if err := yaml.Unmarshal([]byte(doc.content), doc.value); err != nil {
+			return "", fmt.Errorf("failed to parse YAML content: %w", err)
+		}
+	}
+
+	switch {
+	case reflect.DeepEqual(b, t):
+		return ours, nil
+	case reflect.DeepEqual(b, o):
+		return theirs, nil
+	}
+
+	merged, err := threeWay(b, t, o)
+	if err != nil {
+		return "", err
+	}
+
+	// The merged keys are written into theirs, so the comments and layout of
+	// the remote file are kept.
+	tm, tok := t.(map[string]any)
+	mm, mok := merged.(map[string]any)
+	if doc, err := yamledit.Parse(theirs); err == nil && tok && mok {
+		if err := editYAML(doc, nil, tm, mm); err != nil {
+			return "", err
+		}
+		return doc.String(), nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(merged); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// editYAML changes the keys of doc that differ between from and to. Keys are
+// set before others are deleted, so a mapping that only changes its keys is
+// not removed as empty in between.
+func editYAML(doc *yamledit.Document, path []string, from, to map[string]any) error {
+	for _, key := range keys(to) {
+		current, exists := from[key]
+		if exists && reflect.DeepEqual(current, to[key]) {
+			continue
+		}
+
+		keyPath := append(slices.Clone(path), key)
+		fm, fok := current.(map[string]any)
+		tm, tok := to[key].(map[string]any)
+		if exists && fok && tok && len(tm) > 0 {
+			if err := editYAML(doc, keyPath, fm, tm); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var node yaml.Node
+		if err := node.Encode(to[key]); err != nil {
+			return err
+		}
+		if err := doc.Set(keyPath, &node); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range keys(from) {
+		if _, ok := to[key]; !ok {
+			if _, err := doc.Delete(append(slices.Clone(path), key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// JSON merges the changes between base and ours into theirs like YAML does.
+// The merged keys are written into theirs, so the order of its keys, its
+// indentation and the notation of its numbers are kept. Comments of JSONC
+// content are accepted, but not kept.
+func JSON(base, theirs, ours string) (string, error) {
+	var b, t, o any
+	var theirsJSON, oursJSON []byte
+	for _, doc := range []struct {
+		content string
+		value   *any
+		plain   *[]byte
+	}{{base, &b, nil}, {theirs, &t, &theirsJSON}, {ours, &o, &oursJSON}} {
+		plain, err := jsonc.ToJSON([]byte(doc.content))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse JSON content: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(plain))
+		dec.UseNumber()
+		if err := dec.Decode(doc.value); err != nil {
+			return "", fmt.Errorf("failed to parse JSON content: %w", err)
+		}
+		if doc.plain != nil {
+			*doc.plain = plain
+		}
+	}
+
+	switch {
+	case reflect.DeepEqual(b, t):
+		return ours, nil
+	case reflect.DeepEqual(b, o):
+		return theirs, nil
+	}
+
+	merged, err := threeWay(b, t, o)
+	if err != nil {
+		return "", err
+	}
+
+	tm, tok := t.(map[string]any)
+	mm, mok := merged.(map[string]any)
+	if !tok || !mok {
+		// Anything but two objects is only merged when both sides agree.
+		return ours, nil
+	}
+
+	doc, err := jsonpatch.Parse(string(theirsJSON))
+	if err != nil {
+		return "", err
+	}
+	oursDoc, err := jsonpatch.Parse(string(oursJSON))
+	if err != nil {
+		return "", err
+	}
+
+	var ops []jsonpatch.Operation
+	if err := editJSON(oursDoc, nil, tm, mm, &ops); err != nil {
+		return "", err
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return "", err
+	}
+	if _, err := jsonpatch.ApplyPatch(doc, string(patch)); err != nil {
+		return "", err
+	}
+	return doc.String(), nil
+}
+
+// editJSON adds the JSON Patch operations that change the keys of from to
+// the values they have in to. Changed values are taken from ours as they are
+// written there.
+func editJSON(ours *jsonpatch.Document, path []string, from, to map[string]any, ops *[]jsonpatch.Operation) error {
+	for _, key := range keys(to) {
+		current, exists := from[key]
+		if exists && reflect.DeepEqual(current, to[key]) {
+			continue
+		}
+
+		keyPath := append(slices.Clone(path), key)
+		fm, fok := current.(map[string]any)
+		tm, tok := to[key].(map[string]any)
+		if exists && fok && tok {
+			if err := editJSON(ours, keyPath, fm, tm, ops); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pointer := jsonpatch.FormatPointer(keyPath)
+		value, ok := ours.Get(pointer)
+		if !ok {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(to[key]); err != nil {
+				return err
+			}
+			value = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		}
+		*ops = append(*ops, jsonpatch.Operation{Op: "add", Path: pointer, Value: value})
+	}
+
+	for _, key := range keys(from) {
+		if _, ok := to[key]; !ok {
+			*ops = append(*ops, jsonpatch.Operation{Op: "remove", Path: jsonpatch.FormatPointer(append(slices.Clone(path), key))})
+		}
+	}
+	return nil
+}
+
+// TOML merges the changes between base and ours into theirs like YAML does.
+// The merged document is written without the comments of the inputs.
+func TOML(base, theirs, ours string) (string, error) {
+	var b, t, o map[string]any
+	for _, doc := range []struct {
+		content string
+		value   *map[string]any
+	}{{base, &b}, {theirs, &t}, {ours, &o}} {
+		if err := toml.Unmarshal([]byte(doc.content), doc.value); err != nil {
+			return "", fmt.Errorf("failed to parse TOML content: %w", err)
+		}
+	}
+
+	merged, err := threeWay(b, t, o)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := toml.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func threeWay(base, theirs, ours any) (any, error) {
+	var conflicts []string
+	merged := mergeValue("", base, theirs, ours, &conflicts)
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		return nil, &ConflictError{Keys: conflicts}
+	}
+	return merged, nil
+}
+
+func mergeValue(path string, base, theirs, ours any, conflicts *[]string) any {
+	switch {
+	case reflect.DeepEqual(theirs, ours):
+		return ours
+	case reflect.DeepEqual(base, theirs):
+		return ours
+	case reflect.DeepEqual(base, ours):
+		return theirs
+	}
+
+	b, bok := base.(map[string]any)
+	t, tok := theirs.(map[string]any)
+	o, ook := ours.(map[string]any)
+	if !bok || !tok || !ook {
+		if path == "" {
+			path = "."
+		}
+		*conflicts = append(*conflicts, path)
+		return ours
+	}
+
+	merged := make(map[string]any)
+	for _, key := range keys(b, t, o) {
+		value := mergeValue(joinPath(path, key), lookup(b, key), lookup(t, key), lookup(o, key), conflicts)
+		if _, ok := value.(absent); !ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func lookup(m map[string]any, key string) any {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return absent{}
+}
+
+func keys(maps ...map[string]any) []string {
+	var all []string
+	for _, m := range maps {
+		for k := range m {
+			if !slices.Contains(all, k) {
+				all = append(all, k)
+			}
+		}
+	}
+	slices.Sort(all)
+	return all
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
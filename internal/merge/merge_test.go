@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		theirs    string
+		ours      string
+		expected  string
+		conflicts []string
+	}{
+		{
+			name:     "no remote changes",
+			base:     "a: 1\nb: 2\n",
+			theirs:   "a: 1\nb: 2\n",
+			ours:     "a: 1\nb: 3\n",
+			expected: "a: 1\nb: 3\n",
+		},
+		{
+			name:     "unrelated keys changed",
+			base:     "a: 1\nb: 2\n",
+			theirs:   "a: 5\nb: 2\n",
+			ours:     "a: 1\nb: 3\n",
+			expected: "a: 5\nb: 3\n",
+		},
+		{
+			name:     "nested keys added and removed",
+			base:     "image:\n  tag: v1\n  pullPolicy: Always\n",
+			theirs:   "image:\n  tag: v1\n  pullPolicy: Always\n  registry: ghcr.io\n",
+			ours:     "image:\n  tag: v2\n",
+			expected: "image:\n  tag: v2\n  registry: ghcr.io\n",
+		},
+		{
+			name:     "remote comments and layout are kept",
+			base:     "# app\nreplicas: 1\nimage:\n  tag: v1 # pinned\n",
+			theirs:   "# app\nreplicas: 2 # scaled\n\nimage:\n  tag: v1 # pinned\n",
+			ours:     "replicas: 1\nimage:\n  tag: \"v2\"\n  pullPolicy: Always\n",
+			expected: "# app\nreplicas: 2 # scaled\n\nimage:\n  tag: v2 # pinned\n  pullPolicy: Always\n",
+		},
+		{
+			name:     "documents are merged one by one",
+			base:     "kind: Service\nport: 80\n---\nkind: Deployment\nreplicas: 1\n",
+			theirs:   "kind: Service\nport: 8080 # moved\n---\nkind: Deployment\nreplicas: 1\n",
+			ours:     "kind: Service\nport: 80\n---\nkind: Deployment\nreplicas: 3\n",
+			expected: "kind: Service\nport: 8080 # moved\n---\nkind: Deployment\nreplicas: 3\n",
+		},
+		{
+			name:      "conflicts name their document",
+			base:      "a: 1\n---\nb: 1\n",
+			theirs:    "a: 1\n---\nb: 2\n",
+			ours:      "a: 1\n---\nb: 3\n",
+			conflicts: []string{"document 2: b"},
+		},
+		{
+			name:      "documents added on both sides",
+			base:      "a: 1\n",
+			theirs:    "a: 1\n---\nb: 1\n",
+			ours:      "a: 2\n---\nc: 1\n---\nd: 1\n",
+			conflicts: []string{"."},
+		},
+		{
+			name:     "only remote changes",
+			base:     "a: 1\n",
+			theirs:   "# remote\na: 2\n",
+			ours:     "a: 1 # local\n",
+			expected: "# remote\na: 2\n",
+		},
+		{
+			name:     "same change on both sides",
+			base:     "a: 1\n",
+			theirs:   "a: 2\n",
+			ours:     "a: 2\n",
+			expected: "a: 2\n",
+		},
+		{
+			name:      "overlapping changes",
+			base:      "image:\n  tag: v1\nreplicas: 1\n",
+			theirs:    "image:\n  tag: v3\nreplicas: 2\n",
+			ours:      "image:\n  tag: v2\nreplicas: 3\n",
+			conflicts: []string{"image.tag", "replicas"},
+		},
+		{
+			name:      "lists are merged as a whole",
+			base:      "hosts:\n  - a\n",
+			theirs:    "hosts:\n  - a\n  - b\n",
+			ours:      "hosts:\n  - c\n",
+			conflicts: []string{"hosts"},
+		},
+		{
+			name:      "removed remotely and changed locally",
+			base:      "a: 1\nb: 2\n",
+			theirs:    "b: 2\n",
+			ours:      "a: 3\nb: 2\n",
+			conflicts: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := YAML(tt.base, tt.theirs, tt.ours)
+
+			if tt.conflicts != nil {
+				require.Error(t, err)
+
+				conflictErr, ok := err.(*ConflictError)
+				require.True(t, ok, "error should be a ConflictError")
+				assert.Equal(t, tt.conflicts, conflictErr.Keys)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		theirs    string
+		ours      string
+		expected  string
+		conflicts []string
+	}{
+		{
+			name:     "unrelated keys changed",
+			base:     `{"a": 1, "b": {"c": true}}`,
+			theirs:   "{\n    \"a\": 2,\n    \"b\": {\"c\": true}\n}\n",
+			ours:     `{"a": 1, "b": {"c": false}}`,
+			expected: "{\n    \"a\": 2,\n    \"b\": {\n        \"c\": false\n    }\n}\n",
+		},
+		{
+			name:     "large integers",
+			base:     `{"id": 12345678901234567890, "n": 1}`,
+			theirs:   `{"id": 12345678901234567890, "n": 2}`,
+			ours:     `{"id": 12345678901234567891, "n": 1}`,
+			expected: `{"id":12345678901234567891,"n":2}`,
+		},
+		{
+			name:     "html characters",
+			base:     `{"url": "a", "n": 1}`,
+			theirs:   `{"url": "a", "n": 2}`,
+			ours:     `{"url": "a<b>&c", "n": 1}`,
+			expected: `{"url":"a<b>&c","n":2}`,
+		},
+		{
+			name:     "key order",
+			base:     "{\n  \"z\": 1,\n  \"a\": 1\n}\n",
+			theirs:   "{\n  \"z\": 2,\n  \"m\": {\"y\": 1, \"b\": 1},\n  \"a\": 1\n}\n",
+			ours:     "{\n  \"z\": 1,\n  \"a\": 2,\n  \"new\": {\"y\": 1, \"b\": 1}\n}\n",
+			expected: "{\n  \"z\": 2,\n  \"m\": {\n    \"y\": 1,\n    \"b\": 1\n  },\n  \"a\": 2,\n  \"new\": {\n    \"y\": 1,\n    \"b\": 1\n  }\n}\n",
+		},
+		{
+			name:     "key removed",
+			base:     `{"a": 1, "b": 1}`,
+			theirs:   `{"a": 2, "b": 1}`,
+			ours:     `{"a": 1}`,
+			expected: `{"a":2}`,
+		},
+		{
+			name:      "overlapping changes",
+			base:      `{"a": 1}`,
+			theirs:    `{"a": 2}`,
+			ours:      `{"a": 3}`,
+			conflicts: []string{"a"},
+		},
+		{
+			name:     "jsonc content",
+			base:     "{\n  // replicas\n  \"a\": 1,\n}",
+			theirs:   "{\n  \"a\": 1,\n  \"b\": true,\n}",
+			ours:     `{"a": 2}`,
+			expected: "{\n  \"a\": 2,\n  \"b\": true\n}",
+		},
+		{
+			name:      "root replaced",
+			base:      `{"a": 1}`,
+			theirs:    `[1]`,
+			ours:      `{"a": 2}`,
+			conflicts: []string{"."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := JSON(tt.base, tt.theirs, tt.ours)
+
+			if tt.conflicts != nil {
+				require.Error(t, err)
+
+				conflictErr, ok := err.(*ConflictError)
+				require.True(t, ok, "error should be a ConflictError")
+				assert.Equal(t, tt.conflicts, conflictErr.Keys)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	_, err := YAML("a: 1\n", "a: [\n", "a: 2\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse YAML")
+
+	_, err = JSON(`{}`, `{`, `{}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse JSON")
+}
+
+func TestTOML(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		theirs    string
+		ours      string
+		expected  string
+		conflicts []string
+	}{
+		{
+			name:     "unrelated keys changed",
+			base:     "name = \"app\"\n\n[server]\nport = 80\n",
+			theirs:   "name = \"web\"\n\n[server]\nport = 80\n",
+			ours:     "name = \"app\"\n\n[server]\nport = 8080\n",
+			expected: "name = 'web'\n\n[server]\nport = 8080\n",
+		},
+		{
+			name:      "overlapping changes",
+			base:      "[server]\nport = 80\n",
+			theirs:    "[server]\nport = 81\n",
+			ours:      "[server]\nport = 82\n",
+			conflicts: []string{"server.port"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := TOML(tt.base, tt.theirs, tt.ours)
+
+			if tt.conflicts != nil {
+				require.Error(t, err)
+
+				conflictErr, ok := err.(*ConflictError)
+				require.True(t, ok, "error should be a ConflictError")
+				assert.Equal(t, tt.conflicts, conflictErr.Keys)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, merged)
+			}
+		})
+	}
+}
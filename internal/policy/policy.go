@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package policy evaluates Rego policies against parsed file content with the
+// embedded OPA library, following the conventions of conftest: the deny rules
+// of a package reject the content and its warn rules only report.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// DefaultNamespace is the Rego package of the deny and warn rules.
+const DefaultNamespace = "main"
+
+// Policy holds the prepared queries of a set of Rego modules.
+type Policy struct {
+	deny rego.PreparedEvalQuery
+	warn rego.PreparedEvalQuery
+}
+
+// Result holds the messages of the deny and warn rules, sorted.
+type Result struct {
+	Denials  []string
+	Warnings []string
+}
+
+// Load compiles modules, which map file names to Rego source, and prepares
+// the deny and warn rules of namespace. An empty namespace means
+// DefaultNamespace.
+func Load(ctx context.Context, namespace string, modules map[string]string) (*Policy, error) {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	var p Policy
+	for rule, query := range map[string]*rego.PreparedEvalQuery{"deny": &p.deny, "warn": &p.warn} {
+		options := []func(*rego.Rego){rego.Query(fmt.Sprintf("data.%s.%s", namespace, rule))}
+		for name, source := range modules {
+			options = append(options, rego.Module(name, source))
+		}
+
+		prepared, err := rego.New(options...).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile policy: %w", err)
+		}
+		*query = prepared
+	}
+	return &p, nil
+}
+
+// LoadFiles reads the Rego files at paths and loads them like Load.
+func LoadFiles(ctx context.Context, namespace string, paths []string) (*Policy, error) {
+	modules := make(map[string]string, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy: %w", err)
+		}
+		modules[path] = string(content)
+	}
+	return Load(ctx, namespace, modules)
+}
+
+// Eval evaluates the policy with input as the parsed content. Rules that are
+// not defined have no messages.
+func (p *Policy) Eval(ctx context.Context, input any) (Result, error) {
+	var result Result
+	var err error
+	if result.Denials, err = messages(ctx, p.deny, "deny", input); err != nil {
+		return Result{}, err
+	}
+	if result.Warnings, err = messages(ctx, p.warn, "warn", input); err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+func messages(ctx context.Context, query rego.PreparedEvalQuery, rule string, input any) ([]string, error) {
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate policy: %w", err)
+	}
+
+	var msgs []string
+	for _, r := range rs {
+		for _, expr := range r.Expressions {
+			switch v := expr.Value.(type) {
+			case []any:
+				for _, e := range v {
+					msgs = append(msgs, message(e))
+				}
+			case bool:
+				// Boolean rules like deny if { ... } carry no message.
+				if v {
+					msgs = append(msgs, fmt.Sprintf("the %s rule matched", rule))
+				}
+			default:
+				msgs = append(msgs, message(v))
+			}
+		}
+	}
+	slices.Sort(msgs)
+	return msgs, nil
+}
+
+// message returns a string rule value, the msg field of an object like
+// conftest, or the value as JSON.
+func message(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(out)
+}
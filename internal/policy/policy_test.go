@@ -0,0 +1,133 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const imagesPolicy = `package main
+
+deny contains msg if {
+	some container in input.spec.template.spec.containers
+	endswith(container.image, ":latest")
+	msg := sprintf("container %s uses the latest tag", [container.name])
+}
+
+deny contains msg if {
+	some container in input.spec.template.spec.containers
+	not container.resources.limits
+	msg := sprintf("container %s has no resource limits", [container.name])
+}
+
+warn contains {"msg": "replicas should be at least 2"} if {
+	input.spec.replicas < 2
+}
+`
+
+func TestEval(t *testing.T) {
+	p, err := Load(context.Background(), "", map[string]string{"images.rego": imagesPolicy})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    any
+		expected Result
+	}{
+		{
+			name: "compliant",
+			input: map[string]any{"spec": map[string]any{
+				"replicas": json.Number("3"),
+				"template": map[string]any{"spec": map[string]any{"containers": []any{
+					map[string]any{"name": "web", "image": "nginx:1.27", "resources": map[string]any{"limits": map[string]any{"cpu": "1"}}},
+				}}},
+			}},
+		},
+		{
+			name: "denied and warned",
+			input: map[string]any{"spec": map[string]any{
+				"replicas": json.Number("1"),
+				"template": map[string]any{"spec": map[string]any{"containers": []any{
+					map[string]any{"name": "web", "image": "nginx:latest"},
+					map[string]any{"name": "sidecar", "image": "envoy:1.30"},
+				}}},
+			}},
+			expected: Result{
+				Denials: []string{
+					"container sidecar has no resource limits",
+					"container web has no resource limits",
+					"container web uses the latest tag",
+				},
+				Warnings: []string{"replicas should be at least 2"},
+			},
+		},
+		{
+			name:  "not a manifest",
+			input: []any{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := p.Eval(context.Background(), tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvalNamespace(t *testing.T) {
+	modules := map[string]string{
+		"a.rego": "package gitsync.values\n\ndeny if input.debug\n",
+		"b.rego": "package gitsync.values\n\nwarn contains input.owner if input.owner != \"platform\"\n",
+		"c.rego": "package main\n\ndeny contains \"ignored\" if true\n",
+	}
+	p, err := Load(context.Background(), "gitsync.values", modules)
+	require.NoError(t, err)
+
+	result, err := p.Eval(context.Background(), map[string]any{"debug": true, "owner": "web"})
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Denials:  []string{"the deny rule matched"},
+		Warnings: []string{"web"},
+	}, result)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		modules  map[string]string
+		errorMsg string
+	}{
+		{
+			name:     "syntax error",
+			modules:  map[string]string{"broken.rego": "package main\n\ndeny contains msg if {"},
+			errorMsg: "broken.rego",
+		},
+		{
+			name:     "rego v0 syntax",
+			modules:  map[string]string{"old.rego": "package main\n\ndeny[msg] {\n\tmsg := \"old\"\n}\n"},
+			errorMsg: "failed to compile policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(context.Background(), "", tt.modules)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestLoadFiles(t *testing.T) {
+	_, err := LoadFiles(context.Background(), "", []string{"testdata/missing.rego"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read policy")
+}
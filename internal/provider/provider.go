@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"terraform-provider-gitsync/internal/git/factory"
+	"terraform-provider-gitsync/internal/policy"
 	gsresource "terraform-provider-gitsync/internal/resource"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -19,6 +20,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
 var _ provider.Provider = &gitSyncProvider{}
@@ -40,8 +42,9 @@ func New(version string) func() provider.Provider {
 
 // gitSyncProviderModel describes the provider data model.
 type gitSyncProviderModel struct {
-	URL   types.String `tfsdk:"url"`
-	Token types.String `tfsdk:"token"`
+	URL    types.String `tfsdk:"url"`
+	Token  types.String `tfsdk:"token"`
+	Policy types.Object `tfsdk:"policy"`
 }
 
 func (p *gitSyncProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -62,6 +65,23 @@ func (p *gitSyncProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				MarkdownDescription: "The personal access token used to authenticate with the Git provider API. The token must have sufficient permissions to create, update, and delete files in the target repository.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"policy": schema.SingleNestedBlock{
+				MarkdownDescription: "Rego policies evaluated with an embedded OPA against the parsed content of every `gitsync_values_yaml` and `gitsync_values_json` resource, during plan and before every commit. " +
+					"Messages of `deny` rules fail the plan, messages of `warn` rules are shown as warnings. Resources can add their own `policy`.",
+				Attributes: map[string]schema.Attribute{
+					"files": schema.ListAttribute{
+						MarkdownDescription: "Local Rego files, written in Rego v1 syntax.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"namespace": schema.StringAttribute{
+						MarkdownDescription: "Rego package of the `deny` and `warn` rules. Defaults to `main`.",
+						Optional:            true,
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -92,14 +112,44 @@ func (p *gitSyncProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
-	resp.ResourceData = client
+	var pol *policy.Policy
+	if !data.Policy.IsNull() && !data.Policy.IsUnknown() {
+		var cfg gsresource.PolicyModel
+		resp.Diagnostics.Append(data.Policy.As(ctx, &cfg, basetypes.ObjectAsOptions{})...)
+		var files []string
+		resp.Diagnostics.Append(cfg.Files.ElementsAs(ctx, &files, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		pol, err = policy.LoadFiles(ctx, cfg.Namespace.ValueString(), files)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid policy",
+				fmt.Sprintf("The policy cannot be loaded: %s", err.Error()),
+			)
+			return
+		}
+	}
+
+	resp.ResourceData = gsresource.NewProviderData(client, pol)
 }
 
 func (p *gitSyncProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		gsresource.NewValueYamlResource,
 		gsresource.NewValueJsonResource,
+		gsresource.NewValueTomlResource,
+		gsresource.NewValueHclResource,
+		gsresource.NewValueKeyValueResource,
+		gsresource.NewValueXmlResource,
 		gsresource.NewValueFileResource,
+		gsresource.NewPullRequestResource,
+		gsresource.NewBranchResource,
+		gsresource.NewTagResource,
+		gsresource.NewReleaseResource,
+		gsresource.NewYamlKeysResource,
+		gsresource.NewJsonPatchResource,
 	}
 }
 
@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
+)
+
+func configureProvider(t *testing.T, url string) any {
+	t.Helper()
+	ctx := context.Background()
+	p := New("test")()
+
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), schemaResp.Diagnostics)
+
+	schemaType := schemaResp.Schema.Type().TerraformType(ctx)
+	objectType, ok := schemaType.(tftypes.Object)
+	require.True(t, ok)
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw: tftypes.NewValue(schemaType, map[string]tftypes.Value{
+			"url":    tftypes.NewValue(tftypes.String, url),
+			"token":  tftypes.NewValue(tftypes.String, "token"),
+			"policy": tftypes.NewValue(objectType.AttributeTypes["policy"], nil),
+		}),
+	}
+
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	require.NotNil(t, resp.ResourceData)
+	return resp.ResourceData
+}
+
+func TestConfigureResources(t *testing.T) {
+	ctx := context.Background()
+	urls := map[string]string{
+		"github": "https://github.com/foo/bar",
+		"gitlab": "https://gitlab.com/foo/bar",
+	}
+
+	for name, url := range urls {
+		t.Run(name, func(t *testing.T) {
+			providerData := configureProvider(t, url)
+
+			for _, newResource := range New("test")().Resources(ctx) {
+				r := newResource()
+
+				var metadata resource.MetadataResponse
+				r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "gitsync"}, &metadata)
+
+				t.Run(metadata.TypeName, func(t *testing.T) {
+					rc, ok := r.(resource.ResourceWithConfigure)
+					require.True(t, ok, "resource does not implement Configure")
+
+					var resp resource.ConfigureResponse
+					rc.Configure(ctx, resource.ConfigureRequest{ProviderData: providerData}, &resp)
+					require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &BranchResource{}
+var _ resource.ResourceWithImportState = &BranchResource{}
+
+func NewBranchResource() resource.Resource {
+	return &BranchResource{}
+}
+
+type BranchResource struct {
+	client git.Client
+}
+
+type BranchResourceModel struct {
+	ID     types.String `tfsdk:"id"`
+	Branch types.String `tfsdk:"branch"`
+	Source types.String `tfsdk:"source"`
+	SHA    types.String `tfsdk:"sha"`
+}
+
+func (r *BranchResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_branch"
+}
+
+func (r *BranchResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a branch in a Git repository. The branch is deleted on destroy.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Name of the branch to create.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Branch, tag or commit SHA the branch is created from. Defaults to the main branch.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{requiresReplaceUnlessImported()},
+			},
+			"sha": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "SHA of the commit at the head of the branch.",
+			},
+		},
+	}
+}
+
+func (r *BranchResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *BranchResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data BranchResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	source := data.Source.ValueString()
+	if source == "" {
+		source = defaultBranch
+	}
+
+	ref, err := r.client.CreateRef(ctx, git.RefPrefixBranch+data.Branch.ValueString(), source)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create branch",
+			fmt.Sprintf(
+				"An error occurred while creating branch %q from %q: %v",
+				data.Branch.ValueString(),
+				source,
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(data.Branch.ValueString())
+	data.SHA = types.StringValue(ref.SHA)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BranchResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BranchResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref, err := r.client.GetRef(ctx, git.RefPrefixBranch+data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read branch",
+			fmt.Sprintf(
+				"An error occurred while reading branch %q: %v",
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.SHA = types.StringValue(ref.SHA)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Every attribute that changes the branch forces a replacement, so an update
+// only happens when source is set on an imported branch.
+func (r *BranchResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data BranchResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+}
+
+func (r *BranchResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data BranchResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteRef(ctx, git.RefPrefixBranch+data.Branch.ValueString())
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete branch",
+			fmt.Sprintf(
+				"An error occurred while deleting branch %q: %v",
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *BranchResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be the name of the branch",
+		)
+		return
+	}
+
+	ref, err := r.client.GetRef(ctx, git.RefPrefixBranch+req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read branch during import",
+			fmt.Sprintf(
+				"An error occurred while reading branch %q: %v",
+				req.ID,
+				err,
+			),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &BranchResourceModel{
+		ID:     types.StringValue(req.ID),
+		Branch: types.StringValue(req.ID),
+		Source: types.StringNull(),
+		SHA:    types.StringValue(ref.SHA),
+	})...)
+}
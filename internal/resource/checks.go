@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type WaitForChecksModel struct {
+	Timeout        types.String `tfsdk:"timeout"`
+	RequiredChecks types.Set    `tfsdk:"required_checks"`
+}
+
+func waitForChecksAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"timeout":         types.StringType,
+		"required_checks": types.SetType{ElemType: types.StringType},
+	}
+}
+
+func waitForChecksBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Wait for the CI checks of the created commit after every write and fail the apply when one of them fails. GitHub check runs and commit statuses, and the jobs of the latest GitLab pipeline are taken into account.",
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the checks to finish, as a Go duration string. Defaults to `30m`.",
+				Optional:            true,
+			},
+			"required_checks": schema.SetAttribute{
+				MarkdownDescription: "Names of the checks to wait for. All reported checks are waited for when omitted.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// validateWaitForChecks checks the timeout of the wait_for_checks block, so
+// an invalid value fails the plan instead of the apply after the commit.
+func validateWaitForChecks(ctx context.Context, block types.Object, diags *diag.Diagnostics) {
+	parseWaitForChecks(ctx, block, diags)
+}
+
+// parseWaitForChecks returns the timeout and the required checks of the
+// wait_for_checks block. Values that are not known yet are left at their
+// defaults.
+func parseWaitForChecks(ctx context.Context, block types.Object, diags *diag.Diagnostics) (time.Duration, []string) {
+	timeout := defaultWaitTimeout
+	if block.IsNull() || block.IsUnknown() {
+		return timeout, nil
+	}
+
+	var cfg WaitForChecksModel
+	if d := block.As(ctx, &cfg, basetypes.ObjectAsOptions{}); d.HasError() {
+		diags.Append(d...)
+		return timeout, nil
+	}
+
+	if !cfg.Timeout.IsUnknown() && cfg.Timeout.ValueString() != "" {
+		d, err := time.ParseDuration(cfg.Timeout.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("wait_for_checks").AtName("timeout"),
+				"Invalid wait_for_checks timeout",
+				fmt.Sprintf("The value %q is not a valid duration: %v", cfg.Timeout.ValueString(), err),
+			)
+		}
+		timeout = d
+	}
+
+	var required []string
+	if !cfg.RequiredChecks.IsUnknown() {
+		diags.Append(cfg.RequiredChecks.ElementsAs(ctx, &required, false)...)
+	}
+	return timeout, required
+}
+
+// waitForChecks blocks until all checks of the commit passed. It is a no-op
+// when the wait_for_checks block is not set.
+func waitForChecks(ctx context.Context, client git.Client, sha string, block types.Object) error {
+	if block.IsNull() || block.IsUnknown() {
+		return nil
+	}
+
+	var diags diag.Diagnostics
+	timeout, required := parseWaitForChecks(ctx, block, &diags)
+	if diags.HasError() {
+		return fmt.Errorf("invalid wait_for_checks block")
+	}
+
+	operation := func() (struct{}, error) {
+		checks, err := client.GetChecks(ctx, sha)
+		if err != nil {
+			return struct{}{}, backoff.Permanent(err)
+		}
+		return struct{}{}, evaluateChecks(checks, required)
+	}
+
+	_, err := backoff.Retry(
+		ctx,
+		operation,
+		backoff.WithBackOff(backoff.NewConstantBackOff(defaultPollInterval)),
+		backoff.WithMaxElapsedTime(timeout),
+	)
+	return err
+}
+
+// evaluateChecks returns nil once every relevant check succeeded, a permanent
+// error naming the failed checks and a retryable error while checks are still
+// pending or have not been reported yet.
+func evaluateChecks(checks []git.Check, required []string) error {
+	var failed, pending, seen []string
+	for _, c := range checks {
+		if len(required) > 0 && !slices.Contains(required, c.Name) {
+			continue
+		}
+
+		seen = append(seen, c.Name)
+		switch c.State {
+		case git.CheckStateFailure:
+			failed = append(failed, c.Name)
+		case git.CheckStatePending:
+			pending = append(pending, c.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return backoff.Permanent(fmt.Errorf("checks failed for commit: %s", strings.Join(failed, ", ")))
+	}
+
+	for _, name := range required {
+		if !slices.Contains(seen, name) {
+			pending = append(pending, name)
+		}
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("checks did not finish in time: %s", strings.Join(pending, ", "))
+	}
+	if len(seen) == 0 {
+		return errors.New("no checks were reported for the commit")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    []git.Check
+		required  []string
+		err       string
+		permanent bool
+	}{
+		{
+			name: "all passed",
+			checks: []git.Check{
+				{Name: "lint", State: git.CheckStateSuccess},
+				{Name: "test", State: git.CheckStateSuccess},
+			},
+		},
+		{
+			name: "failed",
+			checks: []git.Check{
+				{Name: "lint", State: git.CheckStateFailure},
+				{Name: "test", State: git.CheckStatePending},
+			},
+			err:       "checks failed for commit: lint",
+			permanent: true,
+		},
+		{
+			name: "pending",
+			checks: []git.Check{
+				{Name: "lint", State: git.CheckStateSuccess},
+				{Name: "test", State: git.CheckStatePending},
+			},
+			err: "checks did not finish in time: test",
+		},
+		{
+			name: "none reported",
+			err:  "no checks were reported for the commit",
+		},
+		{
+			name: "required missing",
+			checks: []git.Check{
+				{Name: "lint", State: git.CheckStateSuccess},
+			},
+			required: []string{"lint", "test"},
+			err:      "checks did not finish in time: test",
+		},
+		{
+			name: "other checks ignored",
+			checks: []git.Check{
+				{Name: "lint", State: git.CheckStateFailure},
+				{Name: "test", State: git.CheckStateSuccess},
+			},
+			required: []string{"test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := evaluateChecks(tt.checks, tt.required)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.err)
+			var permanent *backoff.PermanentError
+			assert.Equal(t, tt.permanent, errors.As(err, &permanent))
+		})
+	}
+}
+
+func waitForChecksValue(timeout types.String, required types.Set) types.Object {
+	return types.ObjectValueMust(waitForChecksAttrTypes(), map[string]attr.Value{
+		"timeout":         timeout,
+		"required_checks": required,
+	})
+}
+
+func TestParseWaitForChecks(t *testing.T) {
+	ctx := context.Background()
+	required := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("test")})
+
+	var diags diag.Diagnostics
+	timeout, checks := parseWaitForChecks(ctx, waitForChecksValue(types.StringValue("10m"), required), &diags)
+	require.False(t, diags.HasError(), diags)
+	assert.Equal(t, 10*time.Minute, timeout)
+	assert.Equal(t, []string{"test"}, checks)
+
+	timeout, _ = parseWaitForChecks(ctx, waitForChecksValue(types.StringUnknown(), types.SetUnknown(types.StringType)), &diags)
+	require.False(t, diags.HasError(), diags)
+	assert.Equal(t, defaultWaitTimeout, timeout)
+
+	validateWaitForChecks(ctx, waitForChecksValue(types.StringValue("10"), types.SetNull(types.StringType)), &diags)
+	require.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Detail(), `"10" is not a valid duration`)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"time"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// CommitModel holds the metadata of the last commit that touched a managed
+// file. It is embedded in the models of the file resources.
+type CommitModel struct {
+	CommitSHA   types.String `tfsdk:"commit_sha"`
+	BlobSHA     types.String `tfsdk:"blob_sha"`
+	CommitURL   types.String `tfsdk:"commit_url"`
+	CommittedAt types.String `tfsdk:"committed_at"`
+	LastAuthor  types.String `tfsdk:"last_author"`
+}
+
+func commitAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"commit_sha": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "SHA of the last commit that changed the file.",
+		},
+		"blob_sha": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "SHA of the file blob.",
+		},
+		"commit_url": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Web URL of the last commit that changed the file.",
+		},
+		"committed_at": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Time of the last commit that changed the file, in RFC 3339 format.",
+		},
+		"last_author": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: "Author name of the last commit that changed the file.",
+		},
+	}
+}
+
+func (m *CommitModel) setCommit(commit *git.Commit) {
+	m.CommitSHA = types.StringValue(commit.SHA)
+	m.BlobSHA = types.StringValue(commit.BlobSHA)
+	m.CommitURL = types.StringValue(commit.URL)
+	m.CommittedAt = types.StringValue(commit.CommittedAt.UTC().Format(time.RFC3339))
+	m.LastAuthor = types.StringValue(commit.Author)
+}
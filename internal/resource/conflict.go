@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/merge"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	onConflictFail      = "fail"
+	onConflictOverwrite = "overwrite"
+	onConflictMerge     = "merge"
+)
+
+var (
+	onConflictModes      = []string{onConflictFail, onConflictOverwrite}
+	onConflictMergeModes = []string{onConflictFail, onConflictOverwrite, onConflictMerge}
+)
+
+func onConflictAttribute(modes []string) schema.Attribute {
+	description := []string{
+		"What to do when the file was changed in the repository since it was last written by this resource, or since it was imported.",
+		"`fail` stops the apply, `overwrite` replaces the remote changes.",
+	}
+	if slices.Contains(modes, onConflictMerge) {
+		description = append(description,
+			"`merge` does a key-level three-way merge of the remote changes and the planned content and fails when both changed the same keys.",
+			"The merged content is committed, while the planned content is kept in state until the file is changed again.",
+		)
+	}
+	description = append(description, "Defaults to `fail`.")
+
+	return schema.StringAttribute{
+		MarkdownDescription: strings.Join(description, " "),
+		Optional:            true,
+	}
+}
+
+func validateOnConflict(value types.String, allowed []string, diags *diag.Diagnostics) {
+	if !value.IsNull() && !slices.Contains(allowed, value.ValueString()) {
+		diags.AddError(
+			"Invalid on_conflict value",
+			fmt.Sprintf("The value %q is not valid, must be one of %q", value.ValueString(), allowed),
+		)
+	}
+}
+
+// appliedKey is the private state key of the file as it was last written by
+// the resource.
+const appliedKey = "applied"
+
+// privateState is the private state of the framework requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// appliedFile is the file as it was last written by the resource. Read
+// refreshes blob_sha and content to the remote file, so writes are conditioned
+// on the applied blob SHA and merged against the applied content instead.
+type appliedFile struct {
+	BlobSHA string `json:"blob_sha"`
+	// Content is the planned content of the last write, recorded by the
+	// resources that merge. It is the base of the next merge.
+	Content *string `json:"content,omitempty"`
+	// Merged is set when remote changes were merged into the committed
+	// content, so it differs from Content.
+	Merged bool `json:"merged,omitempty"`
+}
+
+// newAppliedFile records a write of the planned content that committed the
+// given content.
+func newAppliedFile(commit *git.Commit, planned, committed string) appliedFile {
+	return appliedFile{
+		BlobSHA: commit.BlobSHA,
+		Content: &planned,
+		Merged:  committed != planned,
+	}
+}
+
+// appliedContent returns the content to keep in state for the remote file.
+// While the remote blob is still the one last written, it is the planned
+// content, so merged remote changes are not reported as a difference.
+func appliedContent(applied *appliedFile, commit *git.Commit, remote string) string {
+	if applied != nil && applied.Content != nil && applied.BlobSHA == commit.BlobSHA {
+		return *applied.Content
+	}
+	return remote
+}
+
+// getApplied returns the file as it was last written, or nil for imported
+// resources and resources created by older versions of the provider.
+func getApplied(ctx context.Context, private privateState, diags *diag.Diagnostics) *appliedFile {
+	value, d := private.GetKey(ctx, appliedKey)
+	diags.Append(d...)
+	if len(value) == 0 {
+		return nil
+	}
+
+	var applied appliedFile
+	if err := json.Unmarshal(value, &applied); err != nil {
+		diags.AddError(
+			"Invalid private state",
+			fmt.Sprintf("The recorded state of the last write cannot be decoded: %v", err),
+		)
+		return nil
+	}
+	return &applied
+}
+
+func setApplied(ctx context.Context, private privateState, applied appliedFile, diags *diag.Diagnostics) {
+	value, err := json.Marshal(applied)
+	if err != nil {
+		diags.AddError(
+			"Invalid private state",
+			fmt.Sprintf("The state of the last write cannot be encoded: %v", err),
+		)
+		return
+	}
+	diags.Append(private.SetKey(ctx, appliedKey, value)...)
+}
+
+// recordApplied returns the file as it was last written. When no write was
+// recorded, which is the case for imported resources and resources created by
+// older versions of the provider, the blob SHA of the prior state is recorded,
+// or the refreshed one right after an import. Writes are otherwise
+// conditioned on the blob SHA of the last refresh, which always matches.
+func recordApplied(ctx context.Context, private privateState, prior types.String, commit *git.Commit, diags *diag.Diagnostics) *appliedFile {
+	if applied := getApplied(ctx, private, diags); applied != nil {
+		return applied
+	}
+
+	applied := appliedFile{BlobSHA: prior.ValueString()}
+	if applied.BlobSHA == "" {
+		applied.BlobSHA = commit.BlobSHA
+	}
+	setApplied(ctx, private, applied, diags)
+	return &applied
+}
+
+// expectedSHA returns the blob SHA a write is conditioned on, which is empty
+// when remote changes are overwritten. It is the applied blob SHA, which Read
+// records for every resource, and blobSHA only when Read did not run yet.
+func expectedSHA(onConflict types.String, applied *appliedFile, blobSHA types.String) string {
+	if onConflict.ValueString() == onConflictOverwrite {
+		return ""
+	}
+	if applied != nil {
+		return applied.BlobSHA
+	}
+	return blobSHA.ValueString()
+}
+
+// updateFile writes the file and, with on_conflict set to merge, retries a
+// conflicting write with the remote changes merged into the content. base is
+// the content the planned change was made against, used when no write was
+// recorded. After a merge, the next write is merged right away, so the remote
+// changes it kept are not reverted. It returns the committed content.
+func updateFile(
+	ctx context.Context,
+	client git.Client,
+	data git.ValuesModel,
+	onConflict types.String,
+	applied *appliedFile,
+	base string,
+	mergeFunc func(base, theirs, ours string) (string, error),
+) (*git.Commit, string, error) {
+	if onConflict.ValueString() != onConflictMerge {
+		commit, err := client.Update(ctx, data)
+		return commit, string(data.Content), err
+	}
+
+	if applied != nil && applied.Content != nil {
+		base = *applied.Content
+	}
+
+	ours := string(data.Content)
+	mergeNow := applied != nil && applied.Merged
+	for attempt := 1; ; attempt++ {
+		if mergeNow {
+			remote, err := client.GetContent(ctx, data.Path, data.Branch)
+			if err != nil {
+				return nil, "", err
+			}
+			merged, err := mergeFunc(base, remote, ours)
+			if err != nil {
+				return nil, "", err
+			}
+			data.Content = []byte(merged)
+		}
+
+		commit, err := client.Update(ctx, data)
+		var conflict *git.ConflictError
+		if attempt == 2 || !errors.As(err, &conflict) {
+			return commit, string(data.Content), err
+		}
+		data.SHA = conflict.Commit.BlobSHA
+		mergeNow = true
+	}
+}
+
+// addConflictError adds a diagnostic naming the commit that changed the file,
+// or the keys that could not be merged, and reports whether err was a
+// conflict.
+func addConflictError(diags *diag.Diagnostics, err error) bool {
+	var mergeConflict *merge.ConflictError
+	if errors.As(err, &mergeConflict) {
+		diags.AddError(
+			"Failed to merge remote changes",
+			fmt.Sprintf(
+				"The file was changed outside of Terraform and the changes overlap with the planned content: %v",
+				mergeConflict,
+			),
+		)
+		return true
+	}
+
+	var conflict *git.ConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+
+	diags.AddError(
+		"File changed outside of Terraform",
+		fmt.Sprintf(
+			"%q in branch %q was changed by %s in commit %s (%s) since it was last written by Terraform. "+
+				"Set on_conflict to \"overwrite\" to replace the change.",
+			conflict.Path,
+			conflict.Branch,
+			conflict.Commit.Author,
+			conflict.Commit.SHA,
+			conflict.Commit.URL,
+		),
+	)
+	return true
+}
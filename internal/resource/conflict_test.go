@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/merge"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient keeps a single file in memory and rejects writes conditioned on
+// a stale blob SHA like the real backends do.
+type fakeClient struct {
+	git.Client
+
+	content string
+	commits int
+}
+
+func blobSHA(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *fakeClient) commit() *git.Commit {
+	return &git.Commit{
+		SHA:     fmt.Sprintf("commit-%d", c.commits),
+		BlobSHA: blobSHA(c.content),
+		Author:  "teammate",
+	}
+}
+
+// edit changes the file outside of Terraform.
+func (c *fakeClient) edit(content string) {
+	c.content = content
+	c.commits++
+}
+
+func (c *fakeClient) GetContent(ctx context.Context, path, branch string) (string, error) {
+	return c.content, nil
+}
+
+func (c *fakeClient) GetFile(ctx context.Context, path, branch string) ([]byte, error) {
+	return []byte(c.content), nil
+}
+
+func (c *fakeClient) GetCommit(ctx context.Context, path, branch string) (*git.Commit, error) {
+	return c.commit(), nil
+}
+
+func (c *fakeClient) Update(ctx context.Context, data git.ValuesModel) (*git.Commit, error) {
+	if data.SHA != "" && data.SHA != blobSHA(c.content) {
+		return nil, &git.ConflictError{Path: data.Path, Branch: data.Branch, Commit: c.commit()}
+	}
+	c.edit(string(data.Content))
+	return c.commit(), nil
+}
+
+type fakePrivateState map[string][]byte
+
+func (p fakePrivateState) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p fakePrivateState) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestExpectedSHAUsesAppliedBlob(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{}
+	private := fakePrivateState{}
+	var diags diag.Diagnostics
+
+	// Apply the file and record the write.
+	commit, err := client.Update(ctx, git.ValuesModel{Path: "values.yaml", Content: []byte("a: 1\n")})
+	require.NoError(t, err)
+	setApplied(ctx, private, appliedFile{BlobSHA: commit.BlobSHA}, &diags)
+
+	// A teammate edits the file and a refresh picks up the new blob SHA.
+	client.edit("a: 1\nb: 2\n")
+	refreshed := types.StringValue(client.commit().BlobSHA)
+
+	applied := getApplied(ctx, private, &diags)
+	require.False(t, diags.HasError(), diags)
+	require.NotNil(t, applied)
+
+	_, err = client.Update(ctx, git.ValuesModel{
+		Path:    "values.yaml",
+		Content: []byte("a: 2\n"),
+		SHA:     expectedSHA(types.StringNull(), applied, refreshed),
+	})
+	assert.ErrorAs(t, err, new(*git.ConflictError))
+	assert.Equal(t, "a: 1\nb: 2\n", client.content)
+
+	// The SHA of the refresh is only used when no write was recorded.
+	assert.Equal(t, refreshed.ValueString(), expectedSHA(types.StringNull(), nil, refreshed))
+	assert.Empty(t, expectedSHA(types.StringValue(onConflictOverwrite), applied, refreshed))
+}
+
+func TestRecordAppliedWithoutWrite(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{}
+	var diags diag.Diagnostics
+
+	// State written before writes were recorded holds the blob SHA of the
+	// last apply, which a teammate changed since.
+	commit, err := client.Update(ctx, git.ValuesModel{Path: "values.yaml", Content: []byte("a: 1\n")})
+	require.NoError(t, err)
+	prior := types.StringValue(commit.BlobSHA)
+	client.edit("a: 1\nb: 2\n")
+
+	private := fakePrivateState{}
+	applied := recordApplied(ctx, private, prior, client.commit(), &diags)
+	require.False(t, diags.HasError(), diags)
+	assert.Equal(t, prior.ValueString(), applied.BlobSHA)
+
+	refreshed := types.StringValue(client.commit().BlobSHA)
+	_, err = client.Update(ctx, git.ValuesModel{
+		Path:    "values.yaml",
+		Content: []byte("a: 2\n"),
+		SHA:     expectedSHA(types.StringNull(), getApplied(ctx, private, &diags), refreshed),
+	})
+	assert.ErrorAs(t, err, new(*git.ConflictError))
+
+	// An imported resource has no prior blob SHA and records the refreshed
+	// one, and a recorded write is kept.
+	imported := fakePrivateState{}
+	applied = recordApplied(ctx, imported, types.StringNull(), client.commit(), &diags)
+	assert.Equal(t, refreshed.ValueString(), applied.BlobSHA)
+	applied = recordApplied(ctx, private, refreshed, client.commit(), &diags)
+	assert.Equal(t, prior.ValueString(), applied.BlobSHA)
+	require.False(t, diags.HasError(), diags)
+}
+
+func TestMergeRemoteEditAfterRefresh(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{}
+	private := fakePrivateState{}
+	onConflict := types.StringValue(onConflictMerge)
+	var diags diag.Diagnostics
+
+	// refresh returns the content Read keeps in state.
+	refresh := func() string {
+		applied := getApplied(ctx, private, &diags)
+		return appliedContent(applied, client.commit(), client.content)
+	}
+	apply := func(planned string) string {
+		applied := getApplied(ctx, private, &diags)
+		commit, committed, err := updateFile(ctx, client, git.ValuesModel{
+			Path:    "values.yaml",
+			Content: []byte(planned),
+			SHA:     expectedSHA(onConflict, applied, types.StringValue(client.commit().BlobSHA)),
+		}, onConflict, applied, refresh(), merge.YAML)
+		require.NoError(t, err)
+		setApplied(ctx, private, newAppliedFile(commit, planned, committed), &diags)
+		return committed
+	}
+
+	commit, err := client.Update(ctx, git.ValuesModel{Path: "values.yaml", Content: []byte("a: 1\nb: 1\n")})
+	require.NoError(t, err)
+	setApplied(ctx, private, newAppliedFile(commit, "a: 1\nb: 1\n", "a: 1\nb: 1\n"), &diags)
+	assert.Equal(t, "a: 1\nb: 1\n", refresh())
+
+	// A teammate adds a key after the refresh, and the apply changes another.
+	client.edit("a: 1\nb: 1\nc: 1\n")
+	assert.Equal(t, "a: 1\nb: 1\nc: 1\n", refresh())
+	assert.Equal(t, "a: 2\nb: 1\nc: 1\n", apply("a: 2\nb: 1\n"))
+
+	// The merged file is not drift and the next apply keeps the merged key.
+	assert.Equal(t, "a: 2\nb: 1\n", refresh())
+	assert.Equal(t, "a: 2\nb: 2\nc: 1\n", apply("a: 2\nb: 2\n"))
+
+	// Another remote edit after the merge is merged as well.
+	client.edit("a: 2\nb: 2\nc: 2\n")
+	assert.Equal(t, "a: 3\nb: 2\nc: 2\n", apply("a: 3\nb: 2\n"))
+	require.False(t, diags.HasError(), diags)
+}
@@ -2,6 +2,13 @@
 
 package resource
 
+import "time"
+
 var (
 	defaultBranch = "main"
 )
+
+var (
+	defaultWaitTimeout  = 30 * time.Minute
+	defaultPollInterval = 15 * time.Second
+)
@@ -0,0 +1,143 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/helm"
+	"terraform-provider-gitsync/internal/serialize"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func helmChartAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"chart_path": schema.StringAttribute{
+			MarkdownDescription: "Directory of a Helm chart in the same branch of the repo. " +
+				"The values are merged over the default values of the chart and validated against its `values.schema.json` " +
+				"during plan and before every commit, like Helm does on install. " +
+				"Nothing is validated when the chart has no schema. The schemas of subcharts are not used. Conflicts with `detect_chart`.",
+			Optional: true,
+		},
+		"detect_chart": schema.BoolAttribute{
+			MarkdownDescription: "Validate the values like `chart_path`, with the chart found by walking up from the directory of `path` " +
+				"to the first directory with a `Chart.yaml`. Conflicts with `chart_path`.",
+			Optional: true,
+		},
+	}
+}
+
+func validateHelmChartConfig(chartPath types.String, detectChart types.Bool, diags *diag.Diagnostics) {
+	if !chartPath.IsNull() && detectChart.ValueBool() {
+		diags.AddError(
+			"Invalid Helm chart",
+			"Only one of chart_path and detect_chart can be set",
+		)
+	}
+}
+
+// helmChartKnown reports whether the Helm chart attributes can be used during
+// plan.
+func helmChartKnown(chartPath types.String, detectChart types.Bool) bool {
+	return !chartPath.IsUnknown() && !detectChart.IsUnknown() && (!chartPath.IsNull() || detectChart.ValueBool())
+}
+
+// validateHelmChart validates the values of the first document against the
+// values.schema.json of the chart of chart_path or detect_chart. The values
+// are merged over the values.yaml of the chart first, unless the file is that
+// values.yaml itself.
+func validateHelmChart(
+	ctx context.Context,
+	client git.Client,
+	chartPath types.String,
+	detectChart types.Bool,
+	filePath, branch string,
+	documents []any,
+	diags *diag.Diagnostics,
+) {
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	dir, ok := path.Clean(chartPath.ValueString()), !chartPath.IsNull()
+	if detectChart.ValueBool() {
+		var err error
+		dir, ok, err = findHelmChart(ctx, client, filePath, branch)
+		if err != nil {
+			diags.AddError(
+				"Failed to find Helm chart",
+				fmt.Sprintf("An error occurred while looking for the chart of %q in branch %q: %v", filePath, branch, err),
+			)
+			return
+		}
+	}
+	if !ok {
+		return
+	}
+
+	schemaPath := path.Join(dir, helm.SchemaFile)
+	schema, err := client.GetContent(ctx, schemaPath, branch)
+	if git.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		diags.AddError(
+			"Failed to read JSON schema",
+			fmt.Sprintf("An error occurred while reading %q in branch %q: %v", schemaPath, branch, err),
+		)
+		return
+	}
+
+	var defaults any
+	valuesPath := path.Join(dir, helm.ValuesFile)
+	if valuesPath != path.Clean(filePath) {
+		content, err := client.GetContent(ctx, valuesPath, branch)
+		if err != nil && !git.IsNotFound(err) {
+			diags.AddError(
+				"Failed to read file",
+				fmt.Sprintf("An error occurred while reading %q in branch %q: %v", valuesPath, branch, err),
+			)
+			return
+		}
+		if err == nil {
+			if defaults, err = serialize.ParseYAML(content); err != nil {
+				diags.AddError(
+					"Invalid YAML content",
+					fmt.Sprintf("The default values of the chart in %q are not valid YAML: %v", valuesPath, err),
+				)
+				return
+			}
+		}
+	}
+
+	var values any
+	if len(documents) > 0 {
+		values = documents[0]
+	}
+	err = validators.ValidateJSONSchema(helm.CoalesceValues(defaults, values), schema)
+	addJSONSchemaErrors(diags, err, fmt.Sprintf("The values do not match the schema of the chart in %q", dir))
+}
+
+// findHelmChart returns the closest directory above filePath with a
+// Chart.yaml, or false when there is none.
+func findHelmChart(ctx context.Context, client git.Client, filePath, branch string) (string, bool, error) {
+	for _, dir := range helm.ParentDirs(filePath) {
+		_, err := client.GetContent(ctx, path.Join(dir, helm.ChartFile), branch)
+		if git.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return dir, true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,382 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/jsonpatch"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &JsonPatchResource{}
+var _ resource.ResourceWithValidateConfig = &JsonPatchResource{}
+
+func NewJsonPatchResource() resource.Resource {
+	return &JsonPatchResource{}
+}
+
+type JsonPatchResource struct {
+	client git.Client
+}
+
+type JsonPatchResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	Path         types.String `tfsdk:"path"`
+	Branch       types.String `tfsdk:"branch"`
+	Patch        types.String `tfsdk:"patch"`
+	MergePatch   types.String `tfsdk:"merge_patch"`
+	ReversePatch types.String `tfsdk:"reverse_patch"`
+	Applied      types.Bool   `tfsdk:"applied"`
+
+	CommitModel
+}
+
+func (r *JsonPatchResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_json_patch"
+}
+
+func (r *JsonPatchResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Applies a JSON Patch (RFC 6902) or a JSON Merge Patch (RFC 7386) to an existing json file in a Git repository. " +
+			"The rest of the file keeps its content, key order and indentation, and the patch is undone on destroy.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Relative path of the file in the repo.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch to commit to. Defaults to the main branch.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultBranch),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"patch": schema.StringAttribute{
+				MarkdownDescription: "JSON Patch document, a list of operations. Exactly one of `patch` and `merge_patch` must be set.",
+				Optional:            true,
+			},
+			"merge_patch": schema.StringAttribute{
+				MarkdownDescription: "JSON Merge Patch document. Exactly one of `patch` and `merge_patch` must be set.",
+				Optional:            true,
+			},
+			"reverse_patch": schema.StringAttribute{
+				MarkdownDescription: "Patch of the same kind that restores the file as it was before the patch was applied. It is applied on destroy.",
+				Computed:            true,
+			},
+			"applied": schema.BoolAttribute{
+				MarkdownDescription: "Whether the changes of the patch are present in the remote file. " +
+					"It is false when the file was changed outside of Terraform, which plans to apply the patch again.",
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+		},
+	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
+}
+
+func (r *JsonPatchResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *JsonPatchResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data JsonPatchResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Patch.IsNull() == data.MergePatch.IsNull() {
+		resp.Diagnostics.AddError(
+			"Invalid patch",
+			"Exactly one of patch and merge_patch must be set",
+		)
+	}
+}
+
+func (r *JsonPatchResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data JsonPatchResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateJsonPatchModel(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	commit, err := r.patch(ctx, &data, func(doc *jsonpatch.Document) error {
+		reverse, err := applyJsonPatch(doc, &data)
+		data.ReversePatch = types.StringValue(reverse)
+		return err
+	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to apply patch",
+			fmt.Sprintf(
+				"An error occurred while applying the patch to %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.Applied = types.BoolValue(true)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JsonPatchResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data JsonPatchResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	doc, commit, err := r.load(ctx, &data)
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	var applied bool
+	if !data.MergePatch.IsNull() {
+		applied, err = jsonpatch.MergePatchApplied(doc, data.MergePatch.ValueString())
+	} else {
+		applied, err = jsonpatch.PatchApplied(doc, data.Patch.ValueString())
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while checking the patch of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.Applied = types.BoolValue(applied)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JsonPatchResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state JsonPatchResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateJsonPatchModel(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// When only the remote file drifted, the patch is applied again and the
+	// reverse patch to the original content is kept.
+	changed := !data.Patch.Equal(state.Patch) || !data.MergePatch.Equal(state.MergePatch)
+	commit, err := r.patch(ctx, &data, func(doc *jsonpatch.Document) error {
+		if !changed {
+			_, err := applyJsonPatch(doc, &data)
+			data.ReversePatch = state.ReversePatch
+			return err
+		}
+
+		if err := revertJsonPatch(doc, &state); err != nil {
+			return fmt.Errorf("failed to revert the previous patch: %w", err)
+		}
+		reverse, err := applyJsonPatch(doc, &data)
+		data.ReversePatch = types.StringValue(reverse)
+		return err
+	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to apply patch",
+			fmt.Sprintf(
+				"An error occurred while applying the patch to %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.Applied = types.BoolValue(true)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *JsonPatchResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data JsonPatchResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.patch(ctx, &data, func(doc *jsonpatch.Document) error {
+		return revertJsonPatch(doc, &data)
+	})
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to revert patch",
+			fmt.Sprintf(
+				"An error occurred while reverting the patch of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+// load reads the file together with its last commit. The commit is read
+// first, so a change in between fails the write instead of being overwritten.
+func (r *JsonPatchResource) load(ctx context.Context, data *JsonPatchResourceModel) (*jsonpatch.Document, *git.Commit, error) {
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := jsonpatch.Parse(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, commit, nil
+}
+
+// patch edits the remote file and commits the result. Nothing is committed
+// when the content did not change, and the edit is done again when the file
+// changed concurrently.
+func (r *JsonPatchResource) patch(ctx context.Context, data *JsonPatchResourceModel, edit func(*jsonpatch.Document) error) (*git.Commit, error) {
+	operation := func() (*git.Commit, error) {
+		doc, commit, err := r.load(ctx, data)
+		if err != nil {
+			return nil, backoff.Permanent(err)
+		}
+
+		before := doc.String()
+		if err := edit(doc); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		if doc.String() == before {
+			return commit, nil
+		}
+		if err := validators.ValidateJSON(doc.String()); err != nil {
+			return nil, backoff.Permanent(fmt.Errorf("the patched content is not valid JSON: %w", err))
+		}
+
+		commit, err = r.client.Update(ctx, git.ValuesModel{
+			Path:    data.Path.ValueString(),
+			Branch:  data.Branch.ValueString(),
+			Content: []byte(doc.String()),
+			SHA:     commit.BlobSHA,
+		})
+		var conflict *git.ConflictError
+		if err != nil && !errors.As(err, &conflict) {
+			return nil, backoff.Permanent(err)
+		}
+		return commit, err
+	}
+
+	return backoff.Retry(ctx, operation, backoff.WithMaxTries(5))
+}
+
+// applyJsonPatch applies the patch of the model and returns its reverse.
+func applyJsonPatch(doc *jsonpatch.Document, data *JsonPatchResourceModel) (string, error) {
+	if !data.MergePatch.IsNull() {
+		return jsonpatch.ApplyMergePatch(doc, data.MergePatch.ValueString())
+	}
+	return jsonpatch.ApplyPatch(doc, data.Patch.ValueString())
+}
+
+// revertJsonPatch applies the reverse patch recorded in the model.
+func revertJsonPatch(doc *jsonpatch.Document, data *JsonPatchResourceModel) error {
+	var err error
+	if !data.MergePatch.IsNull() {
+		_, err = jsonpatch.ApplyMergePatch(doc, data.ReversePatch.ValueString())
+	} else {
+		_, err = jsonpatch.ApplyPatch(doc, data.ReversePatch.ValueString())
+	}
+	return err
+}
+
+func validateJsonPatchModel(data *JsonPatchResourceModel, diags *diag.Diagnostics) {
+	ext := filepath.Ext(data.Path.ValueString())
+	if ext != ".json" {
+		diags.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The file extension %q is not valid, must be .json", ext),
+		)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func jsonSchemaAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"schema": schema.StringAttribute{
+			MarkdownDescription: "JSON Schema to validate the content against during plan and before every commit. " +
+				"Drafts 7 and 2020-12 are supported, schemas without `$schema` are read as draft 2020-12. " +
+				"References to other documents are not resolved. Conflicts with `schema_path`.",
+			Optional: true,
+		},
+		"schema_path": schema.StringAttribute{
+			MarkdownDescription: "Path of a JSON Schema in the same branch of the repo to validate the content against, like `schema`. Conflicts with `schema`.",
+			Optional:            true,
+		},
+	}
+}
+
+func validateJSONSchemaConfig(schema, schemaPath types.String, diags *diag.Diagnostics) {
+	if !schema.IsNull() && !schemaPath.IsNull() {
+		diags.AddError(
+			"Invalid schema",
+			"Only one of schema and schema_path can be set",
+		)
+	}
+}
+
+// jsonSchemaKnown reports whether the schema attributes can be used during
+// plan.
+func jsonSchemaKnown(schema, schemaPath types.String) bool {
+	return !schema.IsUnknown() && !schemaPath.IsUnknown() && (!schema.IsNull() || !schemaPath.IsNull())
+}
+
+// validateJSONSchema validates decoded documents against the JSON Schema of
+// schema or schema_path. Every value that does not match is reported as its
+// own diagnostic. During plan, a schema_path that does not exist yet is not
+// an error, since it may be created in the same apply. The main branch is
+// used when branch is empty.
+func validateJSONSchema(
+	ctx context.Context,
+	client git.Client,
+	schema, schemaPath types.String,
+	branch string,
+	documents []any,
+	plan bool,
+	diags *diag.Diagnostics,
+) {
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	content := schema.ValueString()
+	if !schemaPath.IsNull() {
+		var err error
+		content, err = client.GetContent(ctx, schemaPath.ValueString(), branch)
+		if plan && git.IsNotFound(err) {
+			return
+		}
+		if err != nil {
+			diags.AddError(
+				"Failed to read JSON schema",
+				fmt.Sprintf(
+					"An error occurred while reading %q in branch %q: %v",
+					schemaPath.ValueString(),
+					branch,
+					err,
+				),
+			)
+			return
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	for i, document := range documents {
+		err := validators.ValidateJSONSchema(document, content)
+		if len(documents) > 1 {
+			addJSONSchemaErrors(diags, err, fmt.Sprintf("Document %d does not match the JSON schema", i+1))
+		} else {
+			addJSONSchemaErrors(diags, err, "The content does not match the JSON schema")
+		}
+		if diags.HasError() {
+			return
+		}
+	}
+}
+
+// addJSONSchemaErrors reports every value of err that does not match a JSON
+// schema as its own diagnostic, starting with detail.
+func addJSONSchemaErrors(diags *diag.Diagnostics, err error, detail string) {
+	if err == nil {
+		return
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, err := range errs {
+		var validationErr *validators.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Type == "json schema" {
+			diags.AddError(
+				"Invalid JSON schema",
+				fmt.Sprintf("The JSON schema cannot be used: %v", err),
+			)
+			return
+		}
+		diags.AddError(
+			"Invalid content",
+			fmt.Sprintf("%s: %v", detail, err),
+		)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"terraform-provider-gitsync/internal/kubernetes"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type KubernetesValidationModel struct {
+	Version              types.String `tfsdk:"version"`
+	CRDFiles             types.List   `tfsdk:"crd_files"`
+	IgnoreMissingSchemas types.Bool   `tfsdk:"ignore_missing_schemas"`
+}
+
+func kubernetesValidationAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"version":                types.StringType,
+		"crd_files":              types.ListType{ElemType: types.StringType},
+		"ignore_missing_schemas": types.BoolType,
+	}
+}
+
+func kubernetesValidationBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Validate every document as a Kubernetes manifest during plan and before every commit, offline and like kubeconform in strict mode. " +
+			"Unknown fields are an error and null is accepted for every field.",
+		Attributes: map[string]schema.Attribute{
+			"version": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf(
+					"Kubernetes version whose bundled OpenAPI schemas are used, one of %s. Defaults to the newest one.",
+					"`"+strings.Join(kubernetes.Versions(), "`, `")+"`",
+				),
+				Optional: true,
+			},
+			"crd_files": schema.ListAttribute{
+				MarkdownDescription: "Local YAML files with `apiextensions.k8s.io/v1` CustomResourceDefinitions, whose schemas are used for custom resources. " +
+					"Other documents in the files are ignored.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"ignore_missing_schemas": schema.BoolAttribute{
+				MarkdownDescription: "Skip documents of kinds without a schema instead of failing. Defaults to `false`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// kubernetesValidationKnown reports whether the kubernetes_validation block
+// is set and can be used during plan.
+func kubernetesValidationKnown(ctx context.Context, block types.Object) bool {
+	if block.IsNull() || block.IsUnknown() {
+		return false
+	}
+	var cfg KubernetesValidationModel
+	if diags := block.As(ctx, &cfg, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return false
+	}
+	if cfg.Version.IsUnknown() || cfg.CRDFiles.IsUnknown() || cfg.IgnoreMissingSchemas.IsUnknown() {
+		return false
+	}
+	for _, file := range cfg.CRDFiles.Elements() {
+		if file.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// validateKubernetesManifests validates every decoded document against the
+// Kubernetes schemas of the kubernetes_validation block. It is a no-op when
+// the block is not set.
+func validateKubernetesManifests(ctx context.Context, block types.Object, documents []any, diags *diag.Diagnostics) {
+	if block.IsNull() || block.IsUnknown() {
+		return
+	}
+
+	var cfg KubernetesValidationModel
+	if d := block.As(ctx, &cfg, basetypes.ObjectAsOptions{}); d.HasError() {
+		diags.Append(d...)
+		return
+	}
+
+	var files []string
+	if d := cfg.CRDFiles.ElementsAs(ctx, &files, false); d.HasError() {
+		diags.Append(d...)
+		return
+	}
+	var crds []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			diags.AddError(
+				"Failed to read CRD file",
+				fmt.Sprintf("An error occurred while reading %q: %v", file, err),
+			)
+			return
+		}
+		crds = append(crds, string(content))
+	}
+
+	version := cfg.Version.ValueString()
+	if version == "" {
+		version = kubernetes.LatestVersion()
+	}
+	schemas, err := kubernetes.Load(version, crds...)
+	if err != nil {
+		diags.AddError(
+			"Invalid kubernetes_validation",
+			fmt.Sprintf("The Kubernetes schemas cannot be loaded: %v", err),
+		)
+		return
+	}
+
+	for i, document := range documents {
+		err := validators.ValidateKubernetes(document, schemas, cfg.IgnoreMissingSchemas.ValueBool())
+		addJSONSchemaErrors(diags, err, fmt.Sprintf("Document %d is not a valid Kubernetes manifest", i+1))
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+)
+
+// requiresReplaceUnlessImported forces a replacement when the value changes,
+// except when the state has no value yet, which is the case for attributes
+// that cannot be recovered on import.
+func requiresReplaceUnlessImported() planmodifier.String {
+	description := "Changing the value forces a replacement, unless the value was not known on import."
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace = !req.StateValue.IsNull()
+		},
+		description,
+		description,
+	)
+}
@@ -0,0 +1,134 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-gitsync/internal/policy"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+type PolicyModel struct {
+	Files     types.List   `tfsdk:"files"`
+	Namespace types.String `tfsdk:"namespace"`
+}
+
+func policyAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"files":     types.ListType{ElemType: types.StringType},
+		"namespace": types.StringType,
+	}
+}
+
+func policyBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Evaluate Rego policies against every parsed document during plan and before every commit, in addition to the `policy` of the provider. " +
+			"Messages of `deny` rules fail the plan, messages of `warn` rules are shown as warnings.",
+		Attributes: map[string]schema.Attribute{
+			"files": schema.ListAttribute{
+				MarkdownDescription: "Local Rego files, written in Rego v1 syntax.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"namespace": schema.StringAttribute{
+				MarkdownDescription: "Rego package of the `deny` and `warn` rules. Defaults to `main`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// policyKnown reports whether the policy block is set and can be used during
+// plan.
+func policyKnown(ctx context.Context, block types.Object) bool {
+	if block.IsNull() || block.IsUnknown() {
+		return false
+	}
+	var cfg PolicyModel
+	if diags := block.As(ctx, &cfg, basetypes.ObjectAsOptions{}); diags.HasError() {
+		return false
+	}
+	if cfg.Files.IsUnknown() || cfg.Namespace.IsUnknown() {
+		return false
+	}
+	for _, file := range cfg.Files.Elements() {
+		if file.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluatePolicies evaluates the policy of the provider and the policy block
+// against every decoded document. Deny messages are errors. Warn messages are
+// only reported during plan, so they are not shown twice.
+func evaluatePolicies(
+	ctx context.Context,
+	providerPolicy *policy.Policy,
+	block types.Object,
+	documents []any,
+	plan bool,
+	diags *diag.Diagnostics,
+) {
+	var policies []*policy.Policy
+	if providerPolicy != nil {
+		policies = append(policies, providerPolicy)
+	}
+
+	// Blocks with values unknown during plan are evaluated on apply.
+	if policyKnown(ctx, block) {
+		var cfg PolicyModel
+		if d := block.As(ctx, &cfg, basetypes.ObjectAsOptions{}); d.HasError() {
+			diags.Append(d...)
+			return
+		}
+		var files []string
+		if d := cfg.Files.ElementsAs(ctx, &files, false); d.HasError() {
+			diags.Append(d...)
+			return
+		}
+		p, err := policy.LoadFiles(ctx, cfg.Namespace.ValueString(), files)
+		if err != nil {
+			diags.AddError(
+				"Invalid policy",
+				fmt.Sprintf("The policy cannot be loaded: %v", err),
+			)
+			return
+		}
+		policies = append(policies, p)
+	}
+
+	for _, p := range policies {
+		for i, document := range documents {
+			result, err := p.Eval(ctx, document)
+			if err != nil {
+				diags.AddError(
+					"Failed to evaluate policy",
+					fmt.Sprintf("An error occurred while evaluating the policy: %v", err),
+				)
+				return
+			}
+
+			prefix := ""
+			if len(documents) > 1 {
+				prefix = fmt.Sprintf("Document %d: ", i+1)
+			}
+			for _, msg := range result.Denials {
+				diags.AddError("Policy violation", prefix+msg)
+			}
+			if plan {
+				for _, msg := range result.Warnings {
+					diags.AddWarning("Policy warning", prefix+msg)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/policy"
+)
+
+// ProviderData is passed from the provider to the resources. Resources take
+// the client they need from its fields instead of asserting it on the
+// provider data itself.
+type ProviderData struct {
+	Client git.Client
+
+	// PullRequests and Releases are nil when the backend of Client does not
+	// manage pull requests or releases.
+	PullRequests git.PullRequestClient
+	Releases     git.ReleaseClient
+
+	// Policy is the policy of the provider, applied to every file that is
+	// validated against policies, or nil.
+	Policy *policy.Policy
+}
+
+// NewProviderData returns the provider data for client, with the pull request
+// and release clients set when client implements them.
+func NewProviderData(client git.Client, pol *policy.Policy) *ProviderData {
+	data := &ProviderData{Client: client, Policy: pol}
+	if c, ok := client.(git.PullRequestClient); ok {
+		data.PullRequests = c
+	}
+	if c, ok := client.(git.ReleaseClient); ok {
+		data.Releases = c
+	}
+	return data
+}
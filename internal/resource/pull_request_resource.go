@@ -0,0 +1,520 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &PullRequestResource{}
+var _ resource.ResourceWithImportState = &PullRequestResource{}
+
+const (
+	waitForMerged   = "merged"
+	waitForClosed   = "closed"
+	waitForApproved = "approved"
+)
+
+func NewPullRequestResource() resource.Resource {
+	return &PullRequestResource{}
+}
+
+type PullRequestResource struct {
+	client git.PullRequestClient
+}
+
+type PullRequestResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Number         types.Int64  `tfsdk:"number"`
+	URL            types.String `tfsdk:"url"`
+	State          types.String `tfsdk:"state"`
+	Approved       types.Bool   `tfsdk:"approved"`
+	MergeCommitSHA types.String `tfsdk:"merge_commit_sha"`
+	Title          types.String `tfsdk:"title"`
+	Body           types.String `tfsdk:"body"`
+	HeadBranch     types.String `tfsdk:"head_branch"`
+	BaseBranch     types.String `tfsdk:"base_branch"`
+	Labels         types.Set    `tfsdk:"labels"`
+	Assignees      types.Set    `tfsdk:"assignees"`
+	Reviewers      types.Set    `tfsdk:"reviewers"`
+	AutoMerge      types.Bool   `tfsdk:"auto_merge"`
+	MergeMethod    types.String `tfsdk:"merge_method"`
+	WaitFor        types.String `tfsdk:"wait_for"`
+	WaitTimeout    types.String `tfsdk:"wait_timeout"`
+}
+
+func (r *PullRequestResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pull_request"
+}
+
+func (r *PullRequestResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a pull request (GitHub) or merge request (GitLab) in a Git repository.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"number": schema.Int64Attribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Pull request number (GitHub) or merge request IID (GitLab).",
+			},
+			"url": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Web URL of the pull request.",
+			},
+			"state": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "State of the pull request, one of `open`, `closed` or `merged`.",
+			},
+			"approved": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the pull request has been approved.",
+			},
+			"merge_commit_sha": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA of the merge commit once the pull request is merged.",
+			},
+			"title": schema.StringAttribute{
+				MarkdownDescription: "Title of the pull request.",
+				Required:            true,
+			},
+			"body": schema.StringAttribute{
+				MarkdownDescription: "Description of the pull request.",
+				Optional:            true,
+			},
+			"head_branch": schema.StringAttribute{
+				MarkdownDescription: "Branch containing the changes.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"base_branch": schema.StringAttribute{
+				MarkdownDescription: "Branch the changes are merged into. Defaults to the main branch.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultBranch),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"labels": schema.SetAttribute{
+				MarkdownDescription: "Labels to set on the pull request.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"assignees": schema.SetAttribute{
+				MarkdownDescription: "Usernames of the assignees.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"reviewers": schema.SetAttribute{
+				MarkdownDescription: "Usernames of the requested reviewers. Reviewers are only requested and are not refreshed from the remote, as GitHub removes them from the pull request once they have submitted a review.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"auto_merge": schema.BoolAttribute{
+				MarkdownDescription: "Enable auto-merge (GitHub) or merge when pipeline succeeds (GitLab).",
+				Optional:            true,
+			},
+			"merge_method": schema.StringAttribute{
+				MarkdownDescription: "Merge method used by auto-merge, one of `merge`, `squash` or `rebase`. GitLab only supports `merge` and `squash`, the rest is configured on the project. Defaults to `merge`.",
+				Optional:            true,
+			},
+			"wait_for": schema.StringAttribute{
+				MarkdownDescription: "Block the apply until the pull request is `merged`, `closed` or `approved`.",
+				Optional:            true,
+			},
+			"wait_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the state set in `wait_for`, as a Go duration string. Defaults to `30m`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (r *PullRequestResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	if data.PullRequests == nil {
+		resp.Diagnostics.AddError(
+			"Unsupported Git Provider",
+			"The Git provider of the repository does not support pull requests.",
+		)
+		return
+	}
+	r.client = data.PullRequests
+}
+
+func (r *PullRequestResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data PullRequestResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := validatePullRequestModel(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	model := pullRequestModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pr, err := r.client.CreatePullRequest(ctx, model)
+	if err != nil && pr != nil {
+		// The pull request was opened, so it is saved to not open another one
+		// on the next apply.
+		data.ID = types.StringValue(strconv.Itoa(pr.Number))
+		setPullRequestComputed(&data, pr)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		resp.Diagnostics.AddError(
+			"Failed to update pull request",
+			fmt.Sprintf("Pull request %s was opened, but an error occurred while setting its labels, assignees or reviewers: %v", pr.URL, err),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create pull request",
+			fmt.Sprintf(
+				"An error occurred while creating pull request from %q to %q: %v",
+				model.HeadBranch,
+				model.BaseBranch,
+				err,
+			),
+		)
+		return
+	}
+
+	// Save the pull request right away, so a failure below does not leave an
+	// orphaned pull request behind.
+	data.ID = types.StringValue(strconv.Itoa(pr.Number))
+	setPullRequestComputed(&data, pr)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.AutoMerge.ValueBool() {
+		if err := r.client.EnableAutoMerge(ctx, pr.Number, data.MergeMethod.ValueString()); err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to enable auto-merge",
+				fmt.Sprintf("An error occurred while enabling auto-merge for %s: %v", pr.URL, err),
+			)
+			return
+		}
+	}
+
+	pr, err = r.wait(ctx, pr, data.WaitFor.ValueString(), timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for pull request",
+			fmt.Sprintf("An error occurred while waiting for %s to be %s: %v", data.URL.ValueString(), data.WaitFor.ValueString(), err),
+		)
+		return
+	}
+
+	setPullRequestComputed(&data, pr)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PullRequestResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data PullRequestResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	pr, err := r.client.GetPullRequest(ctx, int(data.Number.ValueInt64()))
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read pull request",
+			fmt.Sprintf("An error occurred while reading pull request %d: %v", data.Number.ValueInt64(), err),
+		)
+		return
+	}
+
+	setPullRequestRemote(&data, pr)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PullRequestResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state PullRequestResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := validatePullRequestModel(&data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	model := pullRequestModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	number := int(state.Number.ValueInt64())
+	pr, err := r.client.UpdatePullRequest(ctx, number, model)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update pull request",
+			fmt.Sprintf("An error occurred while updating pull request %d: %v", number, err),
+		)
+		return
+	}
+
+	if pr.State == git.PullRequestStateOpen && data.AutoMerge.ValueBool() != state.AutoMerge.ValueBool() {
+		if data.AutoMerge.ValueBool() {
+			err = r.client.EnableAutoMerge(ctx, number, data.MergeMethod.ValueString())
+		} else {
+			err = r.client.DisableAutoMerge(ctx, number)
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to update auto-merge",
+				fmt.Sprintf("An error occurred while updating auto-merge for %s: %v", pr.URL, err),
+			)
+			return
+		}
+	}
+
+	pr, err = r.wait(ctx, pr, data.WaitFor.ValueString(), timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for pull request",
+			fmt.Sprintf("An error occurred while waiting for %s to be %s: %v", data.URL.ValueString(), data.WaitFor.ValueString(), err),
+		)
+		return
+	}
+
+	setPullRequestComputed(&data, pr)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PullRequestResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data PullRequestResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	number := int(data.Number.ValueInt64())
+	pr, err := r.client.GetPullRequest(ctx, number)
+	if git.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read pull request",
+			fmt.Sprintf("An error occurred while reading pull request %d: %v", number, err),
+		)
+		return
+	}
+
+	// Merged and closed pull requests cannot be removed, they are only
+	// dropped from the state.
+	if pr.State != git.PullRequestStateOpen {
+		return
+	}
+
+	if err := r.client.ClosePullRequest(ctx, number); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to close pull request",
+			fmt.Sprintf("An error occurred while closing pull request %d: %v", number, err),
+		)
+		return
+	}
+}
+
+func (r *PullRequestResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	number, err := strconv.Atoi(req.ID)
+	if err != nil || number <= 0 {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be the pull request number",
+		)
+		return
+	}
+
+	pr, err := r.client.GetPullRequest(ctx, number)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read pull request during import",
+			fmt.Sprintf("An error occurred while reading pull request %d: %v", number, err),
+		)
+		return
+	}
+
+	data := PullRequestResourceModel{
+		ID:          types.StringValue(strconv.Itoa(pr.Number)),
+		Labels:      types.SetNull(types.StringType),
+		Assignees:   types.SetNull(types.StringType),
+		Reviewers:   types.SetNull(types.StringType),
+		AutoMerge:   types.BoolNull(),
+		MergeMethod: types.StringNull(),
+		WaitFor:     types.StringNull(),
+		WaitTimeout: types.StringNull(),
+	}
+	if pr.Body != "" {
+		data.Body = types.StringValue(pr.Body)
+	}
+	setPullRequestRemote(&data, pr)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *PullRequestResource) wait(ctx context.Context, pr *git.PullRequest, target string, timeout time.Duration) (*git.PullRequest, error) {
+	if target == "" {
+		return pr, nil
+	}
+
+	operation := func() (*git.PullRequest, error) {
+		current, err := r.client.GetPullRequest(ctx, pr.Number)
+		if err != nil {
+			return pr, backoff.Permanent(err)
+		}
+
+		switch {
+		case current.State == git.PullRequestStateMerged:
+			return current, nil
+		case target == waitForClosed && current.State == git.PullRequestStateClosed:
+			return current, nil
+		case target == waitForApproved && current.Approved:
+			return current, nil
+		case current.State == git.PullRequestStateClosed:
+			return current, backoff.Permanent(errors.New("pull request was closed without being merged"))
+		}
+
+		return current, fmt.Errorf("pull request is still %s", current.State)
+	}
+
+	return backoff.Retry(
+		ctx,
+		operation,
+		backoff.WithBackOff(backoff.NewConstantBackOff(defaultPollInterval)),
+		backoff.WithMaxElapsedTime(timeout),
+	)
+}
+
+func validatePullRequestModel(data *PullRequestResourceModel, diags *diag.Diagnostics) time.Duration {
+	mergeMethods := []string{git.MergeMethodMerge, git.MergeMethodSquash, git.MergeMethodRebase}
+	if !data.MergeMethod.IsNull() && !slices.Contains(mergeMethods, data.MergeMethod.ValueString()) {
+		diags.AddError(
+			"Invalid merge method",
+			fmt.Sprintf("The merge method %q is not valid, must be one of %q", data.MergeMethod.ValueString(), mergeMethods),
+		)
+	}
+
+	waitFor := []string{waitForMerged, waitForClosed, waitForApproved}
+	if !data.WaitFor.IsNull() && !slices.Contains(waitFor, data.WaitFor.ValueString()) {
+		diags.AddError(
+			"Invalid wait_for value",
+			fmt.Sprintf("The value %q is not valid, must be one of %q", data.WaitFor.ValueString(), waitFor),
+		)
+	}
+
+	if data.WaitTimeout.IsNull() || data.WaitTimeout.ValueString() == "" {
+		return defaultWaitTimeout
+	}
+
+	timeout, err := time.ParseDuration(data.WaitTimeout.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Invalid wait timeout",
+			fmt.Sprintf("The value %q is not a valid duration: %v", data.WaitTimeout.ValueString(), err),
+		)
+	}
+
+	return timeout
+}
+
+func pullRequestModel(ctx context.Context, data *PullRequestResourceModel, diags *diag.Diagnostics) git.PullRequestModel {
+	model := git.PullRequestModel{
+		Title:      data.Title.ValueString(),
+		Body:       data.Body.ValueString(),
+		HeadBranch: data.HeadBranch.ValueString(),
+		BaseBranch: data.BaseBranch.ValueString(),
+	}
+
+	diags.Append(data.Labels.ElementsAs(ctx, &model.Labels, false)...)
+	diags.Append(data.Assignees.ElementsAs(ctx, &model.Assignees, false)...)
+	diags.Append(data.Reviewers.ElementsAs(ctx, &model.Reviewers, false)...)
+
+	return model
+}
+
+func setPullRequestComputed(data *PullRequestResourceModel, pr *git.PullRequest) {
+	data.Number = types.Int64Value(int64(pr.Number))
+	data.URL = types.StringValue(pr.URL)
+	data.State = types.StringValue(pr.State)
+	data.Approved = types.BoolValue(pr.Approved)
+	data.MergeCommitSHA = types.StringValue(pr.MergeCommitSHA)
+}
+
+func setPullRequestRemote(data *PullRequestResourceModel, pr *git.PullRequest) {
+	setPullRequestComputed(data, pr)
+	data.Title = types.StringValue(pr.Title)
+	if !data.Body.IsNull() || pr.Body != "" {
+		data.Body = types.StringValue(pr.Body)
+	}
+	data.HeadBranch = types.StringValue(pr.HeadBranch)
+	data.BaseBranch = types.StringValue(pr.BaseBranch)
+	data.Labels = stringSetValue(data.Labels, pr.Labels)
+	data.Assignees = stringSetValue(data.Assignees, pr.Assignees)
+}
+
+// stringSetValue keeps an unset attribute null when the remote has no values,
+// so an omitted list does not show up as a diff against an empty one.
+func stringSetValue(current types.Set, values []string) types.Set {
+	if current.IsNull() && len(values) == 0 {
+		return current
+	}
+
+	elements := make([]attr.Value, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, types.StringValue(v))
+	}
+
+	return types.SetValueMust(types.StringType, elements)
+}
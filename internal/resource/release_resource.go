@@ -0,0 +1,432 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &ReleaseResource{}
+var _ resource.ResourceWithImportState = &ReleaseResource{}
+var _ resource.ResourceWithModifyPlan = &ReleaseResource{}
+
+func NewReleaseResource() resource.Resource {
+	return &ReleaseResource{}
+}
+
+type ReleaseResource struct {
+	client git.ReleaseClient
+}
+
+type ReleaseResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Tag       types.String `tfsdk:"tag"`
+	Name      types.String `tfsdk:"name"`
+	Notes     types.String `tfsdk:"notes"`
+	Assets    types.List   `tfsdk:"assets"`
+	URL       types.String `tfsdk:"url"`
+	AssetURLs types.Map    `tfsdk:"asset_urls"`
+}
+
+type ReleaseAssetModel struct {
+	Name   types.String `tfsdk:"name"`
+	Path   types.String `tfsdk:"path"`
+	SHA256 types.String `tfsdk:"sha256"`
+}
+
+func (r *ReleaseResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_release"
+}
+
+func (r *ReleaseResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a GitHub or GitLab release for an existing tag. The tag itself is kept on destroy.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"tag": schema.StringAttribute{
+				MarkdownDescription: "Tag the release is created for.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Title of the release.",
+				Optional:            true,
+			},
+			"notes": schema.StringAttribute{
+				MarkdownDescription: "Release notes.",
+				Optional:            true,
+			},
+			"assets": schema.ListNestedAttribute{
+				MarkdownDescription: "Local files uploaded as release assets. All assets are uploaded again whenever the list or the content of one of the files changes.",
+				Optional:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "File name of the asset in the release.",
+							Required:            true,
+						},
+						"path": schema.StringAttribute{
+							MarkdownDescription: "Path of the local file to upload.",
+							Required:            true,
+						},
+						"sha256": schema.StringAttribute{
+							MarkdownDescription: "SHA-256 checksum of the local file, unknown until apply when the file does not exist during plan.",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"url": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Web URL of the release.",
+			},
+			"asset_urls": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Download URLs of the release assets, keyed by asset name.",
+			},
+		},
+	}
+}
+
+func (r *ReleaseResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	if data.Releases == nil {
+		resp.Diagnostics.AddError(
+			"Unsupported Git Provider",
+			"The Git provider of the repository does not support releases.",
+		)
+		return
+	}
+	r.client = data.Releases
+}
+
+// ModifyPlan sets the checksums of the asset files, so a file that changed at
+// the same path is uploaded again.
+func (r *ReleaseResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var assets types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("assets"), &assets)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	assets = hashReleaseAssets(ctx, assets, false, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("assets"), assets)...)
+}
+
+func (r *ReleaseResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ReleaseResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Assets = hashReleaseAssets(ctx, data.Assets, true, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	model := releaseModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	release, err := r.client.CreateRelease(ctx, model)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create release",
+			fmt.Sprintf(
+				"An error occurred while creating release for tag %q: %v",
+				model.Tag,
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(release.Tag)
+	setReleaseComputed(&data, release)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ReleaseResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ReleaseResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	release, err := r.client.GetRelease(ctx, data.Tag.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read release",
+			fmt.Sprintf(
+				"An error occurred while reading release for tag %q: %v",
+				data.Tag.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	setReleaseRemote(&data, release)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ReleaseResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ReleaseResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	model := releaseModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Assets are only replaced when they or the checksums of their files
+	// changed, a nil list keeps the uploaded ones as they are. Checksums of
+	// files created during apply are unknown in the plan, so they are always
+	// uploaded.
+	if data.Assets.Equal(state.Assets) {
+		model.Assets = nil
+	} else if model.Assets == nil {
+		model.Assets = []git.ReleaseAsset{}
+	}
+	data.Assets = hashReleaseAssets(ctx, data.Assets, true, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	release, err := r.client.UpdateRelease(ctx, model)
+	if err != nil {
+		// The old assets may already be deleted when an upload fails, so the
+		// assets are dropped from state to be uploaded again by the next apply.
+		if model.Assets != nil {
+			state.Assets = types.ListValueMust(releaseAssetType(), []attr.Value{})
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		}
+		resp.Diagnostics.AddError(
+			"Failed to update release",
+			fmt.Sprintf(
+				"An error occurred while updating release for tag %q: %v",
+				model.Tag,
+				err,
+			),
+		)
+		return
+	}
+
+	setReleaseComputed(&data, release)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ReleaseResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ReleaseResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteRelease(ctx, data.Tag.ValueString())
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete release",
+			fmt.Sprintf(
+				"An error occurred while deleting release for tag %q: %v",
+				data.Tag.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *ReleaseResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be the tag of the release",
+		)
+		return
+	}
+
+	release, err := r.client.GetRelease(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read release during import",
+			fmt.Sprintf(
+				"An error occurred while reading release for tag %q: %v",
+				req.ID,
+				err,
+			),
+		)
+		return
+	}
+
+	data := ReleaseResourceModel{
+		ID:     types.StringValue(release.Tag),
+		Tag:    types.StringValue(release.Tag),
+		Assets: types.ListNull(releaseAssetType()),
+	}
+	if release.Name != "" && release.Name != release.Tag {
+		data.Name = types.StringValue(release.Name)
+	}
+	if release.Notes != "" {
+		data.Notes = types.StringValue(release.Notes)
+	}
+	setReleaseComputed(&data, release)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func releaseAssetType() types.ObjectType {
+	return types.ObjectType{AttrTypes: map[string]attr.Type{
+		"name":   types.StringType,
+		"path":   types.StringType,
+		"sha256": types.StringType,
+	}}
+}
+
+// hashReleaseAssets sets the SHA-256 checksums of the asset files. During plan,
+// checksums of files that cannot be read yet, like files built during apply,
+// are unknown. On apply, known checksums are kept and missing ones required.
+func hashReleaseAssets(ctx context.Context, list types.List, apply bool, diags *diag.Diagnostics) types.List {
+	if list.IsNull() || list.IsUnknown() {
+		return list
+	}
+	for _, element := range list.Elements() {
+		if element.IsUnknown() {
+			return list
+		}
+	}
+
+	var assets []ReleaseAssetModel
+	diags.Append(list.ElementsAs(ctx, &assets, false)...)
+	if diags.HasError() {
+		return list
+	}
+
+	for i, a := range assets {
+		if a.Path.IsUnknown() || (apply && !a.SHA256.IsUnknown() && !a.SHA256.IsNull()) {
+			continue
+		}
+
+		sum, err := fileSHA256(a.Path.ValueString())
+		switch {
+		case err == nil:
+			assets[i].SHA256 = types.StringValue(sum)
+		case apply:
+			diags.AddError(
+				"Failed to read release asset",
+				fmt.Sprintf("The asset %q cannot be read: %v", a.Name.ValueString(), err),
+			)
+			return list
+		default:
+			assets[i].SHA256 = types.StringUnknown()
+		}
+	}
+
+	hashed, d := types.ListValueFrom(ctx, releaseAssetType(), assets)
+	diags.Append(d...)
+	return hashed
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func releaseModel(ctx context.Context, data *ReleaseResourceModel, diags *diag.Diagnostics) git.ReleaseModel {
+	model := git.ReleaseModel{
+		Tag:   data.Tag.ValueString(),
+		Name:  data.Name.ValueString(),
+		Notes: data.Notes.ValueString(),
+	}
+
+	var assets []ReleaseAssetModel
+	diags.Append(data.Assets.ElementsAs(ctx, &assets, false)...)
+	for _, a := range assets {
+		model.Assets = append(model.Assets, git.ReleaseAsset{
+			Name: a.Name.ValueString(),
+			Path: a.Path.ValueString(),
+		})
+	}
+
+	return model
+}
+
+func setReleaseComputed(data *ReleaseResourceModel, release *git.Release) {
+	urls := make(map[string]attr.Value, len(release.Assets))
+	for _, a := range release.Assets {
+		urls[a.Name] = types.StringValue(a.URL)
+	}
+
+	data.URL = types.StringValue(release.URL)
+	data.AssetURLs = types.MapValueMust(types.StringType, urls)
+}
+
+// GitLab falls back to the tag when a release has no name, so name and notes
+// are only refreshed when they are managed.
+func setReleaseRemote(data *ReleaseResourceModel, release *git.Release) {
+	setReleaseComputed(data, release)
+	if !data.Name.IsNull() {
+		data.Name = types.StringValue(release.Name)
+	}
+	if !data.Notes.IsNull() {
+		data.Notes = types.StringValue(release.Notes)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func releaseAssets(t *testing.T, assets ...ReleaseAssetModel) types.List {
+	t.Helper()
+	list, diags := types.ListValueFrom(context.Background(), releaseAssetType(), assets)
+	require.False(t, diags.HasError(), diags)
+	return list
+}
+
+func assetChecksums(t *testing.T, list types.List) []types.String {
+	t.Helper()
+	var assets []ReleaseAssetModel
+	diags := list.ElementsAs(context.Background(), &assets, false)
+	require.False(t, diags.HasError(), diags)
+
+	sums := make([]types.String, 0, len(assets))
+	for _, a := range assets {
+		sums = append(sums, a.SHA256)
+	}
+	return sums
+}
+
+func TestHashReleaseAssets(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "app")
+	require.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+	missing := filepath.Join(t.TempDir(), "built-during-apply")
+
+	assets := releaseAssets(t,
+		ReleaseAssetModel{Name: types.StringValue("app"), Path: types.StringValue(file), SHA256: types.StringNull()},
+		ReleaseAssetModel{Name: types.StringValue("later"), Path: types.StringValue(missing), SHA256: types.StringNull()},
+	)
+
+	var diags diag.Diagnostics
+	planned := hashReleaseAssets(ctx, assets, false, &diags)
+	require.False(t, diags.HasError(), diags)
+	sums := assetChecksums(t, planned)
+	assert.Equal(t, "3bfc269594ef649228e9a74bab00f042efc91d5acc6fbee31a382e80d42388fe", sums[0].ValueString())
+	assert.True(t, sums[1].IsUnknown())
+
+	// A rebuilt file at the same path changes the plan.
+	require.NoError(t, os.WriteFile(file, []byte("v2"), 0o600))
+	rebuilt := hashReleaseAssets(ctx, assets, false, &diags)
+	assert.False(t, rebuilt.Equal(planned))
+
+	// On apply, checksums unknown during plan are required.
+	hashReleaseAssets(ctx, planned, true, &diags)
+	assert.True(t, diags.HasError())
+
+	require.NoError(t, os.WriteFile(missing, []byte("v1"), 0o600))
+	diags = nil
+	applied := hashReleaseAssets(ctx, planned, true, &diags)
+	require.False(t, diags.HasError(), diags)
+	sums = assetChecksums(t, applied)
+	assert.Equal(t, "3bfc269594ef649228e9a74bab00f042efc91d5acc6fbee31a382e80d42388fe", sums[0].ValueString())
+	assert.Equal(t, sums[0], sums[1])
+}
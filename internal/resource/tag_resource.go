@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &TagResource{}
+var _ resource.ResourceWithImportState = &TagResource{}
+
+func NewTagResource() resource.Resource {
+	return &TagResource{}
+}
+
+type TagResource struct {
+	client git.Client
+}
+
+type TagResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Tag     types.String `tfsdk:"tag"`
+	Source  types.String `tfsdk:"source"`
+	Message types.String `tfsdk:"message"`
+	SHA     types.String `tfsdk:"sha"`
+}
+
+func (r *TagResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tag"
+}
+
+func (r *TagResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a tag in a Git repository. The tag is deleted on destroy.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"tag": schema.StringAttribute{
+				MarkdownDescription: "Name of the tag to create.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Branch, tag or commit SHA to tag. A branch is resolved to its current head. Defaults to the main branch.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{requiresReplaceUnlessImported()},
+			},
+			"message": schema.StringAttribute{
+				MarkdownDescription: "Message of an annotated tag. A lightweight tag is created when omitted.",
+				Optional:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"sha": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "SHA of the tagged commit.",
+			},
+		},
+	}
+}
+
+func (r *TagResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *TagResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	source := data.Source.ValueString()
+	if source == "" {
+		source = defaultBranch
+	}
+
+	tag, err := r.client.CreateTag(ctx, data.Tag.ValueString(), source, data.Message.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create tag",
+			fmt.Sprintf(
+				"An error occurred while creating tag %q from %q: %v",
+				data.Tag.ValueString(),
+				source,
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(data.Tag.ValueString())
+	data.SHA = types.StringValue(tag.SHA)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *TagResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tag, err := r.client.GetTag(ctx, data.Tag.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read tag",
+			fmt.Sprintf(
+				"An error occurred while reading tag %q: %v",
+				data.Tag.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.SHA = types.StringValue(tag.SHA)
+	if !data.Message.IsNull() || tag.Message != "" {
+		data.Message = types.StringValue(tag.Message)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// Every attribute that changes the tag forces a replacement, so an update
+// only happens when source is set on an imported tag.
+func (r *TagResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+}
+
+func (r *TagResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data TagResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteRef(ctx, git.RefPrefixTag+data.Tag.ValueString())
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete tag",
+			fmt.Sprintf(
+				"An error occurred while deleting tag %q: %v",
+				data.Tag.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *TagResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be the name of the tag",
+		)
+		return
+	}
+
+	tag, err := r.client.GetTag(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read tag during import",
+			fmt.Sprintf(
+				"An error occurred while reading tag %q: %v",
+				req.ID,
+				err,
+			),
+		)
+		return
+	}
+
+	data := &TagResourceModel{
+		ID:      types.StringValue(req.ID),
+		Tag:     types.StringValue(req.ID),
+		Source:  types.StringNull(),
+		Message: types.StringNull(),
+		SHA:     types.StringValue(tag.SHA),
+	}
+	if tag.Message != "" {
+		data.Message = types.StringValue(tag.Message)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+}
@@ -0,0 +1,33 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"errors"
+	"fmt"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// addFileTooLargeError adds a diagnostic for a file larger than the Git
+// provider accepts and reports whether err was one.
+func addFileTooLargeError(diags *diag.Diagnostics, err error) bool {
+	var tooLarge *git.FileTooLargeError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+
+	diags.AddError(
+		"File too large",
+		fmt.Sprintf(
+			"The Git provider does not accept the file: %v. "+
+				"GitHub rejects files larger than 100 MB and GitLab applies the maximum push size of the instance. "+
+				"Store large files with Git LFS or outside of the repository.",
+			tooLarge,
+		),
+	)
+	return true
+}
@@ -0,0 +1,308 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"terraform-provider-gitsync/internal/serialize"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func valuesAttribute() schema.Attribute {
+	return schema.DynamicAttribute{
+		MarkdownDescription: "Structured file content, serialized by the provider with the layout set in the `format` block. " +
+			"The remote file is parsed back into the same structure on refresh, so drift is shown per key. " +
+			"Exactly one of `content` and `values` must be set.",
+		Optional: true,
+	}
+}
+
+func formatAttrTypes(yaml bool) map[string]attr.Type {
+	attrTypes := map[string]attr.Type{
+		"indent":           types.Int64Type,
+		"key_order":        types.ListType{ElemType: types.StringType},
+		"trailing_newline": types.BoolType,
+	}
+	if yaml {
+		attrTypes["document_start"] = types.BoolType
+	}
+	return attrTypes
+}
+
+func formatBlock(yaml bool) schema.Block {
+	attributes := map[string]schema.Attribute{
+		"indent": schema.Int64Attribute{
+			MarkdownDescription: "Number of spaces per nesting level. Defaults to `2`.",
+			Optional:            true,
+		},
+		"key_order": schema.ListAttribute{
+			MarkdownDescription: "Keys written first, in the given order, at every level. All other keys are sorted alphabetically.",
+			ElementType:         types.StringType,
+			Optional:            true,
+		},
+		"trailing_newline": schema.BoolAttribute{
+			MarkdownDescription: "End the file with a newline. Defaults to `true`.",
+			Optional:            true,
+		},
+	}
+	if yaml {
+		attributes["document_start"] = schema.BoolAttribute{
+			MarkdownDescription: "Start the file with the `---` document marker. Defaults to `false`.",
+			Optional:            true,
+		}
+	}
+
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Layout of the file rendered from `values`.",
+		Attributes:          attributes,
+	}
+}
+
+func formatOptions(block types.Object) serialize.Options {
+	opts := serialize.Options{TrailingNewline: true}
+	if block.IsNull() || block.IsUnknown() {
+		return opts
+	}
+
+	attrs := block.Attributes()
+	if v, ok := attrs["indent"].(types.Int64); ok && !v.IsNull() {
+		opts.Indent = int(v.ValueInt64())
+	}
+	if v, ok := attrs["key_order"].(types.List); ok {
+		for _, e := range v.Elements() {
+			if s, ok := e.(types.String); ok {
+				opts.KeyOrder = append(opts.KeyOrder, s.ValueString())
+			}
+		}
+	}
+	if v, ok := attrs["trailing_newline"].(types.Bool); ok && !v.IsNull() {
+		opts.TrailingNewline = v.ValueBool()
+	}
+	if v, ok := attrs["document_start"].(types.Bool); ok {
+		opts.DocumentStart = v.ValueBool()
+	}
+	return opts
+}
+
+func validateContentOrValues(content types.String, values types.Dynamic, diags *diag.Diagnostics) {
+	if content.IsNull() == values.IsNull() {
+		diags.AddError(
+			"Invalid content",
+			"Exactly one of content and values must be set",
+		)
+	}
+}
+
+// renderValues serializes the values attribute with the layout of the format
+// block.
+func renderValues(
+	ctx context.Context,
+	values types.Dynamic,
+	format types.Object,
+	encode func(any, serialize.Options) (string, error),
+) (string, error) {
+	value, err := valuesToGo(ctx, values)
+	if err != nil {
+		return "", err
+	}
+	return encode(value, formatOptions(format))
+}
+
+// parseValues parses remote content into the structure of the values
+// attribute. The types of prior are kept where the content still fits them.
+func parseValues(ctx context.Context, content string, parse func(string) (any, error), prior types.Dynamic) (types.Dynamic, error) {
+	value, err := parse(content)
+	if err != nil {
+		return types.DynamicNull(), err
+	}
+	return types.DynamicValue(goToValuesLike(ctx, value, dynamicType(ctx, prior))), nil
+}
+
+// dynamicType returns the type of the value of a dynamic attribute, or nil
+// when it has none.
+func dynamicType(ctx context.Context, value types.Dynamic) attr.Type {
+	if value.IsNull() || value.IsUnknown() || value.IsUnderlyingValueUnknown() {
+		return nil
+	}
+	return value.UnderlyingValue().Type(ctx)
+}
+
+func valuesToGo(ctx context.Context, value attr.Value) (any, error) {
+	if value.IsUnknown() {
+		return nil, fmt.Errorf("values must be known")
+	}
+	if value.IsNull() {
+		return nil, nil
+	}
+
+	switch v := value.(type) {
+	case types.Dynamic:
+		return valuesToGo(ctx, v.UnderlyingValue())
+	case types.Object:
+		return attrMapToGo(ctx, v.Attributes())
+	case types.Map:
+		return attrMapToGo(ctx, v.Elements())
+	case types.Tuple:
+		return attrListToGo(ctx, v.Elements())
+	case types.List:
+		return attrListToGo(ctx, v.Elements())
+	case types.Set:
+		return attrListToGo(ctx, v.Elements())
+	case types.String:
+		return v.ValueString(), nil
+	case types.Bool:
+		return v.ValueBool(), nil
+	case types.Number:
+		return json.Number(v.ValueBigFloat().Text('g', -1)), nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %s", value.Type(ctx))
+	}
+}
+
+func attrMapToGo(ctx context.Context, attrs map[string]attr.Value) (any, error) {
+	m := make(map[string]any, len(attrs))
+	for k, e := range attrs {
+		v, err := valuesToGo(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func attrListToGo(ctx context.Context, elems []attr.Value) (any, error) {
+	l := make([]any, 0, len(elems))
+	for i, e := range elems {
+		v, err := valuesToGo(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		l = append(l, v)
+	}
+	return l, nil
+}
+
+// goToValuesLike converts value like goToValues, but keeps the type typ
+// where value still fits it. Maps, sets, lists and typed nulls of the
+// configuration would otherwise be read back as objects, tuples and untyped
+// nulls and show up as a difference on every plan.
+func goToValuesLike(ctx context.Context, value any, typ attr.Type) attr.Value {
+	if typ == nil {
+		return goToValues(ctx, value)
+	}
+	if value == nil {
+		if _, ok := typ.(basetypes.DynamicType); ok {
+			return types.DynamicNull()
+		}
+		null, err := typ.ValueFromTerraform(ctx, tftypes.NewValue(typ.TerraformType(ctx), nil))
+		if err != nil {
+			return goToValues(ctx, value)
+		}
+		return null
+	}
+
+	switch t := typ.(type) {
+	case types.MapType:
+		if m, ok := value.(map[string]any); ok {
+			elems := make(map[string]attr.Value, len(m))
+			for k, e := range m {
+				elems[k] = goToValuesLike(ctx, e, t.ElemType)
+			}
+			if v, d := types.MapValue(t.ElemType, elems); !d.HasError() {
+				return v
+			}
+		}
+	case types.ObjectType:
+		if m, ok := value.(map[string]any); ok {
+			attrTypes := make(map[string]attr.Type, len(m))
+			attrs := make(map[string]attr.Value, len(m))
+			for k, e := range m {
+				attrs[k] = goToValuesLike(ctx, e, t.AttrTypes[k])
+				attrTypes[k] = attrs[k].Type(ctx)
+			}
+			return types.ObjectValueMust(attrTypes, attrs)
+		}
+	case types.ListType:
+		if l, ok := value.([]any); ok {
+			if v, d := types.ListValue(t.ElemType, elemsLike(ctx, l, t.ElemType)); !d.HasError() {
+				return v
+			}
+		}
+	case types.SetType:
+		if l, ok := value.([]any); ok {
+			if v, d := types.SetValue(t.ElemType, elemsLike(ctx, l, t.ElemType)); !d.HasError() {
+				return v
+			}
+		}
+	case types.TupleType:
+		if l, ok := value.([]any); ok {
+			elemTypes := make([]attr.Type, 0, len(l))
+			elems := make([]attr.Value, 0, len(l))
+			for i, e := range l {
+				var elemType attr.Type
+				if i < len(t.ElemTypes) {
+					elemType = t.ElemTypes[i]
+				}
+				elem := goToValuesLike(ctx, e, elemType)
+				elemTypes = append(elemTypes, elem.Type(ctx))
+				elems = append(elems, elem)
+			}
+			return types.TupleValueMust(elemTypes, elems)
+		}
+	}
+	return goToValues(ctx, value)
+}
+
+func elemsLike(ctx context.Context, l []any, elemType attr.Type) []attr.Value {
+	elems := make([]attr.Value, 0, len(l))
+	for _, e := range l {
+		elems = append(elems, goToValuesLike(ctx, e, elemType))
+	}
+	return elems
+}
+
+func goToValues(ctx context.Context, value any) attr.Value {
+	switch v := value.(type) {
+	case map[string]any:
+		attrTypes := make(map[string]attr.Type, len(v))
+		attrs := make(map[string]attr.Value, len(v))
+		for k, e := range v {
+			attrs[k] = goToValues(ctx, e)
+			attrTypes[k] = attrs[k].Type(ctx)
+		}
+		return types.ObjectValueMust(attrTypes, attrs)
+	case []any:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elems := make([]attr.Value, 0, len(v))
+		for _, e := range v {
+			elem := goToValues(ctx, e)
+			elemTypes = append(elemTypes, elem.Type(ctx))
+			elems = append(elems, elem)
+		}
+		return types.TupleValueMust(elemTypes, elems)
+	case string:
+		return types.StringValue(v)
+	case bool:
+		return types.BoolValue(v)
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return types.StringValue(v.String())
+		}
+		return types.NumberValue(f)
+	default:
+		return types.DynamicNull()
+	}
+}
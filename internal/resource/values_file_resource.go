@@ -5,11 +5,15 @@ package resource
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"maps"
 	"strings"
+	"unicode/utf8"
 
 	"terraform-provider-gitsync/internal/git"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -19,6 +23,7 @@ import (
 
 var _ resource.Resource = &ValuesFileResource{}
 var _ resource.ResourceWithImportState = &ValuesFileResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesFileResource{}
 
 func NewValueFileResource() resource.Resource {
 	return &ValuesFileResource{}
@@ -29,10 +34,16 @@ type ValuesFileResource struct {
 }
 
 type ValuesFileResourceModel struct {
-	ID      types.String `tfsdk:"id"`
-	Path    types.String `tfsdk:"path"`
-	Branch  types.String `tfsdk:"branch"`
-	Content types.String `tfsdk:"content"`
+	ID            types.String `tfsdk:"id"`
+	Path          types.String `tfsdk:"path"`
+	Branch        types.String `tfsdk:"branch"`
+	Content       types.String `tfsdk:"content"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+
+	OnConflict    types.String `tfsdk:"on_conflict"`
+	WaitForChecks types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
 }
 
 func (r *ValuesFileResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -41,7 +52,7 @@ func (r *ValuesFileResource) Metadata(ctx context.Context, req resource.Metadata
 
 func (r *ValuesFileResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Manages a file in a Git repository.",
+		MarkdownDescription: "Manages a file in a Git repository. Files of up to 100 MB are supported on GitHub, where files larger than 1 MB are read and written through the Git Data API. On GitLab, the limit is the maximum push size of the instance.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -57,32 +68,80 @@ func (r *ValuesFileResource) Schema(ctx context.Context, req resource.SchemaRequ
 				Optional:            true,
 			},
 			"content": schema.StringAttribute{
-				MarkdownDescription: "File content to write.",
-				Required:            true,
+				MarkdownDescription: "File content to write, as UTF-8 text. Exactly one of `content` and `content_base64` must be set.",
+				Optional:            true,
+			},
+			"content_base64": schema.StringAttribute{
+				MarkdownDescription: "Base64 encoded file content to write, for binary files like images or keystores, e.g. from `filebase64()`. " +
+					"Exactly one of `content` and `content_base64` must be set.",
+				Optional: true,
 			},
+			"on_conflict": onConflictAttribute(onConflictModes),
+		},
+		Blocks: map[string]schema.Block{
+			"wait_for_checks": waitForChecksBlock(),
 		},
 	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
 }
 
 func (r *ValuesFileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(git.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Provider Data",
-			fmt.Sprintf("Expected *git.Client, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
 		)
 		return
 	}
-	r.client = c
+	r.client = data.Client
+}
+
+func (r *ValuesFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+
+	validateFileContent(data.Content, data.ContentBase64, &resp.Diagnostics)
+}
+
+// validateFileContent checks that exactly one of content and content_base64
+// is set. Values that are not known yet are checked once they are.
+func validateFileContent(content, contentBase64 types.String, diags *diag.Diagnostics) {
+	if content.IsUnknown() || contentBase64.IsUnknown() {
+		return
+	}
+	if content.IsNull() == contentBase64.IsNull() {
+		diags.AddError(
+			"Invalid content",
+			"Exactly one of content and content_base64 must be set",
+		)
+		return
+	}
+	if contentBase64.IsNull() {
+		return
+	}
+	if _, err := base64.StdEncoding.DecodeString(contentBase64.ValueString()); err != nil {
+		diags.AddError(
+			"Invalid content_base64",
+			fmt.Sprintf("The content is not valid base64: %v", err),
+		)
+	}
 }
 
 func (r *ValuesFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ValuesFileResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	validateOnConflict(data.OnConflict, onConflictModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -91,11 +150,23 @@ func (r *ValuesFileResource) Create(ctx context.Context, req resource.CreateRequ
 		data.Branch = types.StringValue(defaultBranch)
 	}
 
-	err := r.client.Create(ctx, git.ValuesModel{
+	content, err := data.bytes()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid content_base64",
+			fmt.Sprintf("The content is not valid base64: %v", err),
+		)
+		return
+	}
+
+	commit, err := r.client.Create(ctx, git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
+		Content: content,
 	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create file",
@@ -110,7 +181,21 @@ func (r *ValuesFileResource) Create(ctx context.Context, req resource.CreateRequ
 	}
 
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -121,7 +206,11 @@ func (r *ValuesFileResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	cnt, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	cnt, err := r.client.GetFile(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read file",
@@ -135,24 +224,63 @@ func (r *ValuesFileResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read commit",
+			fmt.Sprintf(
+				"An error occurred while reading the last commit of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
-	data.Content = types.StringValue(cnt)
+	data.setBytes(cnt, !data.ContentBase64.IsNull())
+	data.setCommit(commit)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ValuesFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data ValuesFileResourceModel
+	var data, state ValuesFileResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	validateOnConflict(data.OnConflict, onConflictModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.Update(ctx, git.ValuesModel{
+	content, err := data.bytes()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid content_base64",
+			fmt.Sprintf("The content is not valid base64: %v", err),
+		)
+		return
+	}
+
+	commit, err := r.client.Update(ctx, git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
+		Content: content,
+		SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
 	})
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to update file",
@@ -166,19 +294,42 @@ func (r *ValuesFileResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data ValuesFileResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.Delete(ctx, data.Path.ValueString(), data.Branch.ValueString())
-	if err != nil {
+	err := r.client.Delete(
+		ctx,
+		data.Path.ValueString(),
+		data.Branch.ValueString(),
+		expectedSHA(data.OnConflict, applied, data.BlobSHA),
+	)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Failed to delete file",
 			fmt.Sprintf(
@@ -217,7 +368,7 @@ func (r *ValuesFileResource) ImportState(ctx context.Context, req resource.Impor
 		return
 	}
 
-	content, err := r.client.GetContent(ctx, path, branch)
+	content, err := r.client.GetFile(ctx, path, branch)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read file during import",
@@ -231,10 +382,34 @@ func (r *ValuesFileResource) ImportState(ctx context.Context, req resource.Impor
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &ValuesFileResourceModel{
-		ID:      types.StringValue(r.client.GetID(branch, path)),
-		Path:    types.StringValue(path),
-		Branch:  types.StringValue(branch),
-		Content: types.StringValue(content),
-	})...)
+	data := ValuesFileResourceModel{
+		ID:     types.StringValue(r.client.GetID(branch, path)),
+		Path:   types.StringValue(path),
+		Branch: types.StringValue(branch),
+
+		WaitForChecks: types.ObjectNull(waitForChecksAttrTypes()),
+	}
+	// Files that are not text are imported as content_base64.
+	data.setBytes(content, !utf8.Valid(content))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// bytes returns the content to write from content or content_base64.
+func (m *ValuesFileResourceModel) bytes() ([]byte, error) {
+	if !m.ContentBase64.IsNull() {
+		return base64.StdEncoding.DecodeString(m.ContentBase64.ValueString())
+	}
+	return []byte(m.Content.ValueString()), nil
+}
+
+// setBytes sets content_base64 to the remote content when encoded is set,
+// and content otherwise.
+func (m *ValuesFileResourceModel) setBytes(content []byte, encoded bool) {
+	if encoded {
+		m.Content = types.StringNull()
+		m.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+		return
+	}
+	m.Content = types.StringValue(string(content))
+	m.ContentBase64 = types.StringNull()
 }
@@ -0,0 +1,136 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"terraform-provider-gitsync/internal/git"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuesFileBinaryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	binary := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe, '\n', 0x80}
+	encoded := base64.StdEncoding.EncodeToString(binary)
+	client := &fakeClient{}
+
+	planned := ValuesFileResourceModel{Content: types.StringNull(), ContentBase64: types.StringValue(encoded)}
+	content, err := planned.bytes()
+	require.NoError(t, err)
+	_, err = client.Update(ctx, git.ValuesModel{Path: "logo.png", Content: content})
+	require.NoError(t, err)
+
+	remote, err := client.GetFile(ctx, "logo.png", "main")
+	require.NoError(t, err)
+	var read ValuesFileResourceModel
+	read.setBytes(remote, !planned.ContentBase64.IsNull())
+	assert.True(t, read.Content.IsNull())
+	assert.Equal(t, encoded, read.ContentBase64.ValueString())
+	assert.Equal(t, binary, remote)
+}
+
+func TestValidateFileContent(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       types.String
+		contentBase64 types.String
+		err           string
+	}{
+		{
+			name:          "content",
+			content:       types.StringValue("a: 1\n"),
+			contentBase64: types.StringNull(),
+		},
+		{
+			name:          "content_base64",
+			content:       types.StringNull(),
+			contentBase64: types.StringValue("YTogMQo="),
+		},
+		{
+			name:          "both",
+			content:       types.StringValue("a: 1\n"),
+			contentBase64: types.StringValue("YTogMQo="),
+			err:           "Invalid content",
+		},
+		{
+			name:          "none",
+			content:       types.StringNull(),
+			contentBase64: types.StringNull(),
+			err:           "Invalid content",
+		},
+		{
+			name:          "both unknown",
+			content:       types.StringUnknown(),
+			contentBase64: types.StringUnknown(),
+		},
+		{
+			name:          "one unknown",
+			content:       types.StringNull(),
+			contentBase64: types.StringUnknown(),
+		},
+		{
+			name:          "invalid base64",
+			content:       types.StringNull(),
+			contentBase64: types.StringValue("not base64!"),
+			err:           "Invalid content_base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			validateFileContent(tt.content, tt.contentBase64, &diags)
+			if tt.err == "" {
+				assert.False(t, diags.HasError(), diags)
+				return
+			}
+			require.True(t, diags.HasError())
+			assert.Equal(t, tt.err, diags[0].Summary())
+		})
+	}
+}
+
+// missingFileClient behaves like a repository where the file was deleted out
+// of band.
+type missingFileClient struct {
+	git.Client
+}
+
+func (c *missingFileClient) GetFile(ctx context.Context, path, branch string) ([]byte, error) {
+	return nil, &git.NotFoundError{Path: path, Branch: branch}
+}
+
+func TestValuesFileReadRemovesDeletedFile(t *testing.T) {
+	ctx := context.Background()
+	r := &ValuesFileResource{client: &missingFileClient{}}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), schemaResp.Diagnostics)
+
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}
+	diags := state.SetAttribute(ctx, path.Root("path"), types.StringValue("values.yaml"))
+	require.False(t, diags.HasError(), diags)
+	diags = state.SetAttribute(ctx, path.Root("branch"), types.StringValue("main"))
+	require.False(t, diags.HasError(), diags)
+
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.True(t, resp.State.Raw.IsNull())
+}
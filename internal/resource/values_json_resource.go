@@ -6,10 +6,16 @@ package resource
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
 
+	"terraform-provider-gitsync/internal/customtypes"
 	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/jsonc"
+	"terraform-provider-gitsync/internal/merge"
+	"terraform-provider-gitsync/internal/policy"
+	"terraform-provider-gitsync/internal/serialize"
 	"terraform-provider-gitsync/internal/validators"
 
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -21,6 +27,8 @@ import (
 
 var _ resource.Resource = &ValuesJsonResource{}
 var _ resource.ResourceWithImportState = &ValuesJsonResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesJsonResource{}
+var _ resource.ResourceWithModifyPlan = &ValuesJsonResource{}
 
 func NewValueJsonResource() resource.Resource {
 	return &ValuesJsonResource{}
@@ -28,13 +36,25 @@ func NewValueJsonResource() resource.Resource {
 
 type ValuesJsonResource struct {
 	client git.Client
+	policy *policy.Policy
 }
 
 type ValuesJsonResourceModel struct {
-	ID      types.String `tfsdk:"id"`
-	Path    types.String `tfsdk:"path"`
-	Branch  types.String `tfsdk:"branch"`
-	Content types.String `tfsdk:"content"`
+	ID      types.String     `tfsdk:"id"`
+	Path    types.String     `tfsdk:"path"`
+	Branch  types.String     `tfsdk:"branch"`
+	Content customtypes.JSON `tfsdk:"content"`
+	Values  types.Dynamic    `tfsdk:"values"`
+
+	Schema     types.String `tfsdk:"schema"`
+	SchemaPath types.String `tfsdk:"schema_path"`
+
+	OnConflict    types.String `tfsdk:"on_conflict"`
+	Format        types.Object `tfsdk:"format"`
+	Policy        types.Object `tfsdk:"policy"`
+	WaitForChecks types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
 }
 
 func (r *ValuesJsonResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -51,7 +71,7 @@ func (r *ValuesJsonResource) Schema(ctx context.Context, req resource.SchemaRequ
 				MarkdownDescription: "Unique ID.",
 			},
 			"path": schema.StringAttribute{
-				MarkdownDescription: "Relative path of the file in the repo.",
+				MarkdownDescription: "Relative path of the file in the repo. Files with the `.jsonc` extension may contain comments and trailing commas.",
 				Required:            true,
 			},
 			"branch": schema.StringAttribute{
@@ -59,32 +79,98 @@ func (r *ValuesJsonResource) Schema(ctx context.Context, req resource.SchemaRequ
 				Optional:            true,
 			},
 			"content": schema.StringAttribute{
-				MarkdownDescription: "File content to write.",
-				Required:            true,
+				MarkdownDescription: "File content to write. Changes that keep the parsed content the same, like key order or indentation, are not reported as a difference. Set to the rendered file when `values` is used.",
+				CustomType:          customtypes.JSONType{},
+				Optional:            true,
+				Computed:            true,
 			},
+			"values":      valuesAttribute(),
+			"on_conflict": onConflictAttribute(onConflictMergeModes),
+		},
+		Blocks: map[string]schema.Block{
+			"format":          formatBlock(false),
+			"policy":          policyBlock(),
+			"wait_for_checks": waitForChecksBlock(),
 		},
 	}
+	maps.Copy(resp.Schema.Attributes, jsonSchemaAttributes())
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
 }
 
 func (r *ValuesJsonResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(git.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Provider Data",
-			fmt.Sprintf("Expected *git.Client, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
 		)
 		return
 	}
-	r.client = c
+	r.client = data.Client
+	r.policy = data.Policy
+}
+
+func (r *ValuesJsonResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesJsonResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+
+	validateContentOrValues(data.Content.StringValue, data.Values, &resp.Diagnostics)
+	validateJSONSchemaConfig(data.Schema, data.SchemaPath, &resp.Diagnostics)
+}
+
+// ModifyPlan validates the planned content against the JSON schema and the
+// policies, so a mismatch is reported before anything is committed.
+func (r *ValuesJsonResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data ValuesJsonResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	schemaKnown := jsonSchemaKnown(data.Schema, data.SchemaPath)
+	policyUsed := r.policy != nil || policyKnown(ctx, data.Policy)
+	if resp.Diagnostics.HasError() || (!schemaKnown && !policyUsed) ||
+		data.Branch.IsUnknown() || data.Values.IsUnknown() || data.Values.IsUnderlyingValueUnknown() {
+		return
+	}
+
+	content := data.Content.ValueString()
+	if !data.Values.IsNull() {
+		var err error
+		if content, err = renderValues(ctx, data.Values, data.Format, serialize.JSON); err != nil {
+			// Reported by Create and Update, or unknown until apply.
+			return
+		}
+	} else if data.Content.IsUnknown() {
+		return
+	}
+
+	document, err := parseJSONContent(content)
+	if err != nil {
+		return
+	}
+	if schemaKnown {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), []any{document}, true, &resp.Diagnostics)
+	}
+	if policyUsed {
+		evaluatePolicies(ctx, r.policy, data.Policy, []any{document}, true, &resp.Diagnostics)
+	}
 }
 
 func (r *ValuesJsonResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ValuesJsonResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -102,7 +188,19 @@ func (r *ValuesJsonResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	if err := validators.ValidateJSON(data.Content.ValueString()); err != nil {
+	if !data.Values.IsNull() {
+		content, err := renderValues(ctx, data.Values, data.Format, serialize.JSON)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid values",
+				fmt.Sprintf("The values cannot be serialized as JSON: %v", err),
+			)
+			return
+		}
+		data.Content = customtypes.NewJSONValue(content)
+	}
+
+	if err := validateJSONContent(data.Path.ValueString(), data.Content.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid JSON content",
 			fmt.Sprintf("The content is not valid JSON: %v", err),
@@ -110,11 +208,22 @@ func (r *ValuesJsonResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	err := r.client.Create(ctx, git.ValuesModel{
+	if document, err := parseJSONContent(data.Content.ValueString()); err == nil {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), []any{document}, false, &resp.Diagnostics)
+		evaluatePolicies(ctx, r.policy, data.Policy, []any{document}, false, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	commit, err := r.client.Create(ctx, git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
+		Content: []byte(data.Content.ValueString()),
 	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create file",
@@ -129,7 +238,21 @@ func (r *ValuesJsonResource) Create(ctx context.Context, req resource.CreateRequ
 	}
 
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), data.Content.ValueString()), &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesJsonResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -141,6 +264,10 @@ func (r *ValuesJsonResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	cnt, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read file",
@@ -154,15 +281,43 @@ func (r *ValuesJsonResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read commit",
+			fmt.Sprintf(
+				"An error occurred while reading the last commit of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	applied := recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
+	cnt = appliedContent(applied, commit, cnt)
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
-	data.Content = types.StringValue(cnt)
+	data.Content = customtypes.NewJSONValue(cnt)
+	if !data.Values.IsNull() {
+		// Content that cannot be parsed any more shows up as a change of values.
+		data.Values, _ = parseValues(ctx, cnt, parseJSONContent, data.Values)
+	}
+	data.setCommit(commit)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ValuesJsonResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data ValuesJsonResourceModel
+	var data, state ValuesJsonResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -176,7 +331,19 @@ func (r *ValuesJsonResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	if err := validators.ValidateJSON(data.Content.ValueString()); err != nil {
+	if !data.Values.IsNull() {
+		content, err := renderValues(ctx, data.Values, data.Format, serialize.JSON)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid values",
+				fmt.Sprintf("The values cannot be serialized as JSON: %v", err),
+			)
+			return
+		}
+		data.Content = customtypes.NewJSONValue(content)
+	}
+
+	if err := validateJSONContent(data.Path.ValueString(), data.Content.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid JSON content",
 			fmt.Sprintf("The content is not valid JSON: %v", err),
@@ -184,11 +351,28 @@ func (r *ValuesJsonResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	err := r.client.Update(ctx, git.ValuesModel{
+	if document, err := parseJSONContent(data.Content.ValueString()); err == nil {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), []any{document}, false, &resp.Diagnostics)
+		evaluatePolicies(ctx, r.policy, data.Policy, []any{document}, false, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	model := git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
-	})
+		Content: []byte(data.Content.ValueString()),
+		SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
+	}
+
+	commit, committed, err := updateFile(ctx, r.client, model, data.OnConflict, applied, state.Content.ValueString(), merge.JSON)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to update file",
@@ -202,19 +386,42 @@ func (r *ValuesJsonResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), committed), &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesJsonResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data ValuesJsonResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.Delete(ctx, data.Path.ValueString(), data.Branch.ValueString())
-	if err != nil {
+	err := r.client.Delete(
+		ctx,
+		data.Path.ValueString(),
+		data.Branch.ValueString(),
+		expectedSHA(data.OnConflict, applied, data.BlobSHA),
+	)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Failed to delete file",
 			fmt.Sprintf(
@@ -271,6 +478,30 @@ func (r *ValuesJsonResource) ImportState(ctx context.Context, req resource.Impor
 		ID:      types.StringValue(r.client.GetID(branch, path)),
 		Path:    types.StringValue(path),
 		Branch:  types.StringValue(branch),
-		Content: types.StringValue(content),
+		Content: customtypes.NewJSONValue(content),
+		Values:  types.DynamicNull(),
+
+		Format:        types.ObjectNull(formatAttrTypes(false)),
+		Policy:        types.ObjectNull(policyAttrTypes()),
+		WaitForChecks: types.ObjectNull(waitForChecksAttrTypes()),
 	})...)
 }
+
+// validateJSONContent validates .jsonc files as JSON with comments and
+// trailing commas and all other files as strict JSON.
+func validateJSONContent(path, content string) error {
+	if filepath.Ext(path) == ".jsonc" {
+		return validators.ValidateJSONC(content)
+	}
+	return validators.ValidateJSON(content)
+}
+
+// parseJSONContent parses remote content, ignoring the comments and trailing
+// commas of JSONC.
+func parseJSONContent(content string) (any, error) {
+	plain, err := jsonc.ToJSON([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	return serialize.ParseJSON(string(plain))
+}
@@ -0,0 +1,601 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/keyvalue"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &ValuesKeyValueResource{}
+var _ resource.ResourceWithImportState = &ValuesKeyValueResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesKeyValueResource{}
+
+func NewValueKeyValueResource() resource.Resource {
+	return &ValuesKeyValueResource{}
+}
+
+type ValuesKeyValueResource struct {
+	client git.Client
+}
+
+type ValuesKeyValueResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Path           types.String `tfsdk:"path"`
+	Branch         types.String `tfsdk:"branch"`
+	Format         types.String `tfsdk:"format"`
+	Content        types.String `tfsdk:"content"`
+	Keys           types.Map    `tfsdk:"keys"`
+	OnDestroy      types.String `tfsdk:"on_destroy"`
+	OriginalValues types.Map    `tfsdk:"original_values"`
+
+	OnConflict    types.String `tfsdk:"on_conflict"`
+	WaitForChecks types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
+}
+
+func (r *ValuesKeyValueResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_values_keyvalue"
+}
+
+func (r *ValuesKeyValueResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a dotenv, INI or Java properties file in a Git repository. " +
+			"Either the whole `content` is managed, or only the `keys` listed, keeping all other lines and comments of the file.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Relative path of the file in the repo.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch to commit to. Defaults to the main branch.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultBranch),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"format": schema.StringAttribute{
+				MarkdownDescription: "Format of the file, one of `dotenv`, `ini` or `properties`. " +
+					"Defaults to the format of the file name: `.env`, `.env.*` and `*.env` files are dotenv files, `*.ini` files INI files and `*.properties` files Java properties files.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "File content to write. Duplicate keys and invalid escape sequences are rejected. Conflicts with `keys`.",
+				Optional:            true,
+			},
+			"keys": schema.MapAttribute{
+				MarkdownDescription: "Values of single keys to set in an existing file, instead of managing the whole content. " +
+					"Keys of INI sections are written as `section.key`, missing sections are added. " +
+					"All other lines and comments are kept, and values are quoted or escaped as the format requires. Conflicts with `content`.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: "What to do with the `keys` on destroy or when they are removed from `keys`. " +
+					"`remove` deletes them, `restore` puts back the values they had before they were managed. Defaults to `remove`.",
+				Optional: true,
+			},
+			"original_values": schema.MapAttribute{
+				MarkdownDescription: "Values the `keys` had before they were managed. Keys that did not exist are left out.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"on_conflict": onConflictAttribute(onConflictModes),
+		},
+		Blocks: map[string]schema.Block{
+			"wait_for_checks": waitForChecksBlock(),
+		},
+	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
+}
+
+func (r *ValuesKeyValueResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *ValuesKeyValueResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesKeyValueResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+
+	if data.Content.IsNull() == data.Keys.IsNull() {
+		resp.Diagnostics.AddError(
+			"Invalid content",
+			"Exactly one of content and keys must be set",
+		)
+	}
+}
+
+func (r *ValuesKeyValueResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ValuesKeyValueResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := validateKeyValueModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var commit *git.Commit
+	var err error
+	originals := map[string]string{}
+	if keys == nil {
+		commit, err = r.client.Create(ctx, git.ValuesModel{
+			Path:    data.Path.ValueString(),
+			Branch:  data.Branch.ValueString(),
+			Content: []byte(data.Content.ValueString()),
+		})
+	} else {
+		commit, err = r.patch(ctx, &data, keys, nil, originals)
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.OriginalValues = keyValueOriginals(keys, originals)
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesKeyValueResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ValuesKeyValueResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	doc, content, commit, err := r.load(ctx, &data)
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	if data.Keys.IsNull() {
+		data.Content = types.StringValue(content)
+	} else {
+		// Keys that were removed remotely are left out, so they are planned
+		// to be set again.
+		state := map[string]string{}
+		resp.Diagnostics.Append(data.Keys.ElementsAs(ctx, &state, false)...)
+		current := make(map[string]attr.Value, len(state))
+		for key := range state {
+			if value, ok := doc.Get(key); ok {
+				current[key] = types.StringValue(value)
+			}
+		}
+		data.Keys = types.MapValueMust(types.StringType, current)
+	}
+
+	recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ValuesKeyValueResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ValuesKeyValueResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := validateKeyValueModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	originals := map[string]string{}
+	previous := map[string]string{}
+	resp.Diagnostics.Append(state.OriginalValues.ElementsAs(ctx, &originals, false)...)
+	resp.Diagnostics.Append(state.Keys.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var removed []string
+	for key := range previous {
+		if _, ok := keys[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	var commit *git.Commit
+	var err error
+	if keys == nil {
+		commit, err = r.client.Update(ctx, git.ValuesModel{
+			Path:    data.Path.ValueString(),
+			Branch:  data.Branch.ValueString(),
+			Content: []byte(data.Content.ValueString()),
+			SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
+		})
+	} else {
+		commit, err = r.patch(ctx, &data, keys, removed, originals)
+	}
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	for _, key := range removed {
+		delete(originals, key)
+	}
+	data.OriginalValues = keyValueOriginals(keys, originals)
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesKeyValueResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ValuesKeyValueResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var err error
+	if data.Keys.IsNull() {
+		err = r.client.Delete(
+			ctx,
+			data.Path.ValueString(),
+			data.Branch.ValueString(),
+			expectedSHA(data.OnConflict, applied, data.BlobSHA),
+		)
+	} else {
+		originals := map[string]string{}
+		keys := map[string]string{}
+		resp.Diagnostics.Append(data.OriginalValues.ElementsAs(ctx, &originals, false)...)
+		resp.Diagnostics.Append(data.Keys.ElementsAs(ctx, &keys, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		_, err = r.patch(ctx, &data, nil, slices.Collect(maps.Keys(keys)), originals)
+	}
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete file",
+			fmt.Sprintf(
+				"An error occurred while deleting %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *ValuesKeyValueResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID := req.ID
+	var branch, path string
+
+	parts := strings.SplitN(importID, ":", 2)
+	if len(parts) == 2 {
+		branch = parts[0]
+		path = parts[1]
+	} else {
+		branch = defaultBranch
+		path = importID
+	}
+
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	if path == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be in format 'branch:path' or 'path'",
+		)
+		return
+	}
+
+	format, ok := keyvalue.DetectFormat(path)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The format of %q cannot be detected from its name, only dotenv, INI and properties files can be imported", path),
+		)
+		return
+	}
+
+	content, err := r.client.GetContent(ctx, path, branch)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file during import",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				path,
+				branch,
+				err,
+			),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &ValuesKeyValueResourceModel{
+		ID:             types.StringValue(r.client.GetID(branch, path)),
+		Path:           types.StringValue(path),
+		Branch:         types.StringValue(branch),
+		Format:         types.StringValue(string(format)),
+		Content:        types.StringValue(content),
+		Keys:           types.MapNull(types.StringType),
+		OriginalValues: types.MapNull(types.StringType),
+
+		WaitForChecks: types.ObjectNull(waitForChecksAttrTypes()),
+	})...)
+}
+
+// load reads the file together with its last commit. The commit is read
+// first, so a change in between fails the write instead of being overwritten.
+// The content is only parsed when keys are managed.
+func (r *ValuesKeyValueResource) load(ctx context.Context, data *ValuesKeyValueResourceModel) (*keyvalue.Document, string, *git.Commit, error) {
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	content, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if data.Keys.IsNull() {
+		return nil, content, commit, nil
+	}
+
+	doc, err := keyvalue.Parse(keyvalue.Format(data.Format.ValueString()), content)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return doc, content, commit, nil
+}
+
+// patch sets keys, reverts the removed keys according to on_destroy and
+// commits the result like the yaml_keys resource does.
+func (r *ValuesKeyValueResource) patch(
+	ctx context.Context,
+	data *ValuesKeyValueResourceModel,
+	keys map[string]string,
+	removed []string,
+	originals map[string]string,
+) (*git.Commit, error) {
+	operation := func() (*git.Commit, error) {
+		doc, _, commit, err := r.load(ctx, data)
+		if err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		for key := range keys {
+			if _, ok := originals[key]; ok {
+				continue
+			}
+			if value, ok := doc.Get(key); ok {
+				originals[key] = value
+			}
+		}
+
+		before := doc.String()
+		if err := patchKeyValue(doc, data.OnDestroy.ValueString(), keys, removed, originals); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		if doc.String() == before {
+			return commit, nil
+		}
+
+		// Keys that are not valid in the format only fail once written.
+		if _, err := keyvalue.Parse(keyvalue.Format(data.Format.ValueString()), doc.String()); err != nil {
+			return nil, backoff.Permanent(fmt.Errorf("invalid result: %w", err))
+		}
+
+		commit, err = r.client.Update(ctx, git.ValuesModel{
+			Path:    data.Path.ValueString(),
+			Branch:  data.Branch.ValueString(),
+			Content: []byte(doc.String()),
+			SHA:     commit.BlobSHA,
+		})
+		var conflict *git.ConflictError
+		if err != nil && !errors.As(err, &conflict) {
+			return nil, backoff.Permanent(err)
+		}
+		return commit, err
+	}
+
+	return backoff.Retry(ctx, operation, backoff.WithMaxTries(5))
+}
+
+func patchKeyValue(doc *keyvalue.Document, onDestroy string, keys map[string]string, removed []string, originals map[string]string) error {
+	for _, key := range slices.Sorted(slices.Values(removed)) {
+		original, ok := originals[key]
+		if onDestroy != onDestroyRestore || !ok {
+			if _, err := doc.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := doc.Set(key, original); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if err := doc.Set(key, keys[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKeyValueModel checks the format and content of the model and sets
+// the format detected from the path. It returns the keys to set, or nil when
+// the whole content is managed.
+func validateKeyValueModel(ctx context.Context, data *ValuesKeyValueResourceModel, diags *diag.Diagnostics) map[string]string {
+	validateOnConflict(data.OnConflict, onConflictModes, diags)
+
+	onDestroy := []string{onDestroyRemove, onDestroyRestore}
+	if !data.OnDestroy.IsNull() && !slices.Contains(onDestroy, data.OnDestroy.ValueString()) {
+		diags.AddError(
+			"Invalid on_destroy value",
+			fmt.Sprintf("The value %q is not valid, must be one of %q", data.OnDestroy.ValueString(), onDestroy),
+		)
+	}
+
+	if data.Format.IsNull() || data.Format.IsUnknown() {
+		format, ok := keyvalue.DetectFormat(data.Path.ValueString())
+		if !ok {
+			diags.AddError(
+				"Invalid file extension",
+				fmt.Sprintf("The format of %q cannot be detected from its name, set format to one of %q", data.Path.ValueString(), keyvalue.Formats),
+			)
+			return nil
+		}
+		data.Format = types.StringValue(string(format))
+	}
+	format := data.Format.ValueString()
+	if !slices.Contains(keyvalue.Formats, format) {
+		diags.AddError(
+			"Invalid format",
+			fmt.Sprintf("The format %q is not valid, must be one of %q", format, keyvalue.Formats),
+		)
+		return nil
+	}
+
+	if data.Keys.IsNull() {
+		if err := validators.ValidateKeyValue(data.Content.ValueString(), keyvalue.Format(format)); err != nil {
+			diags.AddError(
+				"Invalid content",
+				fmt.Sprintf("The content is not a valid %s file: %v", format, err),
+			)
+		}
+		return nil
+	}
+
+	keys := map[string]string{}
+	diags.Append(data.Keys.ElementsAs(ctx, &keys, false)...)
+	return keys
+}
+
+// keyValueOriginals returns the original_values attribute, which is null
+// when the whole content is managed.
+func keyValueOriginals(keys map[string]string, originals map[string]string) types.Map {
+	if keys == nil {
+		return types.MapNull(types.StringType)
+	}
+	return originalValues(originals)
+}
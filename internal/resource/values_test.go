@@ -0,0 +1,81 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"terraform-provider-gitsync/internal/serialize"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValuesKeepsTypes(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		value attr.Value
+	}{
+		{
+			name: "map",
+			value: types.MapValueMust(types.StringType, map[string]attr.Value{
+				"a": types.StringValue("x"),
+				"b": types.StringValue("y"),
+			}),
+		},
+		{
+			name: "set",
+			value: types.ObjectValueMust(
+				map[string]attr.Type{"hosts": types.SetType{ElemType: types.StringType}},
+				map[string]attr.Value{"hosts": types.SetValueMust(types.StringType, []attr.Value{
+					types.StringValue("a.example.com"),
+					types.StringValue("b.example.com"),
+				})},
+			),
+		},
+		{
+			name: "typed null",
+			value: types.ObjectValueMust(
+				map[string]attr.Type{"tag": types.StringType, "replicas": types.NumberType},
+				map[string]attr.Value{"tag": types.StringNull(), "replicas": types.NumberNull()},
+			),
+		},
+		{
+			name: "list of maps",
+			value: types.ListValueMust(types.MapType{ElemType: types.BoolType}, []attr.Value{
+				types.MapValueMust(types.BoolType, map[string]attr.Value{"enabled": types.BoolValue(true)}),
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prior := types.DynamicValue(tt.value)
+			content, err := renderValues(ctx, prior, types.ObjectNull(formatAttrTypes(true)), serialize.YAML)
+			require.NoError(t, err)
+
+			parsed, err := parseValues(ctx, content, serialize.ParseYAML, prior)
+			require.NoError(t, err)
+			assert.True(t, prior.Equal(parsed), "%s\n%s", prior, parsed)
+		})
+	}
+}
+
+func TestParseValuesChangedType(t *testing.T) {
+	ctx := context.Background()
+	prior := types.DynamicValue(types.MapValueMust(types.StringType, map[string]attr.Value{
+		"a": types.StringValue("x"),
+	}))
+
+	// A remote value that no longer fits the map is read as an object, so
+	// the change shows up in the plan.
+	parsed, err := parseValues(ctx, "a: x\nb:\n  c: true\n", serialize.ParseYAML, prior)
+	require.NoError(t, err)
+	assert.IsType(t, types.Object{}, parsed.UnderlyingValue())
+	assert.False(t, prior.Equal(parsed))
+}
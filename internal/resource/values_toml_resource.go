@@ -0,0 +1,382 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"terraform-provider-gitsync/internal/customtypes"
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/merge"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &ValuesTomlResource{}
+var _ resource.ResourceWithImportState = &ValuesTomlResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesTomlResource{}
+
+func NewValueTomlResource() resource.Resource {
+	return &ValuesTomlResource{}
+}
+
+type ValuesTomlResource struct {
+	client git.Client
+}
+
+type ValuesTomlResourceModel struct {
+	ID      types.String     `tfsdk:"id"`
+	Path    types.String     `tfsdk:"path"`
+	Branch  types.String     `tfsdk:"branch"`
+	Content customtypes.TOML `tfsdk:"content"`
+
+	OnConflict    types.String `tfsdk:"on_conflict"`
+	WaitForChecks types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
+}
+
+func (r *ValuesTomlResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_values_toml"
+}
+
+func (r *ValuesTomlResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a toml file in a Git repository.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Relative path of the file in the repo.",
+				Required:            true,
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch to commit to. Defaults to the main branch.",
+				Optional:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "File content to write. Changes that keep the parsed content the same, like key order, comments or inline tables, are not reported as a difference.",
+				CustomType:          customtypes.TOMLType{},
+				Required:            true,
+			},
+			"on_conflict": onConflictAttribute(onConflictMergeModes),
+		},
+		Blocks: map[string]schema.Block{
+			"wait_for_checks": waitForChecksBlock(),
+		},
+	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
+}
+
+func (r *ValuesTomlResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *ValuesTomlResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesTomlResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+}
+
+func (r *ValuesTomlResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ValuesTomlResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Branch.IsNull() || data.Branch.ValueString() == "" {
+		data.Branch = types.StringValue(defaultBranch)
+	}
+
+	ext := filepath.Ext(data.Path.ValueString())
+	if ext != ".toml" {
+		resp.Diagnostics.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The file extension %q is not valid, must be .toml", ext),
+		)
+		return
+	}
+
+	if err := validators.ValidateTOML(data.Content.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid TOML content",
+			fmt.Sprintf("The content is not valid TOML: %v", err),
+		)
+		return
+	}
+
+	commit, err := r.client.Create(ctx, git.ValuesModel{
+		Path:    data.Path.ValueString(),
+		Branch:  data.Branch.ValueString(),
+		Content: []byte(data.Content.ValueString()),
+	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), data.Content.ValueString()), &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesTomlResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ValuesTomlResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cnt, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read commit",
+			fmt.Sprintf(
+				"An error occurred while reading the last commit of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	applied := recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
+	cnt = appliedContent(applied, commit, cnt)
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.Content = customtypes.NewTOMLValue(cnt)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ValuesTomlResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ValuesTomlResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ext := filepath.Ext(data.Path.ValueString())
+	if ext != ".toml" {
+		resp.Diagnostics.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The file extension %q is not valid, must be .toml", ext),
+		)
+		return
+	}
+
+	if err := validators.ValidateTOML(data.Content.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid TOML content",
+			fmt.Sprintf("The content is not valid TOML: %v", err),
+		)
+		return
+	}
+
+	model := git.ValuesModel{
+		Path:    data.Path.ValueString(),
+		Branch:  data.Branch.ValueString(),
+		Content: []byte(data.Content.ValueString()),
+		SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
+	}
+
+	commit, committed, err := updateFile(ctx, r.client, model, data.OnConflict, applied, state.Content.ValueString(), merge.TOML)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), committed), &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesTomlResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ValuesTomlResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Delete(
+		ctx,
+		data.Path.ValueString(),
+		data.Branch.ValueString(),
+		expectedSHA(data.OnConflict, applied, data.BlobSHA),
+	)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete file",
+			fmt.Sprintf(
+				"An error occurred while deleting %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *ValuesTomlResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID := req.ID
+	var branch, path string
+
+	parts := strings.SplitN(importID, ":", 2)
+	if len(parts) == 2 {
+		branch = parts[0]
+		path = parts[1]
+	} else {
+		branch = defaultBranch
+		path = importID
+	}
+
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	if path == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be in format 'branch:path' or 'path'",
+		)
+		return
+	}
+
+	content, err := r.client.GetContent(ctx, path, branch)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file during import",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				path,
+				branch,
+				err,
+			),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &ValuesTomlResourceModel{
+		ID:      types.StringValue(r.client.GetID(branch, path)),
+		Path:    types.StringValue(path),
+		Branch:  types.StringValue(branch),
+		Content: customtypes.NewTOMLValue(content),
+
+		WaitForChecks: types.ObjectNull(waitForChecksAttrTypes()),
+	})...)
+}
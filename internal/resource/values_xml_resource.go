@@ -0,0 +1,420 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/validators"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &ValuesXmlResource{}
+var _ resource.ResourceWithImportState = &ValuesXmlResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesXmlResource{}
+
+func NewValueXmlResource() resource.Resource {
+	return &ValuesXmlResource{}
+}
+
+type ValuesXmlResource struct {
+	client git.Client
+}
+
+type ValuesXmlResourceModel struct {
+	ID      types.String `tfsdk:"id"`
+	Path    types.String `tfsdk:"path"`
+	Branch  types.String `tfsdk:"branch"`
+	Content types.String `tfsdk:"content"`
+
+	XSD           types.String `tfsdk:"xsd"`
+	XSDPath       types.String `tfsdk:"xsd_path"`
+	OnConflict    types.String `tfsdk:"on_conflict"`
+	WaitForChecks types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
+}
+
+func (r *ValuesXmlResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_values_xml"
+}
+
+func (r *ValuesXmlResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an xml file in a Git repository. The content must be well-formed and can be validated against an XML Schema.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Relative path of the file in the repo.",
+				Required:            true,
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch to commit to. Defaults to the main branch.",
+				Optional:            true,
+			},
+			"content": schema.StringAttribute{
+				MarkdownDescription: "File content to write.",
+				Required:            true,
+			},
+			"xsd": schema.StringAttribute{
+				MarkdownDescription: "XML Schema (XSD) to validate the content against before it is committed. " +
+					"The schema must be self-contained, `xs:include` is not supported. Conflicts with `xsd_path`.",
+				Optional: true,
+			},
+			"xsd_path": schema.StringAttribute{
+				MarkdownDescription: "Path of an XML Schema (XSD) in the same branch of the repo to validate the content against, like `xsd`. Conflicts with `xsd`.",
+				Optional:            true,
+			},
+			"on_conflict": onConflictAttribute(onConflictModes),
+		},
+		Blocks: map[string]schema.Block{
+			"wait_for_checks": waitForChecksBlock(),
+		},
+	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
+}
+
+func (r *ValuesXmlResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *ValuesXmlResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesXmlResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+
+	if !data.XSD.IsNull() && !data.XSDPath.IsNull() {
+		resp.Diagnostics.AddError(
+			"Invalid XML schema",
+			"Only one of xsd and xsd_path can be set",
+		)
+	}
+}
+
+func (r *ValuesXmlResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data ValuesXmlResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	validateOnConflict(data.OnConflict, onConflictModes, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Branch.IsNull() || data.Branch.ValueString() == "" {
+		data.Branch = types.StringValue(defaultBranch)
+	}
+
+	r.validate(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	commit, err := r.client.Create(ctx, git.ValuesModel{
+		Path:    data.Path.ValueString(),
+		Branch:  data.Branch.ValueString(),
+		Content: []byte(data.Content.ValueString()),
+	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesXmlResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data ValuesXmlResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cnt, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read commit",
+			fmt.Sprintf(
+				"An error occurred while reading the last commit of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.Content = types.StringValue(cnt)
+	recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *ValuesXmlResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state ValuesXmlResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	validateOnConflict(data.OnConflict, onConflictModes, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.validate(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	commit, err := r.client.Update(ctx, git.ValuesModel{
+		Path:    data.Path.ValueString(),
+		Branch:  data.Branch.ValueString(),
+		Content: []byte(data.Content.ValueString()),
+		SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
+	})
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update file",
+			fmt.Sprintf(
+				"An error occurred while updating %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, appliedFile{BlobSHA: commit.BlobSHA}, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
+}
+
+func (r *ValuesXmlResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data ValuesXmlResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.Delete(
+		ctx,
+		data.Path.ValueString(),
+		data.Branch.ValueString(),
+		expectedSHA(data.OnConflict, applied, data.BlobSHA),
+	)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete file",
+			fmt.Sprintf(
+				"An error occurred while deleting %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *ValuesXmlResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID := req.ID
+	var branch, path string
+
+	parts := strings.SplitN(importID, ":", 2)
+	if len(parts) == 2 {
+		branch = parts[0]
+		path = parts[1]
+	} else {
+		branch = defaultBranch
+		path = importID
+	}
+
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	if path == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be in format 'branch:path' or 'path'",
+		)
+		return
+	}
+
+	content, err := r.client.GetContent(ctx, path, branch)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file during import",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				path,
+				branch,
+				err,
+			),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &ValuesXmlResourceModel{
+		ID:      types.StringValue(r.client.GetID(branch, path)),
+		Path:    types.StringValue(path),
+		Branch:  types.StringValue(branch),
+		Content: types.StringValue(content),
+
+		WaitForChecks: types.ObjectNull(waitForChecksAttrTypes()),
+	})...)
+}
+
+// validate checks the extension and that the content is well-formed XML, and
+// validates it against the XML Schema of xsd or xsd_path.
+func (r *ValuesXmlResource) validate(ctx context.Context, data *ValuesXmlResourceModel, diags *diag.Diagnostics) {
+	ext := filepath.Ext(data.Path.ValueString())
+	if ext != ".xml" {
+		diags.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The file extension %q is not valid, must be .xml", ext),
+		)
+		return
+	}
+
+	if err := validators.ValidateXML(data.Content.ValueString()); err != nil {
+		diags.AddError(
+			"Invalid XML content",
+			fmt.Sprintf("The content is not valid XML: %v", err),
+		)
+		return
+	}
+
+	schema := data.XSD.ValueString()
+	if !data.XSDPath.IsNull() {
+		var err error
+		schema, err = r.client.GetContent(ctx, data.XSDPath.ValueString(), data.Branch.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Failed to read XML schema",
+				fmt.Sprintf(
+					"An error occurred while reading %q in branch %q: %v",
+					data.XSDPath.ValueString(),
+					data.Branch.ValueString(),
+					err,
+				),
+			)
+			return
+		}
+	}
+	if schema == "" {
+		return
+	}
+
+	if err := validators.ValidateXMLSchema(data.Content.ValueString(), schema); err != nil {
+		diags.AddError(
+			"Invalid XML content",
+			fmt.Sprintf("The content does not match the XML schema: %v", err),
+		)
+	}
+}
@@ -6,21 +6,31 @@ package resource
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
 
+	"terraform-provider-gitsync/internal/customtypes"
 	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/merge"
+	"terraform-provider-gitsync/internal/policy"
+	"terraform-provider-gitsync/internal/serialize"
 	"terraform-provider-gitsync/internal/validators"
+	"terraform-provider-gitsync/internal/yamledit"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
 )
 
 var _ resource.Resource = &ValuesYamlResource{}
 var _ resource.ResourceWithImportState = &ValuesYamlResource{}
+var _ resource.ResourceWithValidateConfig = &ValuesYamlResource{}
+var _ resource.ResourceWithModifyPlan = &ValuesYamlResource{}
 
 func NewValueYamlResource() resource.Resource {
 	return &ValuesYamlResource{}
@@ -28,13 +38,30 @@ func NewValueYamlResource() resource.Resource {
 
 type ValuesYamlResource struct {
 	client git.Client
+	policy *policy.Policy
 }
 
 type ValuesYamlResourceModel struct {
-	ID      types.String `tfsdk:"id"`
-	Path    types.String `tfsdk:"path"`
-	Branch  types.String `tfsdk:"branch"`
-	Content types.String `tfsdk:"content"`
+	ID        types.String     `tfsdk:"id"`
+	Path      types.String     `tfsdk:"path"`
+	Branch    types.String     `tfsdk:"branch"`
+	Content   customtypes.YAML `tfsdk:"content"`
+	Values    types.Dynamic    `tfsdk:"values"`
+	Documents types.List       `tfsdk:"documents"`
+
+	Schema     types.String `tfsdk:"schema"`
+	SchemaPath types.String `tfsdk:"schema_path"`
+
+	ChartPath   types.String `tfsdk:"chart_path"`
+	DetectChart types.Bool   `tfsdk:"detect_chart"`
+
+	OnConflict           types.String `tfsdk:"on_conflict"`
+	Format               types.Object `tfsdk:"format"`
+	KubernetesValidation types.Object `tfsdk:"kubernetes_validation"`
+	Policy               types.Object `tfsdk:"policy"`
+	WaitForChecks        types.Object `tfsdk:"wait_for_checks"`
+
+	CommitModel
 }
 
 func (r *ValuesYamlResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -59,32 +86,115 @@ func (r *ValuesYamlResource) Schema(ctx context.Context, req resource.SchemaRequ
 				Optional:            true,
 			},
 			"content": schema.StringAttribute{
-				MarkdownDescription: "File content to write.",
-				Required:            true,
+				MarkdownDescription: "File content to write. Changes that keep the parsed content the same, like key order or indentation, are not reported as a difference. Set to the rendered file when `values` is used.",
+				CustomType:          customtypes.YAMLType{},
+				Optional:            true,
+				Computed:            true,
 			},
+			"values": valuesAttribute(),
+			"documents": schema.ListAttribute{
+				MarkdownDescription: "Content of every document in the file, without the `---` and `...` markers. Use `length()` to get the number of documents.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"on_conflict": onConflictAttribute(onConflictMergeModes),
+		},
+		Blocks: map[string]schema.Block{
+			"format":                formatBlock(true),
+			"kubernetes_validation": kubernetesValidationBlock(),
+			"policy":                policyBlock(),
+			"wait_for_checks":       waitForChecksBlock(),
 		},
 	}
+	maps.Copy(resp.Schema.Attributes, jsonSchemaAttributes())
+	maps.Copy(resp.Schema.Attributes, helmChartAttributes())
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
 }
 
 func (r *ValuesYamlResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
-	c, ok := req.ProviderData.(git.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Provider Data",
-			fmt.Sprintf("Expected *git.Client, got %T", req.ProviderData),
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
 		)
 		return
 	}
-	r.client = c
+	r.client = data.Client
+	r.policy = data.Policy
+}
+
+func (r *ValuesYamlResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data ValuesYamlResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	validateWaitForChecks(ctx, data.WaitForChecks, &resp.Diagnostics)
+
+	validateContentOrValues(data.Content.StringValue, data.Values, &resp.Diagnostics)
+	validateJSONSchemaConfig(data.Schema, data.SchemaPath, &resp.Diagnostics)
+	validateHelmChartConfig(data.ChartPath, data.DetectChart, &resp.Diagnostics)
+}
+
+// ModifyPlan validates the planned content against the JSON schema, the
+// schema of the Helm chart, the Kubernetes schemas and the policies, so a
+// mismatch is reported before anything is committed.
+func (r *ValuesYamlResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var data ValuesYamlResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	schemaKnown := jsonSchemaKnown(data.Schema, data.SchemaPath)
+	chartKnown := helmChartKnown(data.ChartPath, data.DetectChart)
+	kubernetesKnown := kubernetesValidationKnown(ctx, data.KubernetesValidation)
+	policyUsed := r.policy != nil || policyKnown(ctx, data.Policy)
+	if resp.Diagnostics.HasError() || (!schemaKnown && !chartKnown && !kubernetesKnown && !policyUsed) || data.Path.IsUnknown() ||
+		data.Branch.IsUnknown() || data.Values.IsUnknown() || data.Values.IsUnderlyingValueUnknown() {
+		return
+	}
+
+	content := data.Content.ValueString()
+	if !data.Values.IsNull() {
+		var err error
+		if content, err = renderValues(ctx, data.Values, data.Format, serialize.YAML); err != nil {
+			// Reported by Create and Update, or unknown until apply.
+			return
+		}
+	} else if data.Content.IsUnknown() {
+		return
+	}
+
+	documents, err := yamlValues(content)
+	if err != nil {
+		return
+	}
+	if schemaKnown {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), documents, true, &resp.Diagnostics)
+	}
+	if chartKnown {
+		validateHelmChart(ctx, r.client, data.ChartPath, data.DetectChart, data.Path.ValueString(), data.Branch.ValueString(), documents, &resp.Diagnostics)
+	}
+	if kubernetesKnown {
+		validateKubernetesManifests(ctx, data.KubernetesValidation, documents, &resp.Diagnostics)
+	}
+	if policyUsed {
+		evaluatePolicies(ctx, r.policy, data.Policy, documents, true, &resp.Diagnostics)
+	}
 }
 
 func (r *ValuesYamlResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data ValuesYamlResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -102,6 +212,18 @@ func (r *ValuesYamlResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
+	if !data.Values.IsNull() {
+		content, err := renderValues(ctx, data.Values, data.Format, serialize.YAML)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid values",
+				fmt.Sprintf("The values cannot be serialized as YAML: %v", err),
+			)
+			return
+		}
+		data.Content = customtypes.NewYAMLValue(content)
+	}
+
 	if err := validators.ValidateYAML(data.Content.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid YAML content",
@@ -110,11 +232,24 @@ func (r *ValuesYamlResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	err := r.client.Create(ctx, git.ValuesModel{
+	if documents, err := yamlValues(data.Content.ValueString()); err == nil {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), documents, false, &resp.Diagnostics)
+		validateHelmChart(ctx, r.client, data.ChartPath, data.DetectChart, data.Path.ValueString(), data.Branch.ValueString(), documents, &resp.Diagnostics)
+		validateKubernetesManifests(ctx, data.KubernetesValidation, documents, &resp.Diagnostics)
+		evaluatePolicies(ctx, r.policy, data.Policy, documents, false, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	commit, err := r.client.Create(ctx, git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
+		Content: []byte(data.Content.ValueString()),
 	})
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to create file",
@@ -129,7 +264,22 @@ func (r *ValuesYamlResource) Create(ctx context.Context, req resource.CreateRequ
 	}
 
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.Documents = yamlDocuments(data.Content.ValueString())
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), data.Content.ValueString()), &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesYamlResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -141,6 +291,10 @@ func (r *ValuesYamlResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	cnt, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to read file",
@@ -154,15 +308,44 @@ func (r *ValuesYamlResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read commit",
+			fmt.Sprintf(
+				"An error occurred while reading the last commit of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	applied := recordApplied(ctx, resp.Private, data.BlobSHA, commit, &resp.Diagnostics)
+	cnt = appliedContent(applied, commit, cnt)
 	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
-	data.Content = types.StringValue(cnt)
+	data.Content = customtypes.NewYAMLValue(cnt)
+	data.Documents = yamlDocuments(cnt)
+	if !data.Values.IsNull() {
+		// Content that cannot be parsed any more shows up as a change of values.
+		data.Values, _ = parseValues(ctx, cnt, serialize.ParseYAML, data.Values)
+	}
+	data.setCommit(commit)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *ValuesYamlResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data ValuesYamlResourceModel
+	var data, state ValuesYamlResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
+	validateOnConflict(data.OnConflict, onConflictMergeModes, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -176,6 +359,18 @@ func (r *ValuesYamlResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	if !data.Values.IsNull() {
+		content, err := renderValues(ctx, data.Values, data.Format, serialize.YAML)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid values",
+				fmt.Sprintf("The values cannot be serialized as YAML: %v", err),
+			)
+			return
+		}
+		data.Content = customtypes.NewYAMLValue(content)
+	}
+
 	if err := validators.ValidateYAML(data.Content.ValueString()); err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid YAML content",
@@ -184,11 +379,30 @@ func (r *ValuesYamlResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
-	err := r.client.Update(ctx, git.ValuesModel{
+	if documents, err := yamlValues(data.Content.ValueString()); err == nil {
+		validateJSONSchema(ctx, r.client, data.Schema, data.SchemaPath, data.Branch.ValueString(), documents, false, &resp.Diagnostics)
+		validateHelmChart(ctx, r.client, data.ChartPath, data.DetectChart, data.Path.ValueString(), data.Branch.ValueString(), documents, &resp.Diagnostics)
+		validateKubernetesManifests(ctx, data.KubernetesValidation, documents, &resp.Diagnostics)
+		evaluatePolicies(ctx, r.policy, data.Policy, documents, false, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	model := git.ValuesModel{
 		Path:    data.Path.ValueString(),
 		Branch:  data.Branch.ValueString(),
-		Content: data.Content.ValueString(),
-	})
+		Content: []byte(data.Content.ValueString()),
+		SHA:     expectedSHA(data.OnConflict, applied, state.BlobSHA),
+	}
+
+	commit, committed, err := updateFile(ctx, r.client, model, data.OnConflict, applied, state.Content.ValueString(), merge.YAML)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to update file",
@@ -202,19 +416,43 @@ func (r *ValuesYamlResource) Update(ctx context.Context, req resource.UpdateRequ
 		return
 	}
 
+	data.Documents = yamlDocuments(data.Content.ValueString())
+	data.setCommit(commit)
+	setApplied(ctx, resp.Private, newAppliedFile(commit, data.Content.ValueString(), committed), &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+
+	if err := waitForChecks(ctx, r.client, commit.SHA, data.WaitForChecks); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to wait for checks",
+			fmt.Sprintf(
+				"The checks for commit %s on branch %q did not pass: %v",
+				commit.SHA,
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+	}
 }
 
 func (r *ValuesYamlResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data ValuesYamlResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	applied := getApplied(ctx, req.Private, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	err := r.client.Delete(ctx, data.Path.ValueString(), data.Branch.ValueString())
-	if err != nil {
+	err := r.client.Delete(
+		ctx,
+		data.Path.ValueString(),
+		data.Branch.ValueString(),
+		expectedSHA(data.OnConflict, applied, data.BlobSHA),
+	)
+	if addConflictError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil && !git.IsNotFound(err) {
 		resp.Diagnostics.AddError(
 			"Failed to delete file",
 			fmt.Sprintf(
@@ -268,9 +506,51 @@ func (r *ValuesYamlResource) ImportState(ctx context.Context, req resource.Impor
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &ValuesYamlResourceModel{
-		ID:      types.StringValue(r.client.GetID(branch, path)),
-		Path:    types.StringValue(path),
-		Branch:  types.StringValue(branch),
-		Content: types.StringValue(content),
+		ID:        types.StringValue(r.client.GetID(branch, path)),
+		Path:      types.StringValue(path),
+		Branch:    types.StringValue(branch),
+		Content:   customtypes.NewYAMLValue(content),
+		Values:    types.DynamicNull(),
+		Documents: yamlDocuments(content),
+
+		Format:               types.ObjectNull(formatAttrTypes(true)),
+		KubernetesValidation: types.ObjectNull(kubernetesValidationAttrTypes()),
+		Policy:               types.ObjectNull(policyAttrTypes()),
+		WaitForChecks:        types.ObjectNull(waitForChecksAttrTypes()),
 	})...)
 }
+
+// yamlDocuments splits content into its documents. Content without any
+// document, like a file of comments, has no documents.
+func yamlDocuments(content string) types.List {
+	docs := []attr.Value{}
+	for _, doc := range yamledit.SplitDocuments(content) {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(doc), &node); err != nil || node.Kind == 0 {
+			continue
+		}
+		docs = append(docs, types.StringValue(yamledit.DocumentBody(doc)))
+	}
+	return types.ListValueMust(types.StringType, docs)
+}
+
+// yamlValues parses every document of content for validation against a JSON
+// schema. Documents without content are skipped.
+func yamlValues(content string) ([]any, error) {
+	var values []any
+	for _, doc := range yamledit.SplitDocuments(content) {
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+			return nil, err
+		}
+		if node.Kind == 0 {
+			continue
+		}
+		value, err := serialize.ParseYAML(doc)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,530 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"terraform-provider-gitsync/internal/git"
+	"terraform-provider-gitsync/internal/serialize"
+	"terraform-provider-gitsync/internal/yamledit"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+var _ resource.Resource = &YamlKeysResource{}
+var _ resource.ResourceWithImportState = &YamlKeysResource{}
+
+const (
+	onDestroyRemove  = "remove"
+	onDestroyRestore = "restore"
+)
+
+func NewYamlKeysResource() resource.Resource {
+	return &YamlKeysResource{}
+}
+
+type YamlKeysResource struct {
+	client git.Client
+}
+
+type YamlKeysResourceModel struct {
+	ID             types.String  `tfsdk:"id"`
+	Path           types.String  `tfsdk:"path"`
+	Branch         types.String  `tfsdk:"branch"`
+	Keys           types.Dynamic `tfsdk:"keys"`
+	OnDestroy      types.String  `tfsdk:"on_destroy"`
+	OriginalValues types.Map     `tfsdk:"original_values"`
+
+	CommitModel
+}
+
+func (r *YamlKeysResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_yaml_keys"
+}
+
+func (r *YamlKeysResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages single keys of an existing yaml file in a Git repository. " +
+			"All other keys, comments and the formatting of the file are kept. " +
+			"Import with `branch:path:keys` or `path:keys`, where keys is a comma separated list of key paths. " +
+			"The values the keys have on import are their original values.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+				MarkdownDescription: "Unique ID.",
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Relative path of the file in the repo.",
+				Required:            true,
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"branch": schema.StringAttribute{
+				MarkdownDescription: "Branch to commit to. Defaults to the main branch.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultBranch),
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"keys": schema.DynamicAttribute{
+				MarkdownDescription: "Values to set, keyed by dot separated key paths like `image.tag`. " +
+					"Dots that are part of a key are escaped with a backslash, like `\"labels.app\\\\.kubernetes\\\\.io/name\"` in HCL. " +
+					"Missing parent mappings are created.",
+				Required: true,
+			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: "What to do with the keys on destroy or when they are removed from `keys`. " +
+					"`remove` deletes them, `restore` puts back the values they had before they were managed. Defaults to `remove`.",
+				Optional: true,
+			},
+			"original_values": schema.MapAttribute{
+				MarkdownDescription: "YAML of the values the keys had before they were managed, keyed by key path. Keys that did not exist are left out.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+	maps.Copy(resp.Schema.Attributes, commitAttributes())
+}
+
+func (r *YamlKeysResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	data, ok := req.ProviderData.(*ProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Provider Data",
+			fmt.Sprintf("Expected *resource.ProviderData, got %T", req.ProviderData),
+		)
+		return
+	}
+	r.client = data.Client
+}
+
+func (r *YamlKeysResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data YamlKeysResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := validateYamlKeysModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	originals := map[string]string{}
+	commit, err := r.patch(ctx, &data, keys, nil, originals)
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update keys",
+			fmt.Sprintf(
+				"An error occurred while updating keys of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(r.client.GetID(data.Branch.ValueString(), data.Path.ValueString()))
+	data.OriginalValues = originalValues(originals)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YamlKeysResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data YamlKeysResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	doc, commit, err := r.load(ctx, &data)
+	if git.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	// Keys that were removed remotely are left out, so they are planned to be
+	// set again.
+	current := map[string]any{}
+	for key := range stateKeys(ctx, data.Keys) {
+		if value, ok := nodeValue(doc, key); ok {
+			current[key] = value
+		}
+	}
+
+	data.Keys = types.DynamicValue(goToValuesLike(ctx, current, dynamicType(ctx, data.Keys)))
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YamlKeysResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state YamlKeysResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := validateYamlKeysModel(ctx, &data, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	originals := map[string]string{}
+	resp.Diagnostics.Append(state.OriginalValues.ElementsAs(ctx, &originals, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var removed []string
+	for key := range stateKeys(ctx, state.Keys) {
+		if _, ok := keys[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	commit, err := r.patch(ctx, &data, keys, removed, originals)
+	if addFileTooLargeError(&resp.Diagnostics, err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update keys",
+			fmt.Sprintf(
+				"An error occurred while updating keys of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+
+	for _, key := range removed {
+		delete(originals, key)
+	}
+	data.OriginalValues = originalValues(originals)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *YamlKeysResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data YamlKeysResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	originals := map[string]string{}
+	resp.Diagnostics.Append(data.OriginalValues.ElementsAs(ctx, &originals, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	removed := slices.Collect(maps.Keys(stateKeys(ctx, data.Keys)))
+	_, err := r.patch(ctx, &data, nil, removed, originals)
+	if err != nil && !git.IsNotFound(err) {
+		resp.Diagnostics.AddError(
+			"Failed to delete keys",
+			fmt.Sprintf(
+				"An error occurred while deleting keys of %q in branch %q: %v",
+				data.Path.ValueString(),
+				data.Branch.ValueString(),
+				err,
+			),
+		)
+		return
+	}
+}
+
+func (r *YamlKeysResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var branch, path, keyList string
+
+	parts := strings.SplitN(req.ID, ":", 3)
+	switch len(parts) {
+	case 3:
+		branch, path, keyList = parts[0], parts[1], parts[2]
+	case 2:
+		path, keyList = parts[0], parts[1]
+	}
+
+	if branch == "" {
+		branch = defaultBranch
+	}
+
+	if path == "" || keyList == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be in format 'branch:path:keys' or 'path:keys', with keys as comma separated key paths",
+		)
+		return
+	}
+
+	data := YamlKeysResourceModel{
+		ID:        types.StringValue(r.client.GetID(branch, path)),
+		Path:      types.StringValue(path),
+		Branch:    types.StringValue(branch),
+		OnDestroy: types.StringNull(),
+	}
+
+	doc, commit, err := r.load(ctx, &data)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to read file during import",
+			fmt.Sprintf(
+				"An error occurred while reading %q in branch %q: %v",
+				path,
+				branch,
+				err,
+			),
+		)
+		return
+	}
+
+	keys := map[string]any{}
+	for _, key := range strings.Split(keyList, ",") {
+		value, ok := nodeValue(doc, key)
+		if !ok {
+			resp.Diagnostics.AddError(
+				"Key not found",
+				fmt.Sprintf("The key path %q does not exist in %q in branch %q", key, path, branch),
+			)
+			continue
+		}
+		keys[key] = value
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	originals := map[string]string{}
+	captureOriginals(doc, keys, originals)
+
+	data.Keys = types.DynamicValue(goToValues(ctx, keys))
+	data.OriginalValues = originalValues(originals)
+	data.setCommit(commit)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// load reads the file together with its last commit. The commit is read
+// first, so a change in between fails the write instead of being overwritten.
+func (r *YamlKeysResource) load(ctx context.Context, data *YamlKeysResourceModel) (*yamledit.Document, *git.Commit, error) {
+	commit, err := r.client.GetCommit(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := r.client.GetContent(ctx, data.Path.ValueString(), data.Branch.ValueString())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc, err := yamledit.Parse(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, commit, nil
+}
+
+// patch sets keys, reverts the removed keys according to on_destroy and
+// commits the result. The original values of keys that are set for the first
+// time are added to originals. Nothing is committed when the content did not
+// change, and the patch is applied again when the file changed concurrently.
+func (r *YamlKeysResource) patch(
+	ctx context.Context,
+	data *YamlKeysResourceModel,
+	keys map[string]any,
+	removed []string,
+	originals map[string]string,
+) (*git.Commit, error) {
+	operation := func() (*git.Commit, error) {
+		doc, commit, err := r.load(ctx, data)
+		if err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		captureOriginals(doc, keys, originals)
+
+		before := doc.String()
+		if err := patchDocument(doc, data.OnDestroy.ValueString(), keys, removed, originals); err != nil {
+			return nil, backoff.Permanent(err)
+		}
+		if doc.String() == before {
+			return commit, nil
+		}
+
+		commit, err = r.client.Update(ctx, git.ValuesModel{
+			Path:    data.Path.ValueString(),
+			Branch:  data.Branch.ValueString(),
+			Content: []byte(doc.String()),
+			SHA:     commit.BlobSHA,
+		})
+		var conflict *git.ConflictError
+		if err != nil && !errors.As(err, &conflict) {
+			return nil, backoff.Permanent(err)
+		}
+		return commit, err
+	}
+
+	return backoff.Retry(ctx, operation, backoff.WithMaxTries(5))
+}
+
+func patchDocument(doc *yamledit.Document, onDestroy string, keys map[string]any, removed []string, originals map[string]string) error {
+	for _, key := range slices.Sorted(slices.Values(removed)) {
+		original, ok := originals[key]
+		if onDestroy != onDestroyRestore || !ok {
+			if _, err := doc.Delete(yamledit.SplitPath(key)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(original), &node); err != nil {
+			return fmt.Errorf("invalid original value of %s: %w", key, err)
+		}
+		if err := doc.Set(yamledit.SplitPath(key), node.Content[0]); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		node, err := serialize.YAMLNode(keys[key], serialize.Options{})
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if err := doc.Set(yamledit.SplitPath(key), node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateYamlKeysModel(ctx context.Context, data *YamlKeysResourceModel, diags *diag.Diagnostics) map[string]any {
+	ext := filepath.Ext(data.Path.ValueString())
+	if ext != ".yaml" && ext != ".yml" {
+		diags.AddError(
+			"Invalid file extension",
+			fmt.Sprintf("The file extension %q is not valid, must be .yaml or .yml", ext),
+		)
+	}
+
+	onDestroy := []string{onDestroyRemove, onDestroyRestore}
+	if !data.OnDestroy.IsNull() && !slices.Contains(onDestroy, data.OnDestroy.ValueString()) {
+		diags.AddError(
+			"Invalid on_destroy value",
+			fmt.Sprintf("The value %q is not valid, must be one of %q", data.OnDestroy.ValueString(), onDestroy),
+		)
+	}
+
+	value, err := valuesToGo(ctx, data.Keys)
+	keys, ok := value.(map[string]any)
+	if err != nil || !ok {
+		diags.AddError(
+			"Invalid keys",
+			"The keys must be an object of key paths and their values",
+		)
+		return nil
+	}
+
+	for key := range keys {
+		if slices.Contains(yamledit.SplitPath(key), "") {
+			diags.AddError(
+				"Invalid keys",
+				fmt.Sprintf("The key path %q contains an empty key", key),
+			)
+		}
+	}
+	return keys
+}
+
+// captureOriginals records the current YAML of every key in keys that exists
+// in the document and is not recorded yet.
+func captureOriginals(doc *yamledit.Document, keys map[string]any, originals map[string]string) {
+	for key := range keys {
+		if _, ok := originals[key]; ok {
+			continue
+		}
+		node, ok := doc.Get(yamledit.SplitPath(key))
+		if !ok {
+			continue
+		}
+		if out, err := yaml.Marshal(node); err == nil {
+			originals[key] = string(out)
+		}
+	}
+}
+
+// stateKeys returns the keys recorded in state.
+func stateKeys(ctx context.Context, value types.Dynamic) map[string]any {
+	keys, err := valuesToGo(ctx, value)
+	if err != nil {
+		return nil
+	}
+	m, _ := keys.(map[string]any)
+	return m
+}
+
+func nodeValue(doc *yamledit.Document, key string) (any, bool) {
+	node, ok := doc.Get(yamledit.SplitPath(key))
+	if !ok {
+		return nil, false
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, false
+	}
+	value, err := serialize.ParseYAML(string(out))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func originalValues(originals map[string]string) types.Map {
+	elems := make(map[string]attr.Value, len(originals))
+	for key, value := range originals {
+		elems[key] = types.StringValue(value)
+	}
+	return types.MapValueMust(types.StringType, elems)
+}
@@ -0,0 +1,266 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package serialize renders structured values as YAML or JSON documents with
+// a deterministic layout and parses documents back into the same structure.
+//
+// Values are built from map[string]any, []any, string, bool, json.Number and
+// nil.
+package serialize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultIndent = 2
+
+type Options struct {
+	// Indent is the number of spaces per nesting level. Defaults to 2.
+	Indent int
+	// KeyOrder lists keys that are written first, in the given order, at every
+	// level. All other keys are sorted alphabetically.
+	KeyOrder []string
+	// DocumentStart prefixes YAML documents with "---".
+	DocumentStart bool
+	// TrailingNewline ends the document with a newline.
+	TrailingNewline bool
+}
+
+func (o Options) indent() int {
+	if o.Indent <= 0 {
+		return defaultIndent
+	}
+	return o.Indent
+}
+
+// YAML renders value as a YAML document.
+func YAML(value any, opts Options) (string, error) {
+	node, err := YAMLNode(value, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if opts.DocumentStart {
+		buf.WriteString("---\n")
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(opts.indent())
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return finish(buf.String(), opts), nil
+}
+
+// JSON renders value as a JSON document.
+func JSON(value any, opts Options) (string, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, value, opts, 0); err != nil {
+		return "", err
+	}
+	return finish(buf.String(), opts), nil
+}
+
+// ParseYAML parses the first document of content.
+func ParseYAML(content string) (any, error) {
+	var value any
+	if err := yaml.Unmarshal([]byte(content), &value); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML content: %w", err)
+	}
+	return normalize(value), nil
+}
+
+// ParseJSON parses content, keeping numbers as json.Number.
+func ParseJSON(content string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON content: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("failed to parse JSON content: unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+func finish(content string, opts Options) string {
+	content = strings.TrimRight(content, "\n")
+	if opts.TrailingNewline {
+		content += "\n"
+	}
+	return content
+}
+
+func orderedKeys(m map[string]any, keyOrder []string) []string {
+	keys := make([]string, 0, len(m))
+	for _, k := range keyOrder {
+		if _, ok := m[k]; ok && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	var rest []string
+	for k := range m {
+		if !slices.Contains(keys, k) {
+			rest = append(rest, k)
+		}
+	}
+	slices.Sort(rest)
+
+	return append(keys, rest...)
+}
+
+// YAMLNode builds the YAML node of value.
+func YAMLNode(value any, opts Options) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range orderedKeys(v, opts.KeyOrder) {
+			child, err := YAMLNode(v[k], opts)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, child)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range v {
+			child, err := YAMLNode(e, opts)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case string:
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+		if strings.Contains(v, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}, nil
+	case json.Number:
+		tag := "!!float"
+		if _, err := v.Int64(); err == nil {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, value any, opts Options, depth int) error {
+	pad := strings.Repeat(" ", opts.indent()*(depth+1))
+	end := strings.Repeat(" ", opts.indent()*depth)
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i, k := range orderedKeys(v, opts.KeyOrder) {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(pad)
+			if err := writeJSONScalar(buf, k); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := writeJSON(buf, v[k], opts, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + end + "}")
+		return nil
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			buf.WriteString(pad)
+			if err := writeJSON(buf, e, opts, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + end + "]")
+		return nil
+	case string, bool, json.Number, nil:
+		return writeJSONScalar(buf, v)
+	default:
+		return fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func writeJSONScalar(buf *bytes.Buffer, value any) error {
+	var scalar bytes.Buffer
+	enc := json.NewEncoder(&scalar)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(scalar.Bytes(), []byte("\n")))
+	return nil
+}
+
+// normalize converts decoded YAML into the value types of this package.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
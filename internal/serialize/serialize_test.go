@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package serialize
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testValue() map[string]any {
+	return map[string]any{
+		"replicas": json.Number("3"),
+		"image": map[string]any{
+			"tag":        "v1.2.0",
+			"repository": "ghcr.io/example/app",
+		},
+		"enabled": true,
+		"ratio":   json.Number("0.5"),
+		"version": "1.10",
+		"hosts":   []any{"a.example.com", "b.example.com"},
+		"extra":   nil,
+		"empty":   map[string]any{},
+	}
+}
+
+func TestYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		opts     Options
+		expected string
+	}{
+		{
+			name:  "sorted keys",
+			value: testValue(),
+			opts:  Options{TrailingNewline: true},
+			expected: `empty: {}
+enabled: true
+extra: null
+hosts:
+  - a.example.com
+  - b.example.com
+image:
+  repository: ghcr.io/example/app
+  tag: v1.2.0
+ratio: 0.5
+replicas: 3
+version: "1.10"
+`,
+		},
+		{
+			name:  "key order, indent and document start",
+			value: map[string]any{"b": map[string]any{"y": "1", "x": json.Number("1")}, "a": "text"},
+			opts:  Options{Indent: 4, KeyOrder: []string{"b", "y"}, DocumentStart: true},
+			expected: `---
+b:
+    y: "1"
+    x: 1
+a: text`,
+		},
+		{
+			name:     "multi-line string",
+			value:    map[string]any{"script": "echo a\necho b\n"},
+			opts:     Options{TrailingNewline: true},
+			expected: "script: |\n  echo a\n  echo b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := YAML(tt.value, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		opts     Options
+		expected string
+	}{
+		{
+			name:  "sorted keys",
+			value: testValue(),
+			opts:  Options{TrailingNewline: true},
+			expected: `{
+  "empty": {},
+  "enabled": true,
+  "extra": null,
+  "hosts": [
+    "a.example.com",
+    "b.example.com"
+  ],
+  "image": {
+    "repository": "ghcr.io/example/app",
+    "tag": "v1.2.0"
+  },
+  "ratio": 0.5,
+  "replicas": 3,
+  "version": "1.10"
+}
+`,
+		},
+		{
+			name:     "key order and indent",
+			value:    map[string]any{"b": "<tag>", "a": []any{}},
+			opts:     Options{Indent: 4, KeyOrder: []string{"b"}},
+			expected: "{\n    \"b\": \"<tag>\",\n    \"a\": []\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := JSON(tt.value, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	yamlContent, err := YAML(testValue(), Options{})
+	require.NoError(t, err)
+	parsed, err := ParseYAML(yamlContent)
+	require.NoError(t, err)
+	assert.Equal(t, any(testValue()), parsed)
+
+	jsonContent, err := JSON(testValue(), Options{})
+	require.NoError(t, err)
+	parsed, err = ParseJSON(jsonContent)
+	require.NoError(t, err)
+	assert.Equal(t, any(testValue()), parsed)
+}
+
+func TestParseJSONTrailingData(t *testing.T) {
+	_, err := ParseJSON(`{"a": 1} {"b": 2}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse JSON")
+}
@@ -4,23 +4,63 @@
 package validators
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
+	"terraform-provider-gitsync/internal/jsonc"
+	"terraform-provider-gitsync/internal/keyvalue"
+	"terraform-provider-gitsync/internal/kubernetes"
+	"terraform-provider-gitsync/internal/xsd"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 	"gopkg.in/yaml.v3"
 )
 
 type ValidationError struct {
 	Type    string
 	Message string
+	// Document is the 1-based index of the failing document in a YAML
+	// stream, or 0 when the error is not about a single document.
+	Document int
+	// Line and Column locate the error in the content, or are 0 when the
+	// parser does not report them.
+	Line   int
+	Column int
+	// Pointer is the JSON pointer of the value that does not match a JSON
+	// Schema, or empty for the whole document.
+	Pointer string
 	Err     error
 }
 
 func (e *ValidationError) Error() string {
+	message := e.Message
+	if e.Document > 0 {
+		message = fmt.Sprintf("%s in document %d", message, e.Document)
+	}
+	if e.Line > 0 && e.Column > 0 {
+		message = fmt.Sprintf("%s at line %d, column %d", message, e.Line, e.Column)
+	} else if e.Line > 0 {
+		message = fmt.Sprintf("%s at line %d", message, e.Line)
+	}
+	if e.Pointer != "" {
+		message = fmt.Sprintf("%s at %s", message, e.Pointer)
+	}
 	if e.Err != nil {
-		return fmt.Sprintf("invalid %s: %s: %v", e.Type, e.Message, e.Err)
+		return fmt.Sprintf("invalid %s: %s: %v", e.Type, message, e.Err)
 	}
-	return fmt.Sprintf("invalid %s: %s", e.Type, e.Message)
+	return fmt.Sprintf("invalid %s: %s", e.Type, message)
 }
 
 func ValidateYAML(content string) error {
@@ -31,16 +71,24 @@ func ValidateYAML(content string) error {
 		}
 	}
 
-	var data interface{}
-	if err := yaml.Unmarshal([]byte(content), &data); err != nil {
-		return &ValidationError{
-			Type:    "yaml",
-			Message: "failed to parse YAML content",
-			Err:     err,
+	// Every document of the stream is decoded. Line numbers in the errors
+	// count from the start of the content.
+	dec := yaml.NewDecoder(strings.NewReader(content))
+	for document := 1; ; document++ {
+		var data interface{}
+		err := dec.Decode(&data)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return &ValidationError{
+				Type:     "yaml",
+				Message:  "failed to parse YAML content",
+				Document: document,
+				Err:      err,
+			}
 		}
 	}
-
-	return nil
 }
 
 func ValidateJSON(content string) error {
@@ -62,3 +110,420 @@ func ValidateJSON(content string) error {
 
 	return nil
 }
+
+// ValidateJSONC validates JSON that may contain comments and trailing commas,
+// as used by .jsonc files.
+func ValidateJSONC(content string) error {
+	if content == "" {
+		return &ValidationError{
+			Type:    "jsonc",
+			Message: "content cannot be empty",
+		}
+	}
+
+	plain, err := jsonc.ToJSON([]byte(content))
+	if err != nil {
+		return &ValidationError{
+			Type:    "jsonc",
+			Message: "failed to parse JSONC content",
+			Err:     err,
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(plain, &data); err != nil {
+		return &ValidationError{
+			Type:    "jsonc",
+			Message: "failed to parse JSONC content",
+			Err:     err,
+		}
+	}
+
+	return nil
+}
+
+func ValidateTOML(content string) error {
+	if content == "" {
+		return &ValidationError{
+			Type:    "toml",
+			Message: "content cannot be empty",
+		}
+	}
+
+	var data map[string]interface{}
+	if err := toml.Unmarshal([]byte(content), &data); err != nil {
+		validationErr := &ValidationError{
+			Type:    "toml",
+			Message: "failed to parse TOML content",
+			Err:     err,
+		}
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			validationErr.Line, validationErr.Column = decodeErr.Position()
+		}
+		return validationErr
+	}
+
+	return nil
+}
+
+// ValidateHCL validates HCL2 native syntax. With attributesOnly set, the
+// content must consist of attribute assignments only, like a .tfvars file.
+func ValidateHCL(content string, attributesOnly bool) error {
+	if content == "" {
+		return &ValidationError{
+			Type:    "hcl",
+			Message: "content cannot be empty",
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte(content), "", hcl.InitialPos)
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		validationErr := &ValidationError{
+			Type:    "hcl",
+			Message: "failed to parse HCL content",
+			Err:     fmt.Errorf("%s: %s", diag.Summary, diag.Detail),
+		}
+		if diag.Subject != nil {
+			validationErr.Line, validationErr.Column = diag.Subject.Start.Line, diag.Subject.Start.Column
+		}
+		return validationErr
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return &ValidationError{
+			Type:    "hcl",
+			Message: fmt.Sprintf("unexpected HCL body of type %T", file.Body),
+		}
+	}
+	if attributesOnly && len(body.Blocks) > 0 {
+		block := body.Blocks[0]
+		return &ValidationError{
+			Type:    "hcl",
+			Message: fmt.Sprintf("only attributes are allowed, found a %q block", block.Type),
+			Line:    block.TypeRange.Start.Line,
+			Column:  block.TypeRange.Start.Column,
+		}
+	}
+
+	return nil
+}
+
+// ValidateKeyValue validates a dotenv, INI or Java properties file. Duplicate
+// keys and invalid escape sequences are reported as errors.
+func ValidateKeyValue(content string, format keyvalue.Format) error {
+	if content == "" {
+		return &ValidationError{
+			Type:    string(format),
+			Message: "content cannot be empty",
+		}
+	}
+
+	if _, err := keyvalue.Parse(format, content); err != nil {
+		validationErr := &ValidationError{
+			Type:    string(format),
+			Message: fmt.Sprintf("failed to parse %s content", format),
+			Err:     err,
+		}
+		var parseErr *keyvalue.ParseError
+		if errors.As(err, &parseErr) {
+			validationErr.Line, validationErr.Column = parseErr.Line, parseErr.Column
+			validationErr.Err = errors.New(parseErr.Message)
+		}
+		return validationErr
+	}
+
+	return nil
+}
+
+// ValidateXML checks that the content is well-formed XML with a single root
+// element.
+func ValidateXML(content string) error {
+	if content == "" {
+		return &ValidationError{
+			Type:    "xml",
+			Message: "content cannot be empty",
+		}
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(content))
+	// The content is already UTF-8, whatever encoding the declaration names.
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	depth, roots := 0, 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		line, column := dec.InputPos()
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				err = errors.New(syntaxErr.Msg)
+			}
+			return &ValidationError{
+				Type:    "xml",
+				Message: "failed to parse XML content",
+				Line:    line,
+				Column:  column,
+				Err:     err,
+			}
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				roots++
+			}
+			if roots > 1 {
+				return &ValidationError{
+					Type:    "xml",
+					Message: "only one root element is allowed",
+					Line:    line,
+					Column:  column,
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(t)) > 0 {
+				return &ValidationError{
+					Type:    "xml",
+					Message: "text is not allowed outside of the root element",
+					Line:    line,
+					Column:  column,
+				}
+			}
+		}
+	}
+
+	if roots == 0 {
+		return &ValidationError{
+			Type:    "xml",
+			Message: "no root element",
+		}
+	}
+	return nil
+}
+
+// ValidateXMLSchema validates well-formed XML content against an XML Schema
+// (XSD).
+func ValidateXMLSchema(content, schema string) error {
+	s, err := xsd.Parse(schema)
+	if err != nil {
+		validationErr := &ValidationError{
+			Type:    "xsd",
+			Message: "failed to parse XML schema",
+			Err:     err,
+		}
+		var xsdErr *xsd.Error
+		if errors.As(err, &xsdErr) {
+			validationErr.Line = xsdErr.Line
+			validationErr.Err = errors.New(xsdErr.Message)
+		}
+		return validationErr
+	}
+
+	if err := s.Validate(content); err != nil {
+		validationErr := &ValidationError{
+			Type:    "xml",
+			Message: "content does not match the XML schema",
+			Err:     err,
+		}
+		var xsdErr *xsd.Error
+		if errors.As(err, &xsdErr) {
+			validationErr.Line = xsdErr.Line
+			validationErr.Err = errors.New(xsdErr.Message)
+		}
+		return validationErr
+	}
+	return nil
+}
+
+// ValidateJSONSchema validates decoded YAML or JSON data against a JSON
+// Schema. Schemas without $schema are read as draft 2020-12, and references
+// to other documents are not resolved. Every value that does not match is
+// reported as a *ValidationError with its JSON pointer, joined into one
+// error.
+func ValidateJSONSchema(data any, schema string) error {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		return &ValidationError{
+			Type:    "json schema",
+			Message: "failed to parse JSON schema",
+			Err:     err,
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(noLoader{})
+	if err := compiler.AddResource("schema.json", doc); err != nil {
+		return &ValidationError{
+			Type:    "json schema",
+			Message: "failed to compile JSON schema",
+			Err:     err,
+		}
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		return &ValidationError{
+			Type:    "json schema",
+			Message: "failed to compile JSON schema",
+			Err:     err,
+		}
+	}
+
+	return validateInstance(compiled, data)
+}
+
+// ValidateKubernetes validates a decoded Kubernetes manifest against the
+// schema of its apiVersion and kind. A manifest without a schema is an error
+// unless ignoreMissing is set.
+func ValidateKubernetes(manifest any, schemas *kubernetes.Schemas, ignoreMissing bool) error {
+	obj, ok := manifest.(map[string]any)
+	if !ok {
+		return &ValidationError{Type: "manifest", Message: "a Kubernetes manifest must be an object"}
+	}
+	apiVersion, _ := obj["apiVersion"].(string)
+	if apiVersion == "" {
+		return &ValidationError{Type: "manifest", Message: "missing apiVersion", Pointer: "/apiVersion"}
+	}
+	kind, _ := obj["kind"].(string)
+	if kind == "" {
+		return &ValidationError{Type: "manifest", Message: "missing kind", Pointer: "/kind"}
+	}
+
+	compiled, err := schemas.Schema(apiVersion, kind)
+	if err != nil {
+		return &ValidationError{
+			Type:    "json schema",
+			Message: fmt.Sprintf("failed to compile the schema of %s %s", apiVersion, kind),
+			Err:     err,
+		}
+	}
+	if compiled == nil {
+		if ignoreMissing {
+			return nil
+		}
+		return &ValidationError{
+			Type:    "manifest",
+			Message: fmt.Sprintf("no schema found for kind %s of %s", kind, apiVersion),
+		}
+	}
+	return validateInstance(compiled, manifest)
+}
+
+// validateInstance reports every value of data that does not match the
+// schema as a *ValidationError with its JSON pointer, joined into one error.
+func validateInstance(compiled *jsonschema.Schema, data any) error {
+	// The schema library expects the types encoding/json decodes to.
+	out, err := json.Marshal(data)
+	if err != nil {
+		return &ValidationError{
+			Type:    "content",
+			Message: "content cannot be converted to JSON",
+			Err:     err,
+		}
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(out))
+	if err != nil {
+		return &ValidationError{
+			Type:    "content",
+			Message: "content cannot be converted to JSON",
+			Err:     err,
+		}
+	}
+
+	var schemaErr *jsonschema.ValidationError
+	if err := compiled.Validate(instance); errors.As(err, &schemaErr) {
+		printer := message.NewPrinter(language.English)
+		var kinds []jsonschema.ErrorKind
+		var pointers []string
+		typeErrs := map[string]*kind.Type{}
+		for _, leaf := range schemaLeaves(schemaErr) {
+			pointer := jsonPointer(leaf.InstanceLocation)
+			// The branches of anyOf and oneOf report one type error each,
+			// which are combined into one for the value.
+			if typeErr, ok := leaf.ErrorKind.(*kind.Type); ok {
+				if merged, ok := typeErrs[pointer]; ok {
+					for _, want := range typeErr.Want {
+						if !slices.Contains(merged.Want, want) {
+							merged.Want = append(merged.Want, want)
+						}
+					}
+					continue
+				}
+				typeErrs[pointer] = &kind.Type{Got: typeErr.Got, Want: slices.Clone(typeErr.Want)}
+				kinds = append(kinds, typeErrs[pointer])
+			} else {
+				kinds = append(kinds, leaf.ErrorKind)
+			}
+			pointers = append(pointers, pointer)
+		}
+
+		var errs []*ValidationError
+		for i, k := range kinds {
+			errs = append(errs, &ValidationError{
+				Type:    "content",
+				Message: k.LocalizedString(printer),
+				Pointer: pointers[i],
+			})
+		}
+		// Keep the order stable between runs.
+		slices.SortStableFunc(errs, func(a, b *ValidationError) int {
+			return strings.Compare(a.Pointer, b.Pointer)
+		})
+		joined := make([]error, len(errs))
+		for i, err := range errs {
+			joined[i] = err
+		}
+		return errors.Join(joined...)
+	} else if err != nil {
+		return &ValidationError{
+			Type:    "content",
+			Message: "failed to validate content",
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// noLoader refuses to load referenced schemas, so validation does not read
+// local files or the network.
+type noLoader struct{}
+
+func (noLoader) Load(url string) (any, error) {
+	return nil, fmt.Errorf("cannot load %s, references to other documents are not supported", url)
+}
+
+// schemaLeaves returns the errors without further causes, which name the
+// failing values.
+func schemaLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaLeaves(cause)...)
+	}
+	return leaves
+}
+
+func jsonPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return b.String()
+}
@@ -8,6 +8,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"terraform-provider-gitsync/internal/keyvalue"
+	"terraform-provider-gitsync/internal/kubernetes"
+	"terraform-provider-gitsync/internal/serialize"
+	"terraform-provider-gitsync/internal/yamledit"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,6 +86,17 @@ func TestValidateYAML(t *testing.T) {
 			fixture:     "valid_special_chars.yaml",
 			expectError: false,
 		},
+		{
+			name:        "valid multi-document yaml",
+			fixture:     "valid_multi_document.yaml",
+			expectError: false,
+		},
+		{
+			name:        "invalid yaml - error in a later document",
+			fixture:     "invalid_multi_document.yaml",
+			expectError: true,
+			errorMsg:    "failed to parse YAML content in document 2: yaml: line 8:",
+		},
 	}
 
 	for _, tt := range tests {
@@ -212,6 +228,627 @@ func TestValidateJSON(t *testing.T) {
 	}
 }
 
+func TestValidateJSONC(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "valid jsonc with comments and trailing commas",
+			fixture:     "valid_comments.jsonc",
+			expectError: false,
+		},
+		{
+			name:        "comments are allowed",
+			fixture:     "invalid_comments.json",
+			expectError: false,
+		},
+		{
+			name:        "trailing comma is allowed",
+			fixture:     "invalid_trailing_comma.json",
+			expectError: false,
+		},
+		{
+			name:        "plain json",
+			fixture:     "valid_nested.json",
+			expectError: false,
+		},
+		{
+			name:        "empty content",
+			fixture:     "empty.txt",
+			expectError: true,
+			errorMsg:    "content cannot be empty",
+		},
+		{
+			name:        "invalid jsonc - unterminated comment",
+			fixture:     "invalid_unterminated_comment.jsonc",
+			expectError: true,
+			errorMsg:    "failed to parse JSONC content: unterminated comment",
+		},
+		{
+			name:        "invalid jsonc - missing comma",
+			fixture:     "invalid_missing_comma.jsonc",
+			expectError: true,
+			errorMsg:    "failed to parse JSONC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateJSONC(content)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, "jsonc", validationErr.Type)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTOML(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		expectError bool
+		errorMsg    string
+		line        int
+		column      int
+	}{
+		{
+			name:        "valid simple toml",
+			fixture:     "valid_simple.toml",
+			expectError: false,
+		},
+		{
+			name:        "valid pyproject toml",
+			fixture:     "valid_pyproject.toml",
+			expectError: false,
+		},
+		{
+			name:        "empty content",
+			fixture:     "empty.txt",
+			expectError: true,
+			errorMsg:    "content cannot be empty",
+		},
+		{
+			name:        "invalid toml - missing equals sign",
+			fixture:     "invalid_missing_equals.toml",
+			expectError: true,
+			errorMsg:    "failed to parse TOML content at line 4, column 5",
+			line:        4,
+			column:      5,
+		},
+		{
+			name:        "invalid toml - duplicate key",
+			fixture:     "invalid_duplicate_key.toml",
+			expectError: true,
+			errorMsg:    "failed to parse TOML content at line 2",
+			line:        2,
+			column:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateTOML(content)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, "toml", validationErr.Type)
+				assert.Equal(t, tt.line, validationErr.Line)
+				assert.Equal(t, tt.column, validationErr.Column)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHCL(t *testing.T) {
+	tests := []struct {
+		name           string
+		fixture        string
+		attributesOnly bool
+		expectError    bool
+		errorMsg       string
+		line           int
+	}{
+		{
+			name:           "valid tfvars",
+			fixture:        "valid_simple.tfvars",
+			attributesOnly: true,
+			expectError:    false,
+		},
+		{
+			name:        "valid hcl with blocks",
+			fixture:     "valid_blocks.hcl",
+			expectError: false,
+		},
+		{
+			name:           "blocks not allowed",
+			fixture:        "valid_blocks.hcl",
+			attributesOnly: true,
+			expectError:    true,
+			errorMsg:       "only attributes are allowed, found a \"locals\" block at line 1, column 1",
+			line:           1,
+		},
+		{
+			name:        "empty content",
+			fixture:     "empty.txt",
+			expectError: true,
+			errorMsg:    "content cannot be empty",
+		},
+		{
+			name:        "invalid hcl - unclosed brace",
+			fixture:     "invalid_unclosed_brace.tfvars",
+			expectError: true,
+			errorMsg:    "failed to parse HCL content at line 4, column 1: Missing expression",
+			line:        4,
+		},
+		{
+			name:        "invalid hcl - missing value",
+			fixture:     "invalid_missing_value.hcl",
+			expectError: true,
+			errorMsg:    "failed to parse HCL content at line 2",
+			line:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateHCL(content, tt.attributesOnly)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, "hcl", validationErr.Type)
+				assert.Equal(t, tt.line, validationErr.Line)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateKeyValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		format      keyvalue.Format
+		expectError bool
+		errorMsg    string
+		line        int
+		column      int
+	}{
+		{
+			name:        "valid dotenv",
+			fixture:     "valid_simple.env",
+			format:      keyvalue.FormatDotenv,
+			expectError: false,
+		},
+		{
+			name:        "valid ini",
+			fixture:     "valid_simple.ini",
+			format:      keyvalue.FormatINI,
+			expectError: false,
+		},
+		{
+			name:        "valid properties",
+			fixture:     "valid_simple.properties",
+			format:      keyvalue.FormatProperties,
+			expectError: false,
+		},
+		{
+			name:        "empty content",
+			fixture:     "empty.txt",
+			format:      keyvalue.FormatDotenv,
+			expectError: true,
+			errorMsg:    "content cannot be empty",
+		},
+		{
+			name:        "invalid dotenv - duplicate key",
+			fixture:     "invalid_duplicate_key.env",
+			format:      keyvalue.FormatDotenv,
+			expectError: true,
+			errorMsg:    `failed to parse dotenv content at line 3, column 1: duplicate key "APP_NAME"`,
+			line:        3,
+			column:      1,
+		},
+		{
+			name:        "invalid dotenv - unknown escape",
+			fixture:     "invalid_escape.env",
+			format:      keyvalue.FormatDotenv,
+			expectError: true,
+			errorMsg:    `invalid escape "\\x"`,
+			line:        1,
+			column:      16,
+		},
+		{
+			name:        "invalid ini - duplicate key",
+			fixture:     "invalid_duplicate_key.ini",
+			format:      keyvalue.FormatINI,
+			expectError: true,
+			errorMsg:    `duplicate key "database.host"`,
+			line:        4,
+			column:      1,
+		},
+		{
+			name:        "invalid properties - malformed unicode escape",
+			fixture:     "invalid_unicode_escape.properties",
+			format:      keyvalue.FormatProperties,
+			expectError: true,
+			errorMsg:    "invalid unicode escape",
+			line:        1,
+			column:      13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateKeyValue(content, tt.format)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, string(tt.format), validationErr.Type)
+				assert.Equal(t, tt.line, validationErr.Line)
+				assert.Equal(t, tt.column, validationErr.Column)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateXML(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		expectError bool
+		errorMsg    string
+		line        int
+	}{
+		{
+			name:        "valid xml",
+			fixture:     "valid_settings.xml",
+			expectError: false,
+		},
+		{
+			name:        "empty content",
+			fixture:     "empty.txt",
+			expectError: true,
+			errorMsg:    "content cannot be empty",
+		},
+		{
+			name:        "invalid xml - mismatched tag",
+			fixture:     "invalid_mismatched_tag.xml",
+			expectError: true,
+			errorMsg:    "failed to parse XML content at line 5",
+			line:        5,
+		},
+		{
+			name:        "invalid xml - two root elements",
+			fixture:     "invalid_two_roots.xml",
+			expectError: true,
+			errorMsg:    "only one root element is allowed at line 2",
+			line:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateXML(content)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, "xml", validationErr.Type)
+				assert.Equal(t, tt.line, validationErr.Line)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateXMLSchema(t *testing.T) {
+	schema := loadFixture(t, "settings.xsd")
+
+	tests := []struct {
+		name        string
+		fixture     string
+		schema      string
+		expectError bool
+		errorType   string
+		errorMsg    string
+		line        int
+	}{
+		{
+			name:        "valid settings",
+			fixture:     "valid_settings.xml",
+			schema:      schema,
+			expectError: false,
+		},
+		{
+			name:        "invalid value",
+			fixture:     "invalid_settings.xml",
+			schema:      schema,
+			expectError: true,
+			errorType:   "xml",
+			errorMsg:    `content does not match the XML schema at line 3: element "offline": value "no" is not a valid boolean`,
+			line:        3,
+		},
+		{
+			name:        "invalid schema",
+			fixture:     "valid_settings.xml",
+			schema:      loadFixture(t, "valid_settings.xml"),
+			expectError: true,
+			errorType:   "xsd",
+			errorMsg:    "failed to parse XML schema at line 2",
+			line:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := loadFixture(t, tt.fixture)
+			err := ValidateXMLSchema(content, tt.schema)
+
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+
+				validationErr, ok := err.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, tt.errorType, validationErr.Type)
+				assert.Equal(t, tt.line, validationErr.Line)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		data     any
+		pointers []string
+		errorMsg string
+	}{
+		{
+			name:   "valid draft 7",
+			schema: "values_draft7.schema.json",
+			data: map[string]any{
+				"replicaCount": 2,
+				"image":        map[string]any{"repository": "nginx", "pullPolicy": "Always"},
+			},
+		},
+		{
+			name:   "invalid draft 7",
+			schema: "values_draft7.schema.json",
+			data: map[string]any{
+				"replicaCount": 0,
+				"image":        map[string]any{"repository": "nginx", "pullPolicy": "Sometimes", "digest": "x"},
+			},
+			pointers: []string{"/replicaCount", "/image", "/image/pullPolicy"},
+			errorMsg: "at /replicaCount",
+		},
+		{
+			name:     "missing required property",
+			schema:   "values_draft7.schema.json",
+			data:     map[string]any{"replicaCount": 1},
+			pointers: []string{""},
+			errorMsg: "missing property 'image'",
+		},
+		{
+			name:   "valid draft 2020-12",
+			schema: "values_2020.schema.json",
+			data:   map[string]any{"ports": []any{"http", 80, 443}, "labels": map[string]any{"team": "web"}},
+		},
+		{
+			name:     "invalid draft 2020-12",
+			schema:   "values_2020.schema.json",
+			data:     map[string]any{"ports": []any{"http", 70000}, "labels": map[string]any{"a/b": 1}, "extra": true},
+			pointers: []string{"/extra", "/ports/1", "/labels/a~1b"},
+			errorMsg: "maximum: got 70,000, want 65,535 at /ports/1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSONSchema(tt.data, loadFixture(t, tt.schema))
+			if len(tt.pointers) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+
+			var pointers []string
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				validationErr, ok := e.(*ValidationError)
+				require.True(t, ok, "error should be a ValidationError")
+				assert.Equal(t, "content", validationErr.Type)
+				pointers = append(pointers, validationErr.Pointer)
+			}
+			assert.ElementsMatch(t, tt.pointers, pointers)
+		})
+	}
+}
+
+func TestValidateJSONSchemaInvalidSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		errorMsg string
+	}{
+		{
+			name:     "not json",
+			schema:   "{",
+			errorMsg: "failed to parse JSON schema",
+		},
+		{
+			name:     "invalid keyword value",
+			schema:   `{"type": "integer", "minimum": "one"}`,
+			errorMsg: "failed to compile JSON schema",
+		},
+		{
+			name:     "external reference",
+			schema:   `{"$ref": "common.json"}`,
+			errorMsg: "references to other documents are not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSONSchema(map[string]any{}, tt.schema)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+
+			validationErr, ok := err.(*ValidationError)
+			require.True(t, ok, "error should be a ValidationError")
+			assert.Equal(t, "json schema", validationErr.Type)
+		})
+	}
+}
+
+func TestValidateKubernetes(t *testing.T) {
+	schemas, err := kubernetes.Load(kubernetes.LatestVersion(), loadFixture(t, "crd_certificates.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		manifest string
+		pointers []string
+		errorMsg string
+	}{
+		{
+			name:     "valid deployment and service",
+			manifest: "valid_deployment.yaml",
+		},
+		{
+			name:     "invalid deployment",
+			manifest: "invalid_deployment.yaml",
+			pointers: []string{"/spec/replicas", "/spec/template/spec/containers/0"},
+			errorMsg: "additional properties 'port' not allowed",
+		},
+		{
+			name:     "valid custom resource",
+			manifest: "valid_certificate.yaml",
+		},
+		{
+			name:     "invalid custom resource",
+			manifest: "invalid_certificate.yaml",
+			pointers: []string{"/metadata", "/spec", "/spec/duration", "/spec/port"},
+			errorMsg: "missing property 'secretName' at /spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pointers []string
+			for _, doc := range yamledit.SplitDocuments(loadFixture(t, tt.manifest)) {
+				manifest, err := serialize.ParseYAML(doc)
+				require.NoError(t, err)
+
+				err = ValidateKubernetes(manifest, schemas, false)
+				if err == nil {
+					continue
+				}
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+					validationErr, ok := e.(*ValidationError)
+					require.True(t, ok, "error should be a ValidationError")
+					pointers = append(pointers, validationErr.Pointer)
+				}
+			}
+			assert.ElementsMatch(t, tt.pointers, pointers)
+		})
+	}
+}
+
+func TestValidateKubernetesManifest(t *testing.T) {
+	schemas, err := kubernetes.Load(kubernetes.LatestVersion())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		manifest      any
+		ignoreMissing bool
+		errorMsg      string
+	}{
+		{
+			name:     "not an object",
+			manifest: []any{"a"},
+			errorMsg: "a Kubernetes manifest must be an object",
+		},
+		{
+			name:     "missing apiVersion",
+			manifest: map[string]any{"kind": "Service"},
+			errorMsg: "missing apiVersion at /apiVersion",
+		},
+		{
+			name:     "missing kind",
+			manifest: map[string]any{"apiVersion": "v1"},
+			errorMsg: "missing kind at /kind",
+		},
+		{
+			name:     "unknown kind",
+			manifest: map[string]any{"apiVersion": "example.com/v1", "kind": "Certificate"},
+			errorMsg: "no schema found for kind Certificate of example.com/v1",
+		},
+		{
+			name:          "unknown kind ignored",
+			manifest:      map[string]any{"apiVersion": "example.com/v1", "kind": "Certificate"},
+			ignoreMissing: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKubernetes(tt.manifest, schemas, tt.ignoreMissing)
+			if tt.errorMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+
+			validationErr, ok := err.(*ValidationError)
+			require.True(t, ok, "error should be a ValidationError")
+			assert.Equal(t, "manifest", validationErr.Type)
+		})
+	}
+}
+
 func TestValidationError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -235,6 +872,16 @@ func TestValidationError(t *testing.T) {
 			},
 			expected: "invalid yaml: content cannot be empty",
 		},
+		{
+			name: "error with line only",
+			err: &ValidationError{
+				Type:    "xml",
+				Message: "content does not match the XML schema",
+				Line:    3,
+				Err:     assert.AnError,
+			},
+			expected: "invalid xml: content does not match the XML schema at line 3: assert.AnError general error for testing",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,259 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package xsd validates XML documents against an XML Schema. It covers the
+// parts of XML Schema 1.0 that configuration schemas use: element and type
+// declarations, sequences, choices, all groups, named groups, attributes,
+// type extension and restriction, and the facets of simple types. Schemas
+// have to be self-contained, includes are not resolved.
+package xsd
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+	xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+)
+
+// Error locates a problem in a schema or a validated document.
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+type Schema struct {
+	targetNamespace string
+
+	elements        map[string]*node
+	complexTypes    map[string]*node
+	simpleTypes     map[string]*node
+	groups          map[string]*node
+	attributeGroups map[string]*node
+	attributes      map[string]*node
+
+	// patterns holds the compiled pattern facets.
+	patterns map[*node]*regexp.Regexp
+}
+
+// Parse parses an XML Schema and checks that all referenced types, elements,
+// groups and attributes are declared.
+func Parse(content string) (*Schema, error) {
+	root, err := parseTree(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if root.name != (xml.Name{Space: xsdNamespace, Local: "schema"}) {
+		return nil, &Error{Line: root.line, Message: "the root element must be an xs:schema element"}
+	}
+
+	s := &Schema{
+		elements:        make(map[string]*node),
+		complexTypes:    make(map[string]*node),
+		simpleTypes:     make(map[string]*node),
+		groups:          make(map[string]*node),
+		attributeGroups: make(map[string]*node),
+		attributes:      make(map[string]*node),
+		patterns:        make(map[*node]*regexp.Regexp),
+	}
+	s.targetNamespace, _ = root.attr("targetNamespace")
+
+	for _, c := range root.schemaChildren() {
+		name, _ := c.attr("name")
+		switch c.name.Local {
+		case "element":
+			s.elements[name] = c
+		case "complexType":
+			s.complexTypes[name] = c
+		case "simpleType":
+			s.simpleTypes[name] = c
+		case "group":
+			s.groups[name] = c
+		case "attributeGroup":
+			s.attributeGroups[name] = c
+		case "attribute":
+			s.attributes[name] = c
+		case "include", "redefine", "override":
+			return nil, &Error{
+				Line:    c.line,
+				Message: fmt.Sprintf("xs:%s is not supported, the schema must be self-contained", c.name.Local),
+			}
+		}
+	}
+
+	if err := s.check(root); err != nil {
+		return nil, err
+	}
+	if err := s.checkCycles(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// check resolves the references below n and compiles the patterns.
+func (s *Schema) check(n *node) error {
+	for _, c := range n.schemaChildren() {
+		for _, attr := range []string{"type", "base", "itemType"} {
+			if value, ok := c.attr(attr); ok && !s.typeDeclared(c.qname(value)) {
+				return &Error{Line: c.line, Message: fmt.Sprintf("unknown type %q", value)}
+			}
+		}
+		if value, ok := c.attr("memberTypes"); ok {
+			for _, member := range strings.Fields(value) {
+				if !s.typeDeclared(c.qname(member)) {
+					return &Error{Line: c.line, Message: fmt.Sprintf("unknown type %q", member)}
+				}
+			}
+		}
+
+		if ref, ok := c.attr("ref"); ok {
+			name := c.qname(ref)
+			var declared bool
+			switch c.name.Local {
+			case "element":
+				declared = s.elements[name.Local] != nil
+			case "attribute":
+				declared = s.attributes[name.Local] != nil || name.Space == xmlNamespace
+			case "group":
+				declared = s.groups[name.Local] != nil
+			case "attributeGroup":
+				declared = s.attributeGroups[name.Local] != nil
+			}
+			if !declared {
+				return &Error{Line: c.line, Message: fmt.Sprintf("unknown %s %q", c.name.Local, ref)}
+			}
+		}
+
+		if c.name.Local == "pattern" {
+			value, _ := c.attr("value")
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err == nil && strings.Contains(value, "-[") {
+				// Go reads a character class subtraction as a different class.
+				err = errors.New("character class subtraction is not supported")
+			}
+			if err != nil {
+				return &Error{Line: c.line, Message: fmt.Sprintf("unsupported pattern %q: %v", value, err)}
+			}
+			s.patterns[c] = re
+		}
+
+		if err := s.check(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCycles rejects types derived from themselves and groups that contain
+// themselves, which would never end to validate.
+func (s *Schema) checkCycles() error {
+	for kind, declarations := range map[string]map[string]*node{
+		"type":            mergeTypes(s.simpleTypes, s.complexTypes),
+		"group":           s.groups,
+		"attribute group": s.attributeGroups,
+	} {
+		for name, n := range declarations {
+			if s.cyclic(n, map[*node]bool{}) {
+				return &Error{Line: n.line, Message: fmt.Sprintf("%s %q refers to itself", kind, name)}
+			}
+		}
+	}
+	return nil
+}
+
+func mergeTypes(simple, complex map[string]*node) map[string]*node {
+	types := make(map[string]*node, len(simple)+len(complex))
+	for name, n := range simple {
+		types[name] = n
+	}
+	for name, n := range complex {
+		types[name] = n
+	}
+	return types
+}
+
+// cyclic follows the base types and group references of a declaration.
+// Elements are not followed, as recursive elements are valid.
+func (s *Schema) cyclic(n *node, visiting map[*node]bool) bool {
+	if visiting[n] {
+		return true
+	}
+	visiting[n] = true
+	defer delete(visiting, n)
+
+	for _, c := range n.schemaChildren() {
+		var next *node
+		switch c.name.Local {
+		case "restriction", "extension":
+			if base, ok := c.attr("base"); ok {
+				name := c.qname(base)
+				if name.Space != xsdNamespace {
+					next = s.simpleTypes[name.Local]
+					if next == nil {
+						next = s.complexTypes[name.Local]
+					}
+				}
+			}
+		case "group", "attributeGroup":
+			if ref, ok := c.attr("ref"); ok {
+				if c.name.Local == "group" {
+					next = s.groups[c.qname(ref).Local]
+				} else {
+					next = s.attributeGroups[c.qname(ref).Local]
+				}
+			}
+		case "element", "attribute":
+			continue
+		}
+		if next != nil && s.cyclic(next, visiting) {
+			return true
+		}
+		if s.cyclic(c, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) typeDeclared(name xml.Name) bool {
+	if name.Space == xsdNamespace {
+		_, ok := builtins[name.Local]
+		return ok
+	}
+	return s.complexTypes[name.Local] != nil || s.simpleTypes[name.Local] != nil
+}
+
+// Validate validates an XML document against the schema.
+func (s *Schema) Validate(content string) error {
+	root, err := parseTree(content)
+	if err != nil {
+		return err
+	}
+
+	decl, ok := s.elements[root.name.Local]
+	if !ok {
+		return &Error{
+			Line:    root.line,
+			Message: fmt.Sprintf("element %q is not declared in the schema", root.name.Local),
+		}
+	}
+	if root.name.Space != s.targetNamespace {
+		return &Error{
+			Line:    root.line,
+			Message: fmt.Sprintf("element %q must be in namespace %q, not %q", root.name.Local, s.targetNamespace, root.name.Space),
+		}
+	}
+
+	v := &validator{schema: s}
+	return v.element(root, decl)
+}
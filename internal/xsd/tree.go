@@ -0,0 +1,124 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xsd
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+// node is an element of a parsed XML document.
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+	line     int
+	parent   *node
+}
+
+func parseTree(content string) (*node, error) {
+	dec := xml.NewDecoder(strings.NewReader(content))
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var root, current *node
+	for {
+		// The position before the token is the start of the element, as
+		// the whitespace in front of it is a token of its own.
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: slices.Clone(t.Attr), line: line, parent: current}
+			if current != nil {
+				current.children = append(current.children, n)
+			} else if root == nil {
+				root = n
+			}
+			current = n
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("no root element")
+	}
+	return root, nil
+}
+
+// attr returns the value of an attribute without namespace.
+func (n *node) attr(local string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// namespace returns the namespace bound to prefix at n.
+func (n *node) namespace(prefix string) string {
+	for m := n; m != nil; m = m.parent {
+		for _, a := range m.attrs {
+			if prefix == "" && a.Name.Space == "" && a.Name.Local == "xmlns" ||
+				prefix != "" && a.Name.Space == "xmlns" && a.Name.Local == prefix {
+				return a.Value
+			}
+		}
+	}
+	if prefix == "xml" {
+		return xmlNamespace
+	}
+	return ""
+}
+
+// qname resolves a prefixed name used in an attribute value, like the type
+// of an element.
+func (n *node) qname(value string) xml.Name {
+	prefix, local, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return xml.Name{Space: n.namespace(""), Local: prefix}
+	}
+	return xml.Name{Space: n.namespace(prefix), Local: local}
+}
+
+// schemaChildren returns the XML Schema elements below n without
+// annotations.
+func (n *node) schemaChildren() []*node {
+	var children []*node
+	for _, c := range n.children {
+		if c.name.Space == xsdNamespace && c.name.Local != "annotation" {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// child returns the first XML Schema element below n with one of the given
+// names.
+func (n *node) child(locals ...string) *node {
+	for _, c := range n.schemaChildren() {
+		if slices.Contains(locals, c.name.Local) {
+			return c
+		}
+	}
+	return nil
+}
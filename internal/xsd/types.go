@@ -0,0 +1,403 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xsd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// simpleRef is a simple type, either a built-in type or an xs:simpleType
+// element.
+type simpleRef struct {
+	builtin string
+	node    *node
+}
+
+type attributeDecl struct {
+	typ        simpleRef
+	required   bool
+	prohibited bool
+}
+
+var (
+	decimalPattern  = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+	datePattern     = regexp.MustCompile(`^-?\d{4,}-\d{2}-\d{2}(Z|[+-]\d{2}:\d{2})?$`)
+	timePattern     = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`)
+	dateTimePattern = regexp.MustCompile(`^-?\d{4,}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`)
+	durationPattern = regexp.MustCompile(`^-?P(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?$`)
+)
+
+// builtins checks the lexical space of the built-in types. Types without a
+// check accept any value.
+var builtins = map[string]func(string) bool{
+	"anyType":          nil,
+	"anySimpleType":    nil,
+	"string":           nil,
+	"normalizedString": nil,
+	"token":            nil,
+	"language":         nil,
+	"Name":             nil,
+	"NCName":           nil,
+	"NMTOKEN":          nil,
+	"NMTOKENS":         nil,
+	"ID":               nil,
+	"IDREF":            nil,
+	"IDREFS":           nil,
+	"ENTITY":           nil,
+	"ENTITIES":         nil,
+	"QName":            nil,
+	"NOTATION":         nil,
+	"anyURI":           nil,
+	"gYear":            nil,
+	"gYearMonth":       nil,
+	"gMonth":           nil,
+	"gMonthDay":        nil,
+	"gDay":             nil,
+	"boolean":          func(s string) bool { return slices.Contains([]string{"true", "false", "1", "0"}, s) },
+	"decimal":          decimalPattern.MatchString,
+	"float":            isFloat,
+	"double":           isFloat,
+	"date":             datePattern.MatchString,
+	"time":             timePattern.MatchString,
+	"dateTime":         dateTimePattern.MatchString,
+	"duration":         func(s string) bool { return durationPattern.MatchString(s) && s != "P" && !strings.HasSuffix(s, "T") },
+	"base64Binary": func(s string) bool {
+		_, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		return err == nil
+	},
+	"hexBinary":          func(s string) bool { _, err := hex.DecodeString(s); return err == nil },
+	"integer":            integer("", ""),
+	"long":               integer("-9223372036854775808", "9223372036854775807"),
+	"int":                integer("-2147483648", "2147483647"),
+	"short":              integer("-32768", "32767"),
+	"byte":               integer("-128", "127"),
+	"nonNegativeInteger": integer("0", ""),
+	"positiveInteger":    integer("1", ""),
+	"nonPositiveInteger": integer("", "0"),
+	"negativeInteger":    integer("", "-1"),
+	"unsignedLong":       integer("0", "18446744073709551615"),
+	"unsignedInt":        integer("0", "4294967295"),
+	"unsignedShort":      integer("0", "65535"),
+	"unsignedByte":       integer("0", "255"),
+}
+
+func isFloat(s string) bool {
+	if slices.Contains([]string{"INF", "-INF", "NaN"}, s) {
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil && !strings.ContainsAny(s, "xXpP_") && !strings.EqualFold(s, "inf") && !strings.EqualFold(s, "nan")
+}
+
+func integer(lower, upper string) func(string) bool {
+	return func(s string) bool {
+		n, ok := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+		if !ok || strings.HasPrefix(s, "+-") {
+			return false
+		}
+		if lower != "" {
+			bound, _ := new(big.Int).SetString(lower, 10)
+			if n.Cmp(bound) < 0 {
+				return false
+			}
+		}
+		if upper != "" {
+			bound, _ := new(big.Int).SetString(upper, 10)
+			if n.Cmp(bound) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// simpleRef resolves the name of a simple type used at n.
+func (s *Schema) simpleRef(n *node, value string) simpleRef {
+	name := n.qname(value)
+	if name.Space == xsdNamespace {
+		return simpleRef{builtin: name.Local}
+	}
+	if st := s.simpleTypes[name.Local]; st != nil {
+		return simpleRef{node: st}
+	}
+	// Complex types in the place of a simple type accept any text.
+	return simpleRef{builtin: "anySimpleType"}
+}
+
+// preserved reports whether whitespace around values of the type is kept,
+// which is the case for types derived from xs:string.
+func (s *Schema) preserved(typ simpleRef) bool {
+	for typ.node != nil {
+		restriction := typ.node.child("restriction")
+		if restriction == nil {
+			return false
+		}
+		typ = s.restrictionBase(restriction)
+	}
+	return typ.builtin == "string" || typ.builtin == "normalizedString" || typ.builtin == "anySimpleType"
+}
+
+func (s *Schema) restrictionBase(restriction *node) simpleRef {
+	if base, ok := restriction.attr("base"); ok {
+		return s.simpleRef(restriction, base)
+	}
+	if st := restriction.child("simpleType"); st != nil {
+		return simpleRef{node: st}
+	}
+	return simpleRef{builtin: "anySimpleType"}
+}
+
+// value validates a value against a simple type.
+func (s *Schema) value(value string, typ simpleRef) error {
+	if !s.preserved(typ) {
+		value = strings.TrimSpace(value)
+	}
+
+	if typ.node == nil {
+		if check := builtins[typ.builtin]; check != nil && !check(value) {
+			return fmt.Errorf("value %q is not a valid %s", value, typ.builtin)
+		}
+		return nil
+	}
+
+	switch c := typ.node.child("restriction", "list", "union"); {
+	case c == nil:
+		return nil
+	case c.name.Local == "restriction":
+		if err := s.value(value, s.restrictionBase(c)); err != nil {
+			return err
+		}
+		return s.facets(value, c)
+	case c.name.Local == "list":
+		item := simpleRef{builtin: "anySimpleType"}
+		if itemType, ok := c.attr("itemType"); ok {
+			item = s.simpleRef(c, itemType)
+		} else if st := c.child("simpleType"); st != nil {
+			item = simpleRef{node: st}
+		}
+		for _, field := range strings.Fields(value) {
+			if err := s.value(field, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		var members []simpleRef
+		if memberTypes, ok := c.attr("memberTypes"); ok {
+			for _, member := range strings.Fields(memberTypes) {
+				members = append(members, s.simpleRef(c, member))
+			}
+		}
+		for _, st := range c.schemaChildren() {
+			members = append(members, simpleRef{node: st})
+		}
+		for _, member := range members {
+			if s.value(value, member) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q does not match any member type of the union", value)
+	}
+}
+
+// facets checks the facets of a restriction. The patterns of one
+// restriction are alternatives.
+func (s *Schema) facets(value string, restriction *node) error {
+	var enumeration, patterns []string
+	matched := false
+	for _, f := range restriction.schemaChildren() {
+		facet, _ := f.attr("value")
+		switch f.name.Local {
+		case "enumeration":
+			enumeration = append(enumeration, facet)
+		case "pattern":
+			patterns = append(patterns, facet)
+			matched = matched || s.patterns[f].MatchString(value)
+		case "length", "minLength", "maxLength":
+			limit, _ := strconv.Atoi(facet)
+			length := utf8.RuneCountInString(value)
+			switch {
+			case f.name.Local == "length" && length != limit:
+				return fmt.Errorf("value %q must be %d characters long", value, limit)
+			case f.name.Local == "minLength" && length < limit:
+				return fmt.Errorf("value %q must be at least %d characters long", value, limit)
+			case f.name.Local == "maxLength" && length > limit:
+				return fmt.Errorf("value %q must be at most %d characters long", value, limit)
+			}
+		case "minInclusive", "maxInclusive", "minExclusive", "maxExclusive":
+			if err := bound(value, f.name.Local, facet); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(enumeration) > 0 && !slices.Contains(enumeration, value) {
+		return fmt.Errorf("value %q is not one of %q", value, enumeration)
+	}
+	if len(patterns) > 0 && !matched {
+		return fmt.Errorf("value %q does not match the pattern %q", value, strings.Join(patterns, "|"))
+	}
+	return nil
+}
+
+// bound compares a number to a range facet. Values that are not numbers,
+// like dates, are not compared.
+func bound(value, facet, limit string) error {
+	v, _, err := big.ParseFloat(value, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil
+	}
+	l, _, err := big.ParseFloat(limit, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil
+	}
+
+	cmp := v.Cmp(l)
+	ok := map[string]bool{
+		"minInclusive": cmp >= 0,
+		"maxInclusive": cmp <= 0,
+		"minExclusive": cmp > 0,
+		"maxExclusive": cmp < 0,
+	}[facet]
+	if !ok {
+		return fmt.Errorf("value %s does not satisfy %s %s", value, facet, limit)
+	}
+	return nil
+}
+
+// simpleContent validates the text of an element with simple content.
+func (s *Schema) simpleContent(text string, sc *node) error {
+	derivation := sc.child("extension", "restriction")
+	if derivation == nil {
+		return nil
+	}
+
+	base, _ := derivation.attr("base")
+	name := derivation.qname(base)
+	if ct := s.complexTypes[name.Local]; ct != nil && name.Space != xsdNamespace {
+		if baseContent := ct.child("simpleContent"); baseContent != nil {
+			if err := s.simpleContent(text, baseContent); err != nil {
+				return err
+			}
+		}
+	} else if err := s.value(text, s.simpleRef(derivation, base)); err != nil {
+		return err
+	}
+
+	if derivation.name.Local == "restriction" {
+		return s.facets(strings.TrimSpace(text), derivation)
+	}
+	return nil
+}
+
+// mixed reports whether a complex type allows text between its elements.
+func (s *Schema) mixed(ct *node) bool {
+	if mixed, _ := ct.attr("mixed"); mixed == "true" {
+		return true
+	}
+	if cc := ct.child("complexContent"); cc != nil {
+		mixed, _ := cc.attr("mixed")
+		return mixed == "true"
+	}
+	return false
+}
+
+// contentModel returns the particles of a complex type in order, starting
+// with the ones of the base types it extends.
+func (s *Schema) contentModel(ct *node) []*node {
+	cc := ct.child("complexContent")
+	if cc == nil {
+		if model := ct.child("sequence", "choice", "all", "group"); model != nil {
+			return []*node{model}
+		}
+		return nil
+	}
+
+	var models []*node
+	derivation := cc.child("extension", "restriction")
+	if derivation == nil {
+		return nil
+	}
+	if derivation.name.Local == "extension" {
+		base, _ := derivation.attr("base")
+		if baseType := s.complexTypes[derivation.qname(base).Local]; baseType != nil && baseType != ct {
+			models = s.contentModel(baseType)
+		}
+	}
+	if model := derivation.child("sequence", "choice", "all", "group"); model != nil {
+		models = append(models, model)
+	}
+	return models
+}
+
+// attributeDecls returns the attributes declared by a complex type and its
+// base types, and whether other attributes are allowed.
+func (s *Schema) attributeDecls(ct *node) (map[string]attributeDecl, bool) {
+	decls := make(map[string]attributeDecl)
+	anyAttribute := false
+
+	holder := ct
+	if content := ct.child("complexContent", "simpleContent"); content != nil {
+		derivation := content.child("extension", "restriction")
+		if derivation == nil {
+			return decls, false
+		}
+		if derivation.name.Local == "extension" {
+			base, _ := derivation.attr("base")
+			if baseType := s.complexTypes[derivation.qname(base).Local]; baseType != nil && baseType != ct {
+				decls, anyAttribute = s.attributeDecls(baseType)
+			}
+		}
+		holder = derivation
+	}
+
+	if s.collectAttributes(holder, decls, 0) {
+		anyAttribute = true
+	}
+	return decls, anyAttribute
+}
+
+// collectAttributes adds the attributes declared below n to decls and
+// reports whether n allows any attribute.
+func (s *Schema) collectAttributes(n *node, decls map[string]attributeDecl, depth int) bool {
+	anyAttribute := false
+	for _, c := range n.schemaChildren() {
+		switch c.name.Local {
+		case "attribute":
+			decl := c
+			use, _ := c.attr("use")
+			if ref, ok := c.attr("ref"); ok {
+				name := c.qname(ref)
+				if name.Space == xmlNamespace {
+					continue
+				}
+				decl = s.attributes[name.Local]
+			}
+			name, _ := decl.attr("name")
+			typ := simpleRef{builtin: "anySimpleType"}
+			if t, ok := decl.attr("type"); ok {
+				typ = s.simpleRef(decl, t)
+			} else if st := decl.child("simpleType"); st != nil {
+				typ = simpleRef{node: st}
+			}
+			decls[name] = attributeDecl{typ: typ, required: use == "required", prohibited: use == "prohibited"}
+		case "attributeGroup":
+			ref, _ := c.attr("ref")
+			if group := s.attributeGroups[c.qname(ref).Local]; group != nil && depth < 32 {
+				anyAttribute = s.collectAttributes(group, decls, depth+1) || anyAttribute
+			}
+		case "anyAttribute":
+			anyAttribute = true
+		}
+	}
+	return anyAttribute
+}
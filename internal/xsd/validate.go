@@ -0,0 +1,338 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type validator struct {
+	schema *Schema
+}
+
+// missing reports that a particle did not match at its position. It is only
+// an error when the elements before it were matched by the same group.
+type missing struct {
+	expected []string
+}
+
+func (m *missing) Error() string {
+	return "missing " + m.describe()
+}
+
+func (m *missing) describe() string {
+	if len(m.expected) == 1 {
+		return "element " + m.expected[0]
+	}
+	return "one of the elements " + strings.Join(m.expected, ", ")
+}
+
+// element validates n against an xs:element declaration.
+func (v *validator) element(n *node, decl *node) error {
+	if ref, ok := decl.attr("ref"); ok {
+		decl = v.schema.elements[decl.qname(ref).Local]
+	}
+	if nilled, _ := attrValue(n, xsiNamespace, "nil"); nilled == "true" {
+		return nil
+	}
+
+	if typ, ok := decl.attr("type"); ok {
+		name := decl.qname(typ)
+		if ct := v.schema.complexTypes[name.Local]; ct != nil && name.Space != xsdNamespace {
+			return v.complexType(n, ct)
+		}
+		return v.simpleElement(n, v.schema.simpleRef(decl, typ))
+	}
+	if ct := decl.child("complexType"); ct != nil {
+		return v.complexType(n, ct)
+	}
+	if st := decl.child("simpleType"); st != nil {
+		return v.simpleElement(n, simpleRef{node: st})
+	}
+	// Elements without a type can contain anything.
+	return nil
+}
+
+func (v *validator) simpleElement(n *node, typ simpleRef) error {
+	if typ.builtin == "anyType" {
+		return nil
+	}
+	if len(n.children) > 0 {
+		return &Error{
+			Line:    n.children[0].line,
+			Message: fmt.Sprintf("element %q cannot contain elements", n.name.Local),
+		}
+	}
+	if err := v.attributes(n, nil, false); err != nil {
+		return err
+	}
+	if err := v.schema.value(n.text, typ); err != nil {
+		return &Error{Line: n.line, Message: fmt.Sprintf("element %q: %v", n.name.Local, err)}
+	}
+	return nil
+}
+
+func (v *validator) complexType(n *node, ct *node) error {
+	declared, anyAttribute := v.schema.attributeDecls(ct)
+	if err := v.attributes(n, declared, anyAttribute); err != nil {
+		return err
+	}
+
+	if sc := ct.child("simpleContent"); sc != nil {
+		if len(n.children) > 0 {
+			return &Error{
+				Line:    n.children[0].line,
+				Message: fmt.Sprintf("element %q cannot contain elements", n.name.Local),
+			}
+		}
+		if err := v.schema.simpleContent(n.text, sc); err != nil {
+			return &Error{Line: n.line, Message: fmt.Sprintf("element %q: %v", n.name.Local, err)}
+		}
+		return nil
+	}
+
+	if !v.schema.mixed(ct) && strings.TrimSpace(n.text) != "" {
+		return &Error{
+			Line:    n.line,
+			Message: fmt.Sprintf("element %q cannot contain text", n.name.Local),
+		}
+	}
+
+	i := 0
+	for _, model := range v.schema.contentModel(ct) {
+		j, err := v.match(model, n, i)
+		if err != nil {
+			return v.located(err, n, j)
+		}
+		i = j
+	}
+	if i < len(n.children) {
+		return &Error{
+			Line:    n.children[i].line,
+			Message: fmt.Sprintf("unexpected element %q in %q", n.children[i].name.Local, n.name.Local),
+		}
+	}
+	return nil
+}
+
+// located turns a missing particle into an error at the position it was
+// expected.
+func (v *validator) located(err error, parent *node, i int) error {
+	var m *missing
+	if !errors.As(err, &m) {
+		return err
+	}
+	if i < len(parent.children) {
+		return &Error{
+			Line: parent.children[i].line,
+			Message: fmt.Sprintf("unexpected element %q in %q, expected %s",
+				parent.children[i].name.Local, parent.name.Local, m.describe()),
+		}
+	}
+	return &Error{
+		Line:    parent.line,
+		Message: fmt.Sprintf("missing %s in %q", m.describe(), parent.name.Local),
+	}
+}
+
+func occurs(p *node) (int, int) {
+	minOccurs, maxOccurs := 1, 1
+	if value, ok := p.attr("minOccurs"); ok {
+		minOccurs, _ = strconv.Atoi(value)
+	}
+	if value, ok := p.attr("maxOccurs"); ok {
+		if value == "unbounded" {
+			maxOccurs = -1
+		} else {
+			maxOccurs, _ = strconv.Atoi(value)
+		}
+	}
+	return minOccurs, maxOccurs
+}
+
+// match matches the particle p as often as it occurs against the children
+// of parent from index i. It returns the index after the matched children,
+// also when it fails.
+func (v *validator) match(p *node, parent *node, i int) (int, error) {
+	minOccurs, maxOccurs := occurs(p)
+	for count := 0; maxOccurs < 0 || count < maxOccurs; count++ {
+		j, err := v.matchOnce(p, parent, i)
+		var m *missing
+		if errors.As(err, &m) && j == i {
+			if count >= minOccurs {
+				return i, nil
+			}
+			return i, err
+		}
+		if err != nil {
+			return j, err
+		}
+		if j == i {
+			// A particle that matches nothing satisfies any number of
+			// occurrences.
+			return i, nil
+		}
+		i = j
+	}
+	return i, nil
+}
+
+func (v *validator) matchOnce(p *node, parent *node, i int) (int, error) {
+	children := parent.children
+
+	switch p.name.Local {
+	case "element":
+		decl := p
+		if ref, ok := p.attr("ref"); ok {
+			decl = v.schema.elements[p.qname(ref).Local]
+		}
+		name, _ := decl.attr("name")
+		if i < len(children) && children[i].name.Local == name {
+			return i + 1, v.element(children[i], decl)
+		}
+		return i, &missing{expected: []string{strconv.Quote(name)}}
+
+	case "any":
+		if i < len(children) {
+			return i + 1, nil
+		}
+		return i, &missing{expected: []string{"of any name"}}
+
+	case "group":
+		ref, _ := p.attr("ref")
+		group := v.schema.groups[p.qname(ref).Local]
+		if model := group.child("sequence", "choice", "all"); model != nil {
+			return v.match(model, parent, i)
+		}
+		return i, nil
+
+	case "sequence":
+		k := i
+		for _, c := range p.schemaChildren() {
+			j, err := v.match(c, parent, k)
+			var m *missing
+			if errors.As(err, &m) && j == i {
+				return i, err
+			}
+			if err != nil {
+				return j, v.located(err, parent, j)
+			}
+			k = j
+		}
+		return k, nil
+
+	case "choice":
+		var expected []string
+		empty := false
+		for _, c := range p.schemaChildren() {
+			j, err := v.match(c, parent, i)
+			var m *missing
+			switch {
+			case err == nil && j > i:
+				return j, nil
+			case err == nil:
+				empty = true
+			case errors.As(err, &m) && j == i:
+				expected = append(expected, m.expected...)
+			default:
+				return j, v.located(err, parent, j)
+			}
+		}
+		if empty {
+			return i, nil
+		}
+		return i, &missing{expected: expected}
+
+	case "all":
+		seen := make(map[*node]bool)
+		k := i
+	next:
+		for k < len(children) {
+			for _, c := range p.schemaChildren() {
+				decl := c
+				if ref, ok := c.attr("ref"); ok {
+					decl = v.schema.elements[c.qname(ref).Local]
+				}
+				if name, _ := decl.attr("name"); name == children[k].name.Local && !seen[c] {
+					if err := v.element(children[k], decl); err != nil {
+						return k, err
+					}
+					seen[c] = true
+					k++
+					continue next
+				}
+			}
+			break
+		}
+
+		var expected []string
+		for _, c := range p.schemaChildren() {
+			if minOccurs, _ := occurs(c); minOccurs > 0 && !seen[c] {
+				decl := c
+				if ref, ok := c.attr("ref"); ok {
+					decl = v.schema.elements[c.qname(ref).Local]
+				}
+				name, _ := decl.attr("name")
+				expected = append(expected, strconv.Quote(name))
+			}
+		}
+		if len(expected) > 0 {
+			if k == i {
+				return i, &missing{expected: expected}
+			}
+			return k, v.located(&missing{expected: expected}, parent, k)
+		}
+		return k, nil
+	}
+	return i, nil
+}
+
+// attributes validates the attributes of n against the declared ones.
+// Namespace declarations and xsi attributes are always allowed.
+func (v *validator) attributes(n *node, declared map[string]attributeDecl, anyAttribute bool) error {
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Space == "" && a.Name.Local == "xmlns" ||
+			a.Name.Space == xsiNamespace || a.Name.Space == xmlNamespace {
+			continue
+		}
+		decl, ok := declared[a.Name.Local]
+		if !ok || decl.prohibited {
+			if anyAttribute {
+				continue
+			}
+			return &Error{
+				Line:    n.line,
+				Message: fmt.Sprintf("attribute %q is not allowed in %q", a.Name.Local, n.name.Local),
+			}
+		}
+		if err := v.schema.value(a.Value, decl.typ); err != nil {
+			return &Error{
+				Line:    n.line,
+				Message: fmt.Sprintf("attribute %q of %q: %v", a.Name.Local, n.name.Local, err),
+			}
+		}
+	}
+
+	for name, decl := range declared {
+		if _, ok := n.attr(name); decl.required && !ok {
+			return &Error{
+				Line:    n.line,
+				Message: fmt.Sprintf("missing attribute %q in %q", name, n.name.Local),
+			}
+		}
+	}
+	return nil
+}
+
+func attrValue(n *node, space, local string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,320 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package xsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"
+           xmlns="urn:example:settings"
+           targetNamespace="urn:example:settings"
+           elementFormDefault="qualified">
+  <xs:element name="settings" type="Settings"/>
+
+  <xs:complexType name="Settings">
+    <xs:sequence>
+      <xs:element name="localRepository" type="xs:string" minOccurs="0"/>
+      <xs:element name="offline" type="xs:boolean" minOccurs="0"/>
+      <xs:element name="servers" minOccurs="0">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="server" type="Server" maxOccurs="unbounded"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:choice minOccurs="0">
+        <xs:element name="mirror" type="xs:anyURI"/>
+        <xs:element name="proxy" type="Proxy"/>
+      </xs:choice>
+      <xs:element name="logging" type="Logging" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="version" type="Version" use="required"/>
+  </xs:complexType>
+
+  <xs:complexType name="Identified">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+
+  <xs:complexType name="Server">
+    <xs:complexContent>
+      <xs:extension base="Identified">
+        <xs:all>
+          <xs:element name="username" type="xs:string"/>
+          <xs:element name="password" type="xs:string" minOccurs="0"/>
+        </xs:all>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+
+  <xs:complexType name="Proxy">
+    <xs:sequence>
+      <xs:element name="host" type="xs:string"/>
+      <xs:element name="port" type="Port"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="Toggle"/>
+  </xs:complexType>
+
+  <xs:attributeGroup name="Toggle">
+    <xs:attribute name="active" type="xs:boolean"/>
+  </xs:attributeGroup>
+
+  <xs:complexType name="Logging">
+    <xs:simpleContent>
+      <xs:extension base="Level">
+        <xs:attribute name="appender" type="xs:NCName"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+
+  <xs:simpleType name="Level">
+    <xs:restriction base="xs:token">
+      <xs:enumeration value="debug"/>
+      <xs:enumeration value="info"/>
+      <xs:enumeration value="error"/>
+    </xs:restriction>
+  </xs:simpleType>
+
+  <xs:simpleType name="Port">
+    <xs:restriction base="xs:int">
+      <xs:minInclusive value="1"/>
+      <xs:maxInclusive value="65535"/>
+    </xs:restriction>
+  </xs:simpleType>
+
+  <xs:simpleType name="Version">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="\d+\.\d+(\.\d+)?"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		line     int
+		message  string
+	}{
+		{
+			name: "valid document",
+			document: `<?xml version="1.0"?>
+<settings xmlns="urn:example:settings" version="1.2">
+  <!-- local settings -->
+  <localRepository>/tmp/repo</localRepository>
+  <offline>true</offline>
+  <servers>
+    <server>
+      <id>central</id>
+      <password>secret</password>
+      <username>ci</username>
+    </server>
+    <server><id>internal</id><username>ci</username></server>
+  </servers>
+  <proxy active="false">
+    <host>proxy.local</host>
+    <port>3128</port>
+  </proxy>
+  <logging appender="console"> info </logging>
+</settings>
+`,
+		},
+		{
+			name:     "wrong namespace",
+			document: `<settings version="1.0"/>`,
+			line:     1,
+			message:  `element "settings" must be in namespace "urn:example:settings", not ""`,
+		},
+		{
+			name:     "undeclared root element",
+			document: `<profiles xmlns="urn:example:settings"/>`,
+			line:     1,
+			message:  `element "profiles" is not declared in the schema`,
+		},
+		{
+			name:     "missing required attribute",
+			document: "<settings xmlns=\"urn:example:settings\">\n</settings>",
+			line:     1,
+			message:  `missing attribute "version" in "settings"`,
+		},
+		{
+			name:     "attribute pattern",
+			document: `<settings xmlns="urn:example:settings" version="latest"/>`,
+			line:     1,
+			message:  `attribute "version" of "settings": value "latest" does not match the pattern "\\d+\\.\\d+(\\.\\d+)?"`,
+		},
+		{
+			name:     "undeclared attribute",
+			document: `<settings xmlns="urn:example:settings" version="1.0" mode="fast"/>`,
+			line:     1,
+			message:  `attribute "mode" is not allowed in "settings"`,
+		},
+		{
+			name:     "invalid boolean",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <offline>yes</offline>\n</settings>",
+			line:     2,
+			message:  `element "offline": value "yes" is not a valid boolean`,
+		},
+		{
+			name:     "elements out of order",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <offline>true</offline>\n  <localRepository>/tmp</localRepository>\n</settings>",
+			line:     3,
+			message:  `unexpected element "localRepository" in "settings"`,
+		},
+		{
+			name:     "missing element of extension",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <servers>\n    <server>\n      <id>a</id>\n    </server>\n  </servers>\n</settings>",
+			line:     3,
+			message:  `missing element "username" in "server"`,
+		},
+		{
+			name:     "missing element of base type",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <servers>\n    <server>\n      <username>a</username>\n    </server>\n  </servers>\n</settings>",
+			line:     4,
+			message:  `unexpected element "username" in "server", expected element "id"`,
+		},
+		{
+			name:     "empty required sequence",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <servers/>\n</settings>",
+			line:     2,
+			message:  `missing element "server" in "servers"`,
+		},
+		{
+			name:     "both alternatives of a choice",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <mirror>https://repo.local</mirror>\n  <proxy><host>p</host><port>1</port></proxy>\n</settings>",
+			line:     3,
+			message:  `unexpected element "proxy" in "settings"`,
+		},
+		{
+			name:     "out of range",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <proxy>\n    <host>p</host>\n    <port>70000</port>\n  </proxy>\n</settings>",
+			line:     4,
+			message:  `element "port": value 70000 does not satisfy maxInclusive 65535`,
+		},
+		{
+			name:     "enumeration of simple content",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <logging>trace</logging>\n</settings>",
+			line:     2,
+			message:  `element "logging": value "trace" is not one of ["debug" "info" "error"]`,
+		},
+		{
+			name:     "text in element only content",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  loose text\n</settings>",
+			line:     1,
+			message:  `element "settings" cannot contain text`,
+		},
+		{
+			name:     "elements in simple content",
+			document: "<settings xmlns=\"urn:example:settings\" version=\"1.0\">\n  <offline>\n    <value>true</value>\n  </offline>\n</settings>",
+			line:     3,
+			message:  `element "offline" cannot contain elements`,
+		},
+	}
+
+	schema, err := Parse(testSchema)
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.document)
+			if tt.message == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var xsdErr *Error
+			require.ErrorAs(t, err, &xsdErr)
+			assert.Equal(t, tt.line, xsdErr.Line)
+			assert.Equal(t, tt.message, xsdErr.Message)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		message string
+	}{
+		{
+			name:    "not a schema",
+			schema:  `<settings/>`,
+			message: "the root element must be an xs:schema element",
+		},
+		{
+			name: "unknown type",
+			schema: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="a" type="Missing"/>
+</xs:schema>`,
+			message: `unknown type "Missing"`,
+		},
+		{
+			name: "unknown built-in type",
+			schema: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="a" type="xs:text"/>
+</xs:schema>`,
+			message: `unknown type "xs:text"`,
+		},
+		{
+			name: "include",
+			schema: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="common.xsd"/>
+</xs:schema>`,
+			message: "xs:include is not supported, the schema must be self-contained",
+		},
+		{
+			name: "recursive group",
+			schema: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:group name="g">
+    <xs:sequence>
+      <xs:group ref="g"/>
+    </xs:sequence>
+  </xs:group>
+</xs:schema>`,
+			message: `group "g" refers to itself`,
+		},
+		{
+			name: "unsupported pattern",
+			schema: `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="s">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[a-z-[aeiou]]+"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+			message: `unsupported pattern`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.schema)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestRecursiveElements(t *testing.T) {
+	schema, err := Parse(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="node" type="Node"/>
+  <xs:complexType name="Node">
+    <xs:sequence>
+      <xs:element ref="node" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:any minOccurs="0" processContents="lax"/>
+    </xs:sequence>
+    <xs:anyAttribute/>
+  </xs:complexType>
+</xs:schema>`)
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(`<node a="1"><node><node/></node><extra><x/></extra></node>`))
+}
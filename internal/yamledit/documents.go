@@ -0,0 +1,80 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package yamledit
+
+import "strings"
+
+// SplitDocuments splits a YAML stream into the text of its documents. Every
+// part keeps its own markers, comments and blank lines, so joining the parts
+// gives back content. Comments in front of the first marker belong to the
+// first document and comments after the last document to the last one.
+func SplitDocuments(content string) []string {
+	var parts []string
+	var current strings.Builder
+	hasContent := false
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		if isDocumentStart(trimmed) && hasContent {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+
+		switch {
+		case trimmed == "...":
+			parts = append(parts, current.String())
+			current.Reset()
+			hasContent = false
+		case isDocumentStart(trimmed):
+			hasContent = true
+		case !isTrivia(trimmed):
+			hasContent = true
+		}
+	}
+
+	if current.Len() > 0 {
+		if hasContent || len(parts) == 0 {
+			parts = append(parts, current.String())
+		} else {
+			parts[len(parts)-1] += current.String()
+		}
+	}
+	return parts
+}
+
+// DocumentBody returns the content of a document without its markers and
+// directives.
+func DocumentBody(document string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(document, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case trimmed == "...", strings.HasPrefix(trimmed, "%"):
+			continue
+		case trimmed == "---":
+			continue
+		case isDocumentStart(trimmed):
+			// Content can follow the marker on the same line.
+			b.WriteString(strings.TrimLeft(line[3:], " \t"))
+			continue
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func isDocumentStart(line string) bool {
+	return line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t")
+}
+
+// isTrivia reports whether a line holds no document content.
+func isTrivia(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(line, "%")
+}
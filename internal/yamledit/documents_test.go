@@ -0,0 +1,63 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package yamledit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "single document",
+			content:  "a: 1\nb: 2\n",
+			expected: []string{"a: 1\nb: 2\n"},
+		},
+		{
+			name:     "leading comment belongs to the first document",
+			content:  "# manifests\n---\nkind: Service\n---\nkind: Deployment\n",
+			expected: []string{"# manifests\n---\nkind: Service\n", "---\nkind: Deployment\n"},
+		},
+		{
+			name:     "empty documents",
+			content:  "---\n---\n",
+			expected: []string{"---\n", "---\n"},
+		},
+		{
+			name:     "end marker and directives",
+			content:  "a: 1\n...\n%YAML 1.2\n---\nb: 2\n# end\n",
+			expected: []string{"a: 1\n...\n", "%YAML 1.2\n---\nb: 2\n# end\n"},
+		},
+		{
+			name:     "marker inside a block scalar",
+			content:  "script: |\n  ---\n  echo\n---\nb: 2\n",
+			expected: []string{"script: |\n  ---\n  echo\n", "---\nb: 2\n"},
+		},
+		{
+			name:     "only comments",
+			content:  "# nothing\n",
+			expected: []string{"# nothing\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := SplitDocuments(tt.content)
+			assert.Equal(t, tt.expected, parts)
+			assert.Equal(t, tt.content, strings.Join(parts, ""))
+		})
+	}
+}
+
+func TestDocumentBody(t *testing.T) {
+	assert.Equal(t, "# manifests\nkind: Service\n", DocumentBody("# manifests\n---\nkind: Service\n...\n"))
+	assert.Equal(t, "!!map {}\n", DocumentBody("%YAML 1.2\n--- !!map {}\n"))
+}
@@ -0,0 +1,538 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package yamledit edits single keys of a YAML document in place. Only the
+// lines of the edited keys are rewritten, so comments, blank lines and the
+// layout of all other keys are kept as they are. Replaced values keep the
+// quoting style, anchor and line comment of the value they replace.
+package yamledit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultIndent = 2
+
+type Document struct {
+	content string
+}
+
+// Parse parses content into an editable document. Content without any
+// document is treated as an empty mapping.
+func Parse(content string) (*Document, error) {
+	d := &Document{content: content}
+	if _, _, err := d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SplitPath splits a dot separated key path like image.tag. Dots that are
+// part of a key are escaped with a backslash, like
+// metadata.annotations.app\.kubernetes\.io/name, and a backslash as \\.
+func SplitPath(path string) []string {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path) && (path[i+1] == '.' || path[i+1] == '\\'):
+			key.WriteByte(path[i+1])
+			i++
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+	return append(keys, key.String())
+}
+
+// String returns the current content of the document.
+func (d *Document) String() string {
+	return d.content
+}
+
+// Get returns the value node at path.
+func (d *Document) Get(path []string) (*yaml.Node, bool) {
+	_, root, err := d.parse()
+	if err != nil || root == nil {
+		return nil, false
+	}
+
+	node := root
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		_, value := lookup(node, key)
+		if value == nil {
+			return nil, false
+		}
+		node = value
+		if node.Kind == yaml.AliasNode {
+			// Aliases resolve to the value of their anchor.
+			resolved := *node.Alias
+			resolved.Anchor = ""
+			node = &resolved
+		}
+	}
+	return node, true
+}
+
+// Set replaces the value at path, creating missing parent mappings.
+func (d *Document) Set(path []string, value *yaml.Node) error {
+	doc, root, err := d.parse()
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		d.append(renderEntry(path[0], nest(path[1:], value), defaultIndent), "")
+		return nil
+	}
+
+	var parentKey *yaml.Node
+	node := root
+	for i, key := range path {
+		if node.Style&yaml.FlowStyle != 0 {
+			if err := setNode(node, path[i:], value); err != nil {
+				return err
+			}
+			return d.rewrite(doc, parentKey, node)
+		}
+
+		keyNode, child := lookup(node, key)
+		switch {
+		case child == nil:
+			d.insert(node, path[i:], value, indentUnit(root))
+			return nil
+		case i == len(path)-1:
+			d.replace(keyNode, child, value, indentUnit(root))
+			return nil
+		case child.Kind == yaml.MappingNode:
+			parentKey, node = keyNode, child
+		case child.Tag == "!!null":
+			d.replace(keyNode, child, nest(path[i+1:], value), indentUnit(root))
+			return nil
+		default:
+			return fmt.Errorf("%s is not a mapping", strings.Join(path[:i+1], "."))
+		}
+	}
+	return nil
+}
+
+// Delete removes the key at path and reports whether it existed. Parent
+// mappings left empty by the removal are removed as well.
+func (d *Document) Delete(path []string) (bool, error) {
+	doc, root, err := d.parse()
+	if err != nil || root == nil {
+		return false, err
+	}
+
+	// entries holds the key and mapping of every level of path.
+	type entry struct{ key, mapping *yaml.Node }
+	var entries []entry
+
+	node := root
+	for i, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return false, nil
+		}
+		keyNode, child := lookup(node, key)
+		if child == nil {
+			return false, nil
+		}
+		entries = append(entries, entry{keyNode, node})
+		if i < len(path)-1 {
+			node = child
+		}
+	}
+
+	target := len(entries) - 1
+	for target > 0 && len(entries[target].mapping.Content) == 2 {
+		target--
+	}
+
+	for i := 0; i <= target; i++ {
+		if entries[i].mapping.Style&yaml.FlowStyle == 0 {
+			continue
+		}
+		// Flow mappings are rewritten as a whole from the edited node.
+		removeNode(entries[target].mapping, entries[target].key)
+		if i == 0 {
+			return true, d.rewrite(doc, nil, root)
+		}
+		return true, d.rewrite(doc, entries[i-1].key, entries[i].mapping)
+	}
+
+	key := entries[target].key
+	lines := strings.Split(d.content, "\n")
+	start := key.Line - 1
+	indent := key.Column - 1
+	if strings.TrimSpace(lines[start][:indent]) != "" {
+		// The key shares its line with a sequence entry marker.
+		removeNode(entries[target].mapping, key)
+		return true, d.rewrite(doc, nil, root)
+	}
+
+	end := entryEnd(lines, start, indent)
+	// Drop the blank line separating the entry when nothing follows it.
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" &&
+		(end+1 == len(lines) || strings.TrimSpace(lines[end+1]) == "") {
+		start--
+	}
+	d.content = strings.Join(append(lines[:start], lines[end+1:]...), "\n")
+	return true, nil
+}
+
+func (d *Document) parse() (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(d.content), &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML content: %w", err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return &doc, nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("the document is not a mapping")
+	}
+	return &doc, root, nil
+}
+
+// replace rewrites the entry of keyNode with value. Single line scalars are
+// replaced within the line, so trailing comments stay in place.
+func (d *Document) replace(keyNode, current, value *yaml.Node, unit int) {
+	lines := strings.Split(d.content, "\n")
+	start := keyNode.Line - 1
+	indent := keyNode.Column - 1
+	end := entryEnd(lines, start, indent)
+
+	value = keepStyle(current, value)
+	if end == start && current.Line == keyNode.Line {
+		line := lines[start]
+		props, from := properties(line, current.Column-1)
+		inline := *value
+		inline.Anchor, inline.LineComment = "", ""
+		if text, ok := inlineScalar(&inline); ok {
+			if to, ok := scalarEnd(line, from); ok {
+				lines[start] = line[:current.Column-1] + keptProperties(props, current, value) + text + line[to:]
+				d.content = strings.Join(lines, "\n")
+				return
+			}
+		}
+	}
+
+	rendered := renderEntry(keyNode.Value, value, unit)
+	for i := range rendered {
+		if i == 0 {
+			rendered[i] = lines[start][:indent] + rendered[i]
+		} else if rendered[i] != "" {
+			rendered[i] = strings.Repeat(" ", indent) + rendered[i]
+		}
+	}
+
+	out := append([]string{}, lines[:start]...)
+	out = append(out, rendered...)
+	d.content = strings.Join(append(out, lines[end+1:]...), "\n")
+}
+
+// insert adds the first key of path after the last entry of mapping.
+func (d *Document) insert(mapping *yaml.Node, path []string, value *yaml.Node, unit int) {
+	lines := strings.Split(d.content, "\n")
+	last := mapping.Content[len(mapping.Content)-2]
+	indent := mapping.Content[0].Column - 1
+	end := entryEnd(lines, last.Line-1, indent)
+
+	rendered := renderEntry(path[0], nest(path[1:], value), unit)
+	for i := range rendered {
+		if rendered[i] != "" {
+			rendered[i] = strings.Repeat(" ", indent) + rendered[i]
+		}
+	}
+
+	out := append([]string{}, lines[:end+1]...)
+	out = append(out, rendered...)
+	d.content = strings.Join(append(out, lines[end+1:]...), "\n")
+}
+
+func (d *Document) append(rendered []string, prefix string) {
+	content := d.content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	d.content = content + prefix + strings.Join(rendered, "\n") + "\n"
+}
+
+// rewrite renders the entry of keyNode again from value, or the whole
+// document when keyNode is nil.
+func (d *Document) rewrite(doc, keyNode, value *yaml.Node) error {
+	if keyNode == nil {
+		var buf bytes.Buffer
+		// The encoder drops the document markers of the original content.
+		if hasDocumentStart(d.content) {
+			buf.WriteString("---\n")
+		}
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(defaultIndent)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		if hasDocumentEnd(d.content) {
+			buf.WriteString("...\n")
+		}
+		d.content = buf.String()
+		return nil
+	}
+
+	d.replace(keyNode, value, value, indentUnit(doc.Content[0]))
+	return nil
+}
+
+// keepStyle returns a copy of value that takes over the line comment and
+// anchor of current, and its quoting style when both are strings.
+func keepStyle(current, value *yaml.Node) *yaml.Node {
+	out := *value
+	out.LineComment = current.LineComment
+	if out.Anchor == "" {
+		out.Anchor = current.Anchor
+	}
+
+	if current.Kind != yaml.ScalarNode || out.Kind != yaml.ScalarNode || out.Style != 0 ||
+		current.ShortTag() != "!!str" || out.ShortTag() != "!!str" {
+		return &out
+	}
+	switch {
+	case current.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		out.Style = current.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+	case current.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && strings.Contains(out.Value, "\n"):
+		out.Style = current.Style & (yaml.LiteralStyle | yaml.FoldedStyle)
+	}
+	return &out
+}
+
+// properties returns the anchor and tag tokens in front of the value starting
+// at from, and the position of the value itself.
+func properties(line string, from int) ([]string, int) {
+	var props []string
+	for from < len(line) && (line[from] == '&' || line[from] == '!') {
+		end := strings.IndexByte(line[from:], ' ')
+		if end < 0 {
+			return props, len(line)
+		}
+		props = append(props, line[from:from+end])
+		from += end
+		for from < len(line) && line[from] == ' ' {
+			from++
+		}
+	}
+	return props, from
+}
+
+// keptProperties renders the properties that still apply to value. Anchors
+// are kept, explicit tags only while the type of the value stays the same.
+func keptProperties(props []string, current, value *yaml.Node) string {
+	var b strings.Builder
+	for _, p := range props {
+		if p[0] == '!' && current.ShortTag() != value.ShortTag() {
+			continue
+		}
+		b.WriteString(p + " ")
+	}
+	return b.String()
+}
+
+// entryEnd returns the last line of the entry whose key starts on line start
+// at the given indentation.
+func entryEnd(lines []string, start, indent int) int {
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			continue
+		}
+		if len(lines[i])-len(strings.TrimLeft(lines[i], " ")) <= indent {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// inlineScalar renders value when it fits on a single line.
+func inlineScalar(value *yaml.Node) (string, bool) {
+	if value.Kind != yaml.ScalarNode || strings.Contains(value.Value, "\n") {
+		return "", false
+	}
+
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	text := strings.TrimSuffix(string(out), "\n")
+	if strings.Contains(text, "\n") {
+		return "", false
+	}
+	return text, true
+}
+
+// scalarEnd returns the end of the single line scalar starting at from.
+func scalarEnd(line string, from int) (int, bool) {
+	if from >= len(line) {
+		return 0, false
+	}
+
+	switch line[from] {
+	case '"':
+		for i := from + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, true
+			}
+		}
+		return 0, false
+	case '\'':
+		for i := from + 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+		return 0, false
+	case '&', '*', '!', '|', '>', '[', '{':
+		return 0, false
+	}
+
+	end := len(line)
+	if i := strings.Index(line[from:], " #"); i >= 0 {
+		end = from + i
+	}
+	return from + len(strings.TrimRight(line[from:end], " ")), true
+}
+
+// hasDocumentStart reports whether the document begins with an explicit
+// "---" marker, possibly after comments and directives.
+func hasDocumentStart(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "%"):
+			continue
+		case trimmed == "---", strings.HasPrefix(trimmed, "--- "):
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+// hasDocumentEnd reports whether the document ends with a "..." marker.
+func hasDocumentEnd(content string) bool {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]) == "..."
+}
+
+func renderEntry(key string, value *yaml.Node, unit int) []string {
+	mapping := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Tag:  "!!map",
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			value,
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(unit)
+	_ = enc.Encode(mapping)
+	_ = enc.Close()
+
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// nest wraps value into mappings for the keys of path.
+func nest(path []string, value *yaml.Node) *yaml.Node {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = &yaml.Node{
+			Kind: yaml.MappingNode,
+			Tag:  "!!map",
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[i]},
+				value,
+			},
+		}
+	}
+	return value
+}
+
+// indentUnit returns the indentation of the first nested block collection.
+func indentUnit(root *yaml.Node) int {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, child := root.Content[i], root.Content[i+1]
+		if child.Kind != yaml.MappingNode && child.Kind != yaml.SequenceNode {
+			continue
+		}
+		if child.Style&yaml.FlowStyle != 0 || child.Line == key.Line {
+			continue
+		}
+		if unit := child.Column - key.Column; unit > 0 {
+			return unit
+		}
+	}
+	return defaultIndent
+}
+
+func lookup(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// setNode sets the value at path within the node tree of mapping.
+func setNode(mapping *yaml.Node, path []string, value *yaml.Node) error {
+	node := mapping
+	for i, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", key)
+		}
+
+		_, child := lookup(node, key)
+		if child == nil {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, nest(path[i+1:], value))
+			return nil
+		}
+		if i == len(path)-1 {
+			*child = *value
+			return nil
+		}
+		node = child
+	}
+	return nil
+}
+
+func removeNode(mapping, key *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i] == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package yamledit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const values = `# Umbrella chart values
+replicaCount: 1 # scaled by HPA
+
+image:
+  # pinned by the release pipeline
+  repository: ghcr.io/example/app
+  tag: v1.0.0
+
+ingress:
+  enabled: false
+`
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		value    *yaml.Node
+		expected string
+	}{
+		{
+			name:  "existing key keeps comments",
+			path:  "replicaCount",
+			value: scalar("!!int", "3"),
+			expected: `# Umbrella chart values
+replicaCount: 3 # scaled by HPA
+
+image:
+  # pinned by the release pipeline
+  repository: ghcr.io/example/app
+  tag: v1.0.0
+
+ingress:
+  enabled: false
+`,
+		},
+		{
+			name:  "nested key",
+			path:  "image.tag",
+			value: scalar("!!str", "v1.1.0"),
+			expected: `# Umbrella chart values
+replicaCount: 1 # scaled by HPA
+
+image:
+  # pinned by the release pipeline
+  repository: ghcr.io/example/app
+  tag: v1.1.0
+
+ingress:
+  enabled: false
+`,
+		},
+		{
+			name:  "missing parents are created",
+			path:  "resources.limits.cpu",
+			value: scalar("!!str", "500m"),
+			expected: `# Umbrella chart values
+replicaCount: 1 # scaled by HPA
+
+image:
+  # pinned by the release pipeline
+  repository: ghcr.io/example/app
+  tag: v1.0.0
+
+ingress:
+  enabled: false
+resources:
+  limits:
+    cpu: 500m
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(values)
+			require.NoError(t, err)
+			require.NoError(t, doc.Set(SplitPath(tt.path), tt.value))
+
+			assert.Equal(t, tt.expected, doc.String())
+
+			node, ok := doc.Get(SplitPath(tt.path))
+			require.True(t, ok)
+			assert.Equal(t, tt.value.Value, node.Value)
+		})
+	}
+}
+
+func TestSetThroughScalar(t *testing.T) {
+	doc, err := Parse(values)
+	require.NoError(t, err)
+
+	err = doc.Set(SplitPath("replicaCount.min"), scalar("!!int", "1"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "replicaCount is not a mapping")
+}
+
+func TestDelete(t *testing.T) {
+	doc, err := Parse(values)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		path    string
+		deleted bool
+	}{
+		{"ingress.enabled", true},
+		{"ingress.enabled", false},
+		{"image.digest", false},
+	} {
+		deleted, err := doc.Delete(SplitPath(tt.path))
+		require.NoError(t, err)
+		assert.Equal(t, tt.deleted, deleted, tt.path)
+	}
+
+	assert.Equal(t, `# Umbrella chart values
+replicaCount: 1 # scaled by HPA
+
+image:
+  # pinned by the release pipeline
+  repository: ghcr.io/example/app
+  tag: v1.0.0
+`, doc.String())
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		edit     func(*Document) error
+		expected string
+	}{
+		{
+			name:    "quoted scalar keeps its quotes",
+			content: "---\nname: \"app\" # the name\nother: 'x'\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"name"}, scalar("!!str", "web"))
+			},
+			expected: "---\nname: \"web\" # the name\nother: 'x'\n",
+		},
+		{
+			name:    "block value is replaced",
+			content: "hosts:\n    - a\n    - b\nport: 80\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"hosts"}, &yaml.Node{
+					Kind:    yaml.SequenceNode,
+					Tag:     "!!seq",
+					Content: []*yaml.Node{scalar("!!str", "c")},
+				})
+			},
+			expected: "hosts:\n    - c\nport: 80\n",
+		},
+		{
+			name:    "indentation of the document is used",
+			content: "image:\n    tag: v1\n",
+			edit: func(d *Document) error {
+				return d.Set(SplitPath("resources.limits.cpu"), scalar("!!str", "1"))
+			},
+			expected: "image:\n    tag: v1\nresources:\n    limits:\n        cpu: \"1\"\n",
+		},
+		{
+			name:    "null parent is replaced",
+			content: "image:\nport: 80\n",
+			edit: func(d *Document) error {
+				return d.Set(SplitPath("image.tag"), scalar("!!str", "v1"))
+			},
+			expected: "image:\n  tag: v1\nport: 80\n",
+		},
+		{
+			name:    "flow mapping is rewritten",
+			content: "# settings\nimage: {tag: v1, pullPolicy: Always}\nport: 80\n",
+			edit: func(d *Document) error {
+				return d.Set(SplitPath("image.tag"), scalar("!!str", "v2"))
+			},
+			expected: "# settings\nimage: {tag: v2, pullPolicy: Always}\nport: 80\n",
+		},
+		{
+			name:    "single quotes are kept",
+			content: "name: 'app'\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"name"}, scalar("!!str", "it's"))
+			},
+			expected: "name: 'it''s'\n",
+		},
+		{
+			name:    "quotes are dropped for other types",
+			content: "port: \"80\"\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"port"}, scalar("!!int", "8080"))
+			},
+			expected: "port: 8080\n",
+		},
+		{
+			name:    "anchor is kept",
+			content: "base: &tag v1 # shared\nimage:\n  tag: *tag\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"base"}, scalar("!!str", "v2"))
+			},
+			expected: "base: &tag v2 # shared\nimage:\n  tag: *tag\n",
+		},
+		{
+			name:    "anchor of a block value is kept",
+			content: "defaults: &defaults\n  cpu: 1\nport: 80\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"defaults"}, &yaml.Node{
+					Kind:    yaml.MappingNode,
+					Tag:     "!!map",
+					Content: []*yaml.Node{scalar("!!str", "cpu"), scalar("!!int", "2")},
+				})
+			},
+			expected: "defaults: &defaults\n  cpu: 2\nport: 80\n",
+		},
+		{
+			name:    "explicit tag is kept for the same type",
+			content: "version: !!str 1.10\nport: !!str 80\n",
+			edit: func(d *Document) error {
+				if err := d.Set([]string{"version"}, scalar("!!str", "1.11")); err != nil {
+					return err
+				}
+				return d.Set([]string{"port"}, scalar("!!int", "8080"))
+			},
+			expected: "version: !!str \"1.11\"\nport: 8080\n",
+		},
+		{
+			name:    "literal block stays literal",
+			content: "script: |\n  echo a\n  echo b\nport: 80\n",
+			edit: func(d *Document) error {
+				return d.Set([]string{"script"}, scalar("!!str", "echo c\necho d\n"))
+			},
+			expected: "script: |\n  echo c\n  echo d\nport: 80\n",
+		},
+		{
+			name:    "document markers are kept on rewrite",
+			content: "---\n{image: v1, port: 80}\n...\n",
+			edit: func(d *Document) error {
+				_, err := d.Delete(SplitPath("port"))
+				return err
+			},
+			expected: "---\n{image: v1}\n...\n",
+		},
+		{
+			name:    "emptied flow mapping is removed",
+			content: "image: {tag: v1}\nport: 80\n",
+			edit: func(d *Document) error {
+				_, err := d.Delete(SplitPath("image.tag"))
+				return err
+			},
+			expected: "port: 80\n",
+		},
+		{
+			name:    "empty parents are removed",
+			content: "a: 1\nresources:\n  limits:\n    cpu: 1\nb: 2\n",
+			edit: func(d *Document) error {
+				_, err := d.Delete(SplitPath("resources.limits.cpu"))
+				return err
+			},
+			expected: "a: 1\nb: 2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.content)
+			require.NoError(t, err)
+			require.NoError(t, tt.edit(doc))
+			assert.Equal(t, tt.expected, doc.String())
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	doc, err := Parse("base: &base\n  tag: v1\nimage: *base\nname: &name app\n")
+	require.NoError(t, err)
+
+	node, ok := doc.Get(SplitPath("image.tag"))
+	require.True(t, ok)
+	assert.Equal(t, "v1", node.Value)
+
+	node, ok = doc.Get(SplitPath("image"))
+	require.True(t, ok)
+	out, err := yaml.Marshal(node)
+	require.NoError(t, err)
+	assert.Equal(t, "tag: v1\n", string(out))
+
+	_, ok = doc.Get(SplitPath("image.missing"))
+	assert.False(t, ok)
+}
+
+func TestSplitPath(t *testing.T) {
+	for path, want := range map[string][]string{
+		"image.tag": {"image", "tag"},
+		`metadata.labels.app\.kubernetes\.io/name`: {"metadata", "labels", "app.kubernetes.io/name"},
+		`a\\.b`: {`a\`, "b"},
+		`a\b`:   {`a\b`},
+		"a..b":  {"a", "", "b"},
+	} {
+		assert.Equal(t, want, SplitPath(path), path)
+	}
+
+	doc, err := Parse("labels:\n  app.kubernetes.io/name: app\n")
+	require.NoError(t, err)
+	node, ok := doc.Get(SplitPath(`labels.app\.kubernetes\.io/name`))
+	require.True(t, ok)
+	assert.Equal(t, "app", node.Value)
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse("")
+	require.NoError(t, err)
+	require.NoError(t, doc.Set([]string{"a"}, scalar("!!str", "b")))
+	assert.Equal(t, "a: b\n", doc.String())
+
+	_, err = Parse("- a\n- b\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a mapping")
+
+	_, err = Parse("a: [\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse YAML")
+}